@@ -0,0 +1,285 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+
+	"bhojanalya/internal/auth"
+	"bhojanalya/internal/db"
+
+	"github.com/joho/godotenv"
+)
+
+const usage = `usage: admin <command> [flags]
+
+commands:
+  create-admin    -email E -name N [-password P]   create a new ADMIN user
+  promote         -email E                         give an existing user the ADMIN role
+  demote          -email E                         move an ADMIN back to RESTAURANT
+  reset-password  -email E [-password P]           set a new password
+  reset-mfa       -email E                         remove two-factor auth (lost device)
+  seal-mfa-secrets                                 encrypt two-factor secrets stored before encryption
+  set-role        -email E -role R                 assign any existing role (e.g. ANALYST)
+  list-users                                       print all users and their roles
+
+  list-roles                                       print roles and their permissions
+  grant           -role R -permission P            grant a permission (creates the role)
+  revoke          -role R -permission P            revoke a permission
+
+When -password is omitted it is read from the first line of stdin.
+`
+
+func main() {
+	if os.Getenv("APP_ENV") != "production" {
+		_ = godotenv.Load()
+	}
+
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	pgDB := db.OpenPostgres()
+	defer pgDB.Close()
+
+	users := auth.NewPostgresUserRepository(pgDB)
+	sessions := auth.NewPostgresSessionRepository(pgDB)
+	mfaBox, err := auth.SecretBoxFromEnv()
+	if err != nil {
+		log.Fatal("❌ ", err)
+	}
+	mfa := auth.NewMFAService(auth.NewPostgresMFARepository(pgDB), mfaBox, auth.MFAPolicyFromEnv())
+	authorizer := auth.NewAuthorizer(auth.NewPostgresPermissionRepository(pgDB))
+
+	if err := run(context.Background(), users, sessions, mfa, authorizer, os.Args[1], os.Args[2:]); err != nil {
+		log.Fatal("❌ ", err)
+	}
+}
+
+func run(
+	ctx context.Context,
+	users auth.UserRepository,
+	sessions auth.SessionRepository,
+	mfa *auth.MFAService,
+	authorizer *auth.Authorizer,
+	command string,
+	args []string,
+) error {
+	fs := flag.NewFlagSet(command, flag.ExitOnError)
+	email := fs.String("email", "", "user email")
+	name := fs.String("name", "", "display name")
+	password := fs.String("password", "", "password (read from stdin if omitted)")
+	role := fs.String("role", "", "role name")
+	permission := fs.String("permission", "", "permission, e.g. menus:review")
+	_ = fs.Parse(args)
+
+	switch command {
+	case "create-admin":
+		if *email == "" || *name == "" {
+			return errors.New("-email and -name are required")
+		}
+
+		exists, _ := users.ExistsByEmail(*email)
+		if exists {
+			return errors.New("email already exists, use promote instead")
+		}
+
+		hash, err := readAndHashPassword(*password)
+		if err != nil {
+			return err
+		}
+
+		user := &auth.User{
+			Name:     *name,
+			Email:    *email,
+			Password: hash,
+			Role:     string(auth.RoleAdmin),
+
+			EmailVerified: true,
+		}
+		if err := users.Save(user); err != nil {
+			return err
+		}
+
+		log.Printf("✅ Created ADMIN %s (%s)", user.Email, user.ID)
+
+	case "promote":
+		user, err := findByEmail(users, *email)
+		if err != nil {
+			return err
+		}
+		if err := users.UpdateRole(ctx, user.ID, string(auth.RoleAdmin)); err != nil {
+			return err
+		}
+
+		log.Printf("✅ %s is now ADMIN", user.Email)
+
+	case "demote":
+		user, err := findByEmail(users, *email)
+		if err != nil {
+			return err
+		}
+		if err := users.UpdateRole(ctx, user.ID, string(auth.RoleRestaurant)); err != nil {
+			return err
+		}
+		// Existing tokens still carry the ADMIN role, so log the user out.
+		if err := sessions.RevokeUserSessions(ctx, user.ID, "demoted"); err != nil {
+			return err
+		}
+
+		log.Printf("✅ %s is now RESTAURANT, sessions revoked", user.Email)
+
+	case "reset-password":
+		user, err := findByEmail(users, *email)
+		if err != nil {
+			return err
+		}
+
+		hash, err := readAndHashPassword(*password)
+		if err != nil {
+			return err
+		}
+		if err := users.UpdatePassword(ctx, user.ID, hash); err != nil {
+			return err
+		}
+		if err := sessions.RevokeUserSessions(ctx, user.ID, "password_reset"); err != nil {
+			return err
+		}
+
+		log.Printf("✅ Password reset for %s, sessions revoked", user.Email)
+
+	case "reset-mfa":
+		user, err := findByEmail(users, *email)
+		if err != nil {
+			return err
+		}
+		if err := mfa.Reset(ctx, user.ID); err != nil {
+			return err
+		}
+		if err := sessions.RevokeUserSessions(ctx, user.ID, "mfa_reset"); err != nil {
+			return err
+		}
+
+		log.Printf("✅ Two-factor auth removed for %s, sessions revoked", user.Email)
+
+	case "seal-mfa-secrets":
+		all, err := users.ListUsers(ctx)
+		if err != nil {
+			return err
+		}
+		sealed := 0
+		for _, u := range all {
+			ok, err := mfa.SealLegacySecret(ctx, u.ID)
+			if err != nil {
+				return fmt.Errorf("%s: %w", u.Email, err)
+			}
+			if ok {
+				sealed++
+			}
+		}
+
+		log.Printf("✅ Encrypted %d two-factor secrets", sealed)
+
+	case "set-role":
+		user, err := findByEmail(users, *email)
+		if err != nil {
+			return err
+		}
+		newRole := auth.NormalizeRole(*role)
+		exists, err := authorizer.RoleExists(ctx, newRole)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return fmt.Errorf("%w: %q (see list-roles)", auth.ErrUnknownRole, *role)
+		}
+		if err := users.UpdateRole(ctx, user.ID, newRole); err != nil {
+			return err
+		}
+		// Existing tokens still carry the old role.
+		if err := sessions.RevokeUserSessions(ctx, user.ID, "role_changed"); err != nil {
+			return err
+		}
+
+		log.Printf("✅ %s is now %s, sessions revoked", user.Email, newRole)
+
+	case "list-roles":
+		roles, err := authorizer.ListRoles(ctx)
+		if err != nil {
+			return err
+		}
+		names := make([]string, 0, len(roles))
+		for name := range roles {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			perms := make([]string, len(roles[name]))
+			for i, p := range roles[name] {
+				perms[i] = string(p)
+			}
+			fmt.Printf("%-12s  %s\n", name, strings.Join(perms, ", "))
+		}
+
+	case "grant", "revoke":
+		if *role == "" || *permission == "" {
+			return errors.New("-role and -permission are required")
+		}
+		if command == "grant" {
+			if err := authorizer.Grant(ctx, *role, *permission); err != nil {
+				return err
+			}
+		} else {
+			if err := authorizer.Revoke(ctx, *role, *permission); err != nil {
+				return err
+			}
+		}
+
+		log.Printf("✅ %s %s on %s", command, *permission, auth.NormalizeRole(*role))
+
+	case "list-users":
+		all, err := users.ListUsers(ctx)
+		if err != nil {
+			return err
+		}
+		for _, u := range all {
+			fmt.Printf("%-36s  %-12s  %-30s  %s\n", u.ID, u.Role, u.Email, u.Name)
+		}
+
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	return nil
+}
+
+func findByEmail(users auth.UserRepository, email string) (*auth.User, error) {
+	if email == "" {
+		return nil, errors.New("-email is required")
+	}
+	return users.FindByEmail(email)
+}
+
+func readAndHashPassword(password string) (string, error) {
+	if password == "" {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return "", errors.New("no password provided on stdin")
+		}
+		password = strings.TrimRight(line, "\r\n")
+	}
+
+	if err := auth.ValidatePasswordStrength(password); err != nil {
+		return "", err
+	}
+
+	return auth.HashPassword(password)
+}
@@ -2,15 +2,20 @@ package main
 
 import (
 	"context"
+	"errors"
 	"log"
+	"net/http"
 	"os"
 	"os/exec"
+	"os/signal"
+	"syscall"
 	"time"
 	"bhojanalya/internal/auth"
 	"bhojanalya/internal/competition"
 	"bhojanalya/internal/db"
 	"bhojanalya/internal/deals"
 	"bhojanalya/internal/llm"
+	"bhojanalya/internal/mail"
 	"bhojanalya/internal/menu"
 	"bhojanalya/internal/middleware"
 	"bhojanalya/internal/ocr"
@@ -29,10 +34,7 @@ func main() {
 	}
 
 	required := []string{
-		"JWT_SECRET",
 		"DATABASE_URL",
-		"GEMINI_API_KEY",
-		"GEMINI_MODEL",
 		"R2_ACCESS_KEY",
 		"R2_SECRET_KEY",
 		"R2_BUCKET_NAME",
@@ -46,17 +48,24 @@ func main() {
 		}
 	}
 
+	// Cancelled on SIGINT/SIGTERM; see SHUTDOWN below.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	// ───────────────────────── DB ─────────────────────────
 	pgDB := db.ConnectPostgres()
 	defer pgDB.Close()
 
 	// ───────────────────────── GIN ─────────────────────────
 	r := gin.Default()
+	if err := r.SetTrustedProxies(auth.TrustedProxiesFromEnv()); err != nil {
+		log.Fatal("❌ TRUSTED_PROXIES invalid:", err)
+	}
 
 	r.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"http://localhost:3000", "http://localhost:5173"},
 		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", "X-API-Key"},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
 	}))
@@ -68,17 +77,73 @@ func main() {
 	}
 
 	// ───────────────────────── AUTH ─────────────────────────
+	jwtKeys, err := auth.KeySetFromEnv()
+	if err != nil {
+		log.Fatal("❌ JWT keys init failed:", err)
+	}
+	auth.UseKeySet(jwtKeys)
+	jwksHandler := auth.NewJWKSHandler(jwtKeys)
+
 	userRepo := auth.NewPostgresUserRepository(pgDB)
+	sessionRepo := auth.NewPostgresSessionRepository(pgDB)
+	accountTokenRepo := auth.NewPostgresAccountTokenRepository(pgDB)
+
+	mailer, err := mail.NewFromEnv()
+	if err != nil {
+		log.Fatal("❌ Mailer init failed:", err)
+	}
+
 	authService := auth.NewService(userRepo)
-	authHandler := auth.NewHandler(authService)
+	sessionService := auth.NewSessionService(sessionRepo, userRepo)
+	accountService := auth.NewAccountService(userRepo, accountTokenRepo, sessionRepo, mailer)
+	loginGuard := auth.NewLoginGuard(auth.NewFallbackLoginAttemptRepository(
+		auth.NewPostgresLoginAttemptRepository(pgDB),
+		auth.NewInMemoryLoginAttemptRepository(),
+	))
+	mfaBox, err := auth.SecretBoxFromEnv()
+	if err != nil {
+		log.Fatal("❌ MFA encryption key init failed:", err)
+	}
+	mfaService := auth.NewMFAService(auth.NewPostgresMFARepository(pgDB), mfaBox, auth.MFAPolicyFromEnv())
+	authHandler := auth.NewHandler(authService, sessionService, accountService, loginGuard, mfaService)
+	authAdminHandler := auth.NewAdminHandler(loginGuard)
+	authorizer := auth.NewAuthorizer(auth.NewPostgresPermissionRepository(pgDB))
+	policy := middleware.RequirePolicy(authorizer, routePolicy)
+
+	r.GET("/.well-known/jwks.json", jwksHandler.Get)
 
 	authGroup := r.Group("/auth")
 	{
 		authGroup.POST("/register", authHandler.Register)
 		authGroup.POST("/login", authHandler.Login)
+		authGroup.POST("/refresh", authHandler.Refresh)
+		authGroup.POST("/logout", middleware.AuthMiddleware(sessionService), authHandler.Logout)
+
+		authGroup.POST("/password/forgot", authHandler.ForgotPassword)
+		authGroup.POST("/password/reset", authHandler.ResetPassword)
+		authGroup.POST("/verify-email", authHandler.VerifyEmail)
+		authGroup.GET("/verify-email", authHandler.VerifyEmail)
+		authGroup.POST("/verify-email/resend", middleware.AuthMiddleware(sessionService), authHandler.ResendVerification)
+
+		// Second step of login, authenticated with the mfa_token from /auth/login
+		mfaLogin := authGroup.Group("/mfa/login")
+		mfaLogin.Use(middleware.MFAPendingMiddleware(sessionService))
+		{
+			mfaLogin.POST("/enroll", authHandler.MFAEnroll)
+			mfaLogin.POST("/verify", authHandler.MFALoginVerify)
+		}
+
+		mfa := authGroup.Group("/mfa")
+		mfa.Use(middleware.AuthMiddleware(sessionService))
+		{
+			mfa.POST("/enroll", authHandler.MFAEnroll)
+			mfa.POST("/enroll/confirm", authHandler.MFAConfirmEnrollment)
+			mfa.POST("/recovery-codes", authHandler.MFARegenerateRecoveryCodes)
+			mfa.POST("/disable", authHandler.MFADisable)
+		}
 
 		protected := authGroup.Group("/protected")
-		protected.Use(middleware.AuthMiddleware())
+		protected.Use(middleware.AuthMiddleware(sessionService))
 		{
 			protected.GET("/ping", func(c *gin.Context) {
 				c.JSON(200, gin.H{"message": "pong"})
@@ -95,50 +160,128 @@ func main() {
 	menuRepo := menu.NewPostgresRepository(pgDB)
 	competitionRepo := competition.NewRepository(pgDB)
 	dealRepo := deals.NewRepository(pgDB)
+	memberRepo := restaurant.NewPostgresMemberRepository(pgDB)
 
 	// ───────────────────────── SERVICES (ORDER MATTERS) ─────────────────────────
 	menuService := menu.NewService(menuRepo, r2Client)
+	memberService := restaurant.NewMemberService(memberRepo, mailer)
 
 	restaurantService := restaurant.NewService(
 		restaurantRepo,
 		menuService,
 		competitionRepo,
 		r2Client,
+		memberService,
 	)
 
 	dealService := deals.NewService(
 		dealRepo,
 		restaurantRepo,
+		memberService,
 		competitionRepo,
 	)
 
 	competitionService := competition.NewService(pgDB)
 
+	apiKeyService := auth.NewAPIKeyService(
+		auth.NewPostgresAPIKeyRepository(pgDB),
+		userRepo,
+		authorizer,
+		memberService,
+	)
+	// Integrations (POS, dashboards) may call these groups with X-API-Key.
+	apiAuth := middleware.AuthOrAPIKeyMiddleware(sessionService, apiKeyService)
+
+	// ───────────────────────── OCR + LLM PIPELINE ─────────────────────────
+	llmClient, err := llm.NewClientFromEnv()
+	if err != nil {
+		log.Fatal("❌ LLM init failed:", err)
+	}
+	promptVersion, err := llm.PromptVersionFromEnv()
+	if err != nil {
+		log.Fatal("❌ LLM init failed:", err)
+	}
+	ocrRepo := ocr.NewRepository(pgDB)
+	ocrEngine, err := ocr.NewTesseractEngineFromEnv()
+	if err != nil {
+		log.Fatal("❌ OCR engine init failed:", err)
+	}
+	imgPreprocessor, err := ocr.NewImagePreprocessorFromEnv()
+	if err != nil {
+		log.Fatal("❌ OCR preprocessor init failed:", err)
+	}
+
+	ocrService := ocr.NewService(
+		ocrRepo,
+		r2Client,
+		llmClient,
+		promptVersion,
+		menuService,
+		competitionService,
+		ocrEngine,
+		imgPreprocessor,
+	)
+
 	// ───────────────────────── HANDLERS ─────────────────────────
 	restaurantHandler := restaurant.NewHandler(restaurantService)
-	menuHandler := menu.NewHandler(menuService)
+	memberHandler := restaurant.NewMemberHandler(memberService)
+	menuHandler := menu.NewHandler(menuService, memberService)
 	adminMenuHandler := menu.NewAdminHandler(menuService)
 	dealHandler := deals.NewHandler(dealService)
 	competitionHandler := competition.NewHandler(competitionService)
+	pipelineHandler := ocr.NewHandler(ocrService)
+	apiKeyHandler := auth.NewAPIKeyHandler(apiKeyService)
+
+	// ───────────────────────── API KEYS ─────────────────────────
+	// Managed with a normal login only; a key cannot mint more keys.
+	apiKeys := r.Group("/api-keys")
+	apiKeys.Use(middleware.AuthMiddleware(sessionService))
+	{
+		apiKeys.POST("", apiKeyHandler.Create)
+		apiKeys.GET("", apiKeyHandler.List)
+		apiKeys.DELETE("/:id", apiKeyHandler.Revoke)
+	}
 
 	// ───────────────────────── RESTAURANT ROUTES ─────────────────────────
 	restaurants := r.Group("/restaurants")
 	restaurants.Use(
-		middleware.AuthMiddleware(),
-		middleware.RequireRole("RESTAURANT"),
+		apiAuth,
+		policy,
 	)
 	{
-		restaurants.POST("", restaurantHandler.CreateRestaurant)
+		restaurants.POST("", middleware.RequireVerifiedEmail(accountService), restaurantHandler.CreateRestaurant)
 		restaurants.GET("/me", restaurantHandler.ListMyRestaurants)
 		restaurants.GET("/:id/preview", restaurantHandler.Preview)
 		restaurants.POST("/:id/images", restaurantHandler.UploadImages)
+		restaurants.PUT("/:id/menu-language", restaurantHandler.SetMenuLanguage)
+
+		// Staff
+		restaurants.GET("/:id/members", memberHandler.ListMembers)
+		restaurants.PATCH("/:id/members/:userId", memberHandler.ChangeRole)
+		restaurants.DELETE("/:id/members/:userId", memberHandler.RemoveMember)
+		restaurants.POST("/:id/invitations", memberHandler.Invite)
+		restaurants.GET("/:id/invitations", memberHandler.ListInvitations)
+		restaurants.DELETE("/:id/invitations/:invitationId", memberHandler.RevokeInvitation)
+	}
+
+	// ───────────────────────── INVITATIONS ─────────────────────────
+	invitations := r.Group("/invitations")
+	invitations.Use(
+		middleware.AuthMiddleware(sessionService),
+		policy,
+	)
+	{
+		invitations.GET("", memberHandler.MyInvitations)
+		// Accepting proves ownership of the invited address, so it must be verified.
+		invitations.POST("/:id/accept", middleware.RequireVerifiedEmail(accountService), memberHandler.AcceptInvitation)
+		invitations.POST("/:id/decline", memberHandler.DeclineInvitation)
 	}
 
 	// ───────────────────────── DEAL ROUTES ─────────────────────────
 	dealsGroup := r.Group("/restaurants/:id/deals")
 	dealsGroup.Use(
-		middleware.AuthMiddleware(),
-		middleware.RequireRole("RESTAURANT"),
+		apiAuth,
+		policy,
 	)
 	{
 		dealsGroup.GET("/suggestion", dealHandler.GetDealSuggestion())
@@ -148,8 +291,8 @@ func main() {
 
 	deleteDeal := r.Group("/deals")
 	deleteDeal.Use(
-		middleware.AuthMiddleware(),
-		middleware.RequireRole("RESTAURANT"),
+		apiAuth,
+		policy,
 	)
 	{
 		deleteDeal.DELETE("/:id", dealHandler.DeleteDeal())
@@ -158,7 +301,10 @@ func main() {
 
 	// ───────────────────────── MENU ROUTES ─────────────────────────
 	menus := r.Group("/menus")
-	menus.Use(middleware.AuthMiddleware())
+	menus.Use(
+		apiAuth,
+		policy,
+	)
 	{
 		menus.POST("/upload", menuHandler.Upload)
 
@@ -170,41 +316,43 @@ func main() {
 	}
 
 	// ───────────────────────── ADMIN ROUTES ─────────────────────────
+	// Bearer tokens only: an API key would skip the admin's second factor.
 	admin := r.Group("/admin")
 	admin.Use(
-		middleware.AuthMiddleware(),
-		middleware.RequireRole("ADMIN"),
+		middleware.AuthMiddleware(sessionService),
+		policy,
 	)
 	{
 		// Restaurants
 		admin.GET("/restaurants/approved", restaurantHandler.ListApprovedRestaurants)
 		admin.GET("/restaurants/:id", restaurantHandler.GetAdminRestaurantDetails)
+		admin.GET("/restaurants/:id/preview", restaurantHandler.AdminPreview)
 		admin.POST("/restaurants/:id/approve", restaurantHandler.ApproveRestaurant)
 
 		// Menus
 		admin.GET("/menus/pending", adminMenuHandler.PendingMenus)
+		admin.GET("/menus/pipeline", pipelineHandler.Stats)
+		admin.GET("/menus/dead-letter", pipelineHandler.DeadLetters)
+		admin.POST("/menus/dead-letter/requeue", pipelineHandler.Requeue)
+		admin.GET("/menus/dead-letter/:id/errors", pipelineHandler.JobErrors)
+		admin.GET("/menus/:id/timeline", adminMenuHandler.Timeline)
+		admin.POST("/menus/reparse", pipelineHandler.Reparse)
 
 		// Competition (manual fallback)
 		admin.POST("/competition/recompute", competitionHandler.Recompute)
+
+		// Login lockouts
+		admin.GET("/lockouts", authAdminHandler.ListLockouts)
+		admin.DELETE("/lockouts/:scope/:subject", authAdminHandler.ClearLockout)
 	}
 
 	// ───────────────────────── PUBLIC ─────────────────────────
 	r.GET("/competition/insights", competitionHandler.Get)
 
 	// ───────────────────────── OCR + LLM WORKERS ─────────────────────────
-	llmClient := llm.NewGeminiClient()
-	ocrRepo := ocr.NewRepository(pgDB)
-
-	ocrService := ocr.NewService(
-		ocrRepo,
-		r2Client,
-		llmClient,
-		menuService,
-		competitionService,
-	)
-
-	go ocrService.RunOCRWorker()
-	go ocrService.RunLLMWorker()
+	go ocrService.RunOCRWorker(ctx)
+	go ocrService.RunLLMWorker(ctx)
+	go ocrService.RunReaper(ctx)
 
 	// ───────────────────────── HEALTH ─────────────────────────
 	r.GET("/health", func(c *gin.Context) {
@@ -212,8 +360,43 @@ func main() {
 	})
 
 	// ───────────────────────── START ─────────────────────────
-	log.Println("🚀 API running at http://localhost:8000")
-	r.Run(":8000")
+	srv := &http.Server{Addr: ":8000", Handler: r}
+
+	go func() {
+		log.Println("🚀 API running at http://localhost:8000")
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal("❌ HTTP server failed:", err)
+		}
+	}()
+
+	// ───────────────────────── SHUTDOWN ─────────────────────────
+	// Stop taking requests and jobs, then give in-flight ones until the
+	// deadline before cancelling them.
+	<-ctx.Done()
+	stop()
+
+	timeout := shutdownTimeoutFromEnv()
+	log.Printf("🛑 Shutting down (deadline %s)", timeout)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Println("HTTP shutdown:", err)
+	}
+	if err := ocrService.Shutdown(shutdownCtx); err != nil {
+		log.Println("Pipeline shutdown:", err)
+	}
+	log.Println("👋 Stopped")
+}
+
+// shutdownTimeoutFromEnv reads SHUTDOWN_TIMEOUT, default 30s: how long
+// requests and pipeline jobs in flight get to finish on shutdown.
+func shutdownTimeoutFromEnv() time.Duration {
+	if d, err := time.ParseDuration(os.Getenv("SHUTDOWN_TIMEOUT")); err == nil && d > 0 {
+		return d
+	}
+	return 30 * time.Second
 }
 
 // --------------------------------------------------
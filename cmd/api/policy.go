@@ -0,0 +1,48 @@
+package main
+
+import "bhojanalya/internal/auth"
+
+// routePolicy is the permission each authenticated route requires, keyed
+// by "METHOD /full/path". middleware.RequirePolicy refuses any route that
+// is missing here. Per-restaurant checks (OWNER/MANAGER/STAFF) happen in
+// the services on top of this.
+var routePolicy = map[string][]auth.Permission{
+	// Restaurant portal
+	"POST /restaurants":                                 {auth.PermRestaurantsManage},
+	"GET /restaurants/me":                               {auth.PermRestaurantsManage},
+	"GET /restaurants/:id/preview":                      {auth.PermRestaurantsManage},
+	"POST /restaurants/:id/images":                      {auth.PermRestaurantsManage},
+	"PUT /restaurants/:id/menu-language":                {auth.PermRestaurantsManage},
+	"GET /restaurants/:id/members":                      {auth.PermRestaurantsManage},
+	"PATCH /restaurants/:id/members/:userId":            {auth.PermRestaurantsManage},
+	"DELETE /restaurants/:id/members/:userId":           {auth.PermRestaurantsManage},
+	"POST /restaurants/:id/invitations":                 {auth.PermRestaurantsManage},
+	"GET /restaurants/:id/invitations":                  {auth.PermRestaurantsManage},
+	"DELETE /restaurants/:id/invitations/:invitationId": {auth.PermRestaurantsManage},
+	"GET /invitations":                                  {auth.PermRestaurantsManage},
+	"POST /invitations/:id/accept":                      {auth.PermRestaurantsManage},
+	"POST /invitations/:id/decline":                     {auth.PermRestaurantsManage},
+	"GET /restaurants/:id/deals/suggestion":             {auth.PermRestaurantsManage},
+	"POST /restaurants/:id/deals":                       {auth.PermRestaurantsManage},
+	"GET /restaurants/:id/deals":                        {auth.PermRestaurantsManage},
+	"DELETE /deals/:id":                                 {auth.PermRestaurantsManage},
+	"POST /menus/upload":                                {auth.PermRestaurantsManage},
+	"GET /menus/:restaurant_id/status":                  {auth.PermRestaurantsManage},
+	"POST /menus/:restaurant_id/retry":                  {auth.PermRestaurantsManage},
+
+	// Platform staff
+	"GET /admin/restaurants/approved":         {auth.PermRestaurantsRead},
+	"GET /admin/restaurants/:id":              {auth.PermRestaurantsRead},
+	"GET /admin/restaurants/:id/preview":      {auth.PermRestaurantsRead},
+	"POST /admin/restaurants/:id/approve":     {auth.PermRestaurantsApprove, auth.PermDealsApprove},
+	"GET /admin/menus/pending":                {auth.PermMenusReview},
+	"GET /admin/menus/pipeline":               {auth.PermMenusReview},
+	"GET /admin/menus/dead-letter":            {auth.PermMenusReview},
+	"POST /admin/menus/dead-letter/requeue":   {auth.PermPipelineManage},
+	"GET /admin/menus/dead-letter/:id/errors": {auth.PermMenusReview},
+	"GET /admin/menus/:id/timeline":           {auth.PermMenusReview},
+	"POST /admin/menus/reparse":               {auth.PermPipelineManage},
+	"POST /admin/competition/recompute":       {auth.PermCompetitionRecompute},
+	"GET /admin/lockouts":                     {auth.PermLockoutsManage},
+	"DELETE /admin/lockouts/:scope/:subject":  {auth.PermLockoutsManage},
+}
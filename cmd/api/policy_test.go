@@ -0,0 +1,104 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"bhojanalya/internal/auth"
+	"bhojanalya/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// expectedAccess lists, for every route in routePolicy, the built-in roles
+// that may call it. Adding a route without adding it here fails the test.
+var expectedAccess = map[string][]auth.Role{
+	"POST /restaurants":                                 {auth.RoleRestaurant},
+	"GET /restaurants/me":                               {auth.RoleRestaurant},
+	"GET /restaurants/:id/preview":                      {auth.RoleRestaurant},
+	"POST /restaurants/:id/images":                      {auth.RoleRestaurant},
+	"PUT /restaurants/:id/menu-language":                {auth.RoleRestaurant},
+	"GET /restaurants/:id/members":                      {auth.RoleRestaurant},
+	"PATCH /restaurants/:id/members/:userId":            {auth.RoleRestaurant},
+	"DELETE /restaurants/:id/members/:userId":           {auth.RoleRestaurant},
+	"POST /restaurants/:id/invitations":                 {auth.RoleRestaurant},
+	"GET /restaurants/:id/invitations":                  {auth.RoleRestaurant},
+	"DELETE /restaurants/:id/invitations/:invitationId": {auth.RoleRestaurant},
+	"GET /invitations":                                  {auth.RoleRestaurant},
+	"POST /invitations/:id/accept":                      {auth.RoleRestaurant},
+	"POST /invitations/:id/decline":                     {auth.RoleRestaurant},
+	"GET /restaurants/:id/deals/suggestion":             {auth.RoleRestaurant},
+	"POST /restaurants/:id/deals":                       {auth.RoleRestaurant},
+	"GET /restaurants/:id/deals":                        {auth.RoleRestaurant},
+	"DELETE /deals/:id":                                 {auth.RoleRestaurant},
+	"POST /menus/upload":                                {auth.RoleRestaurant},
+	"GET /menus/:restaurant_id/status":                  {auth.RoleRestaurant},
+	"POST /menus/:restaurant_id/retry":                  {auth.RoleRestaurant},
+
+	"GET /admin/restaurants/approved":         {auth.RoleAdmin, auth.RoleAnalyst, auth.RoleReviewer},
+	"GET /admin/restaurants/:id":              {auth.RoleAdmin, auth.RoleAnalyst, auth.RoleReviewer},
+	"GET /admin/restaurants/:id/preview":      {auth.RoleAdmin, auth.RoleAnalyst, auth.RoleReviewer},
+	"POST /admin/restaurants/:id/approve":     {auth.RoleAdmin},
+	"GET /admin/menus/pending":                {auth.RoleAdmin, auth.RoleReviewer},
+	"GET /admin/menus/pipeline":               {auth.RoleAdmin, auth.RoleReviewer},
+	"GET /admin/menus/dead-letter":            {auth.RoleAdmin, auth.RoleReviewer},
+	"POST /admin/menus/dead-letter/requeue":   {auth.RoleAdmin},
+	"GET /admin/menus/dead-letter/:id/errors": {auth.RoleAdmin, auth.RoleReviewer},
+	"GET /admin/menus/:id/timeline":           {auth.RoleAdmin, auth.RoleReviewer},
+	"POST /admin/menus/reparse":               {auth.RoleAdmin},
+	"POST /admin/competition/recompute":       {auth.RoleAdmin},
+	"GET /admin/lockouts":                     {auth.RoleAdmin},
+	"DELETE /admin/lockouts/:scope/:subject":  {auth.RoleAdmin},
+}
+
+func TestRoutePolicy(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	for route := range routePolicy {
+		if _, ok := expectedAccess[route]; !ok {
+			t.Errorf("%s: missing from expectedAccess", route)
+		}
+	}
+
+	authorizer := auth.NewAuthorizer(auth.NewInMemoryPermissionRepository())
+	roles := []auth.Role{auth.RoleAdmin, auth.RoleRestaurant, auth.RoleAnalyst, auth.RoleReviewer, "CUSTOM"}
+
+	for route, allowed := range expectedAccess {
+		method, path, _ := strings.Cut(route, " ")
+
+		for _, role := range roles {
+			router := gin.New()
+			router.Use(func(c *gin.Context) { c.Set("userRole", string(role)) })
+			router.Use(middleware.RequirePolicy(authorizer, routePolicy))
+			router.Handle(method, path, func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			want := http.StatusForbidden
+			for _, r := range allowed {
+				if r == role {
+					want = http.StatusOK
+				}
+			}
+
+			req := httptest.NewRequest(method, samplePath(path), nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != want {
+				t.Errorf("%s as %s: expected status %d, got %d", route, role, want, w.Code)
+			}
+		}
+	}
+}
+
+// samplePath fills route parameters with a placeholder value.
+func samplePath(path string) string {
+	parts := strings.Split(path, "/")
+	for i, p := range parts {
+		if strings.HasPrefix(p, ":") {
+			parts[i] = "1"
+		}
+	}
+	return strings.Join(parts, "/")
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"bhojanalya/internal/db"
+
+	"github.com/joho/godotenv"
+)
+
+const usage = `usage: migrate <command> [flags]
+
+commands:
+  up                apply all pending migrations
+  down [-steps N]   roll back the last N migrations (default 1)
+  status            list migrations and whether they are applied
+`
+
+func main() {
+	if os.Getenv("APP_ENV") != "production" {
+		_ = godotenv.Load()
+	}
+
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	pgDB := db.OpenPostgres()
+	defer pgDB.Close()
+
+	migrator, err := db.NewMigrator(pgDB)
+	if err != nil {
+		log.Fatal("❌ Failed to load migrations:", err)
+	}
+
+	ctx := context.Background()
+
+	switch os.Args[1] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			log.Fatal("❌ ", err)
+		}
+		log.Printf("✅ Applied %d migration(s)", len(applied))
+
+	case "down":
+		fs := flag.NewFlagSet("down", flag.ExitOnError)
+		steps := fs.Int("steps", 1, "number of migrations to roll back")
+		_ = fs.Parse(os.Args[2:])
+
+		rolledBack, err := migrator.Down(ctx, *steps)
+		if err != nil {
+			log.Fatal("❌ ", err)
+		}
+		log.Printf("✅ Rolled back %d migration(s)", len(rolledBack))
+
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			log.Fatal("❌ ", err)
+		}
+		for _, st := range statuses {
+			state := "pending"
+			if st.Applied {
+				state = "applied " + st.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			if st.Dirty {
+				state += " (checksum mismatch)"
+			}
+			fmt.Printf("%04d  %-30s  %s\n", st.Version, st.Name, state)
+		}
+
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+}
@@ -0,0 +1,180 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"time"
+
+	"bhojanalya/internal/mail"
+)
+
+const (
+	passwordResetTTL = 1 * time.Hour
+	emailVerifyTTL   = 48 * time.Hour
+)
+
+// AccountService handles password recovery and email verification.
+type AccountService struct {
+	users    UserRepository
+	tokens   AccountTokenRepository
+	sessions SessionRepository
+	mailer   mail.Mailer
+	baseURL  string
+}
+
+func NewAccountService(
+	users UserRepository,
+	tokens AccountTokenRepository,
+	sessions SessionRepository,
+	mailer mail.Mailer,
+) *AccountService {
+	baseURL := os.Getenv("APP_BASE_URL")
+	if baseURL == "" {
+		baseURL = "http://localhost:3000"
+	}
+
+	return &AccountService{
+		users:    users,
+		tokens:   tokens,
+		sessions: sessions,
+		mailer:   mailer,
+		baseURL:  baseURL,
+	}
+}
+
+// --------------------------------------------------
+// Password reset
+// --------------------------------------------------
+
+// RequestPasswordReset emails a reset link. It never reveals whether the
+// email is registered.
+func (s *AccountService) RequestPasswordReset(ctx context.Context, email string) error {
+	user, err := s.users.FindByEmail(email)
+	if err != nil {
+		log.Printf("Password reset requested for unknown email: %s", email)
+		return nil
+	}
+
+	token, err := s.issueToken(ctx, user.ID, TokenPurposePasswordReset, passwordResetTTL)
+	if err != nil {
+		return err
+	}
+
+	return s.mailer.Send(ctx, mail.Message{
+		To:      user.Email,
+		Subject: "Reset your Bhojanalya password",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nUse the link below to reset your password. It expires in %s.\n\n%s\n\nIf you did not request this, you can ignore this email.\n",
+			user.Name,
+			passwordResetTTL,
+			s.link("/reset-password", token),
+		),
+	})
+}
+
+// ResetPassword sets a new password and logs the user out everywhere.
+func (s *AccountService) ResetPassword(ctx context.Context, token string, newPassword string) error {
+	if err := ValidatePasswordStrength(newPassword); err != nil {
+		return err
+	}
+
+	userID, err := s.tokens.Consume(ctx, hashToken(token), TokenPurposePasswordReset)
+	if err != nil {
+		return err
+	}
+
+	hash, err := HashPassword(newPassword)
+	if err != nil {
+		return err
+	}
+
+	if err := s.users.UpdatePassword(ctx, userID, hash); err != nil {
+		return err
+	}
+
+	// Following the link also proves the user owns the address.
+	_ = s.users.MarkEmailVerified(ctx, userID)
+
+	return s.sessions.RevokeUserSessions(ctx, userID, "password_reset")
+}
+
+// --------------------------------------------------
+// Email verification
+// --------------------------------------------------
+
+func (s *AccountService) SendVerificationEmail(ctx context.Context, user *User) error {
+	if user.EmailVerified {
+		return nil
+	}
+
+	token, err := s.issueToken(ctx, user.ID, TokenPurposeEmailVerify, emailVerifyTTL)
+	if err != nil {
+		return err
+	}
+
+	return s.mailer.Send(ctx, mail.Message{
+		To:      user.Email,
+		Subject: "Verify your Bhojanalya email",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nPlease confirm your email address to start listing your restaurant:\n\n%s\n",
+			user.Name,
+			s.link("/verify-email", token),
+		),
+	})
+}
+
+// ResendVerificationEmail is used by logged-in users who lost the first email.
+func (s *AccountService) ResendVerificationEmail(ctx context.Context, userID string) error {
+	user, err := s.users.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	return s.SendVerificationEmail(ctx, user)
+}
+
+func (s *AccountService) VerifyEmail(ctx context.Context, token string) error {
+	userID, err := s.tokens.Consume(ctx, hashToken(token), TokenPurposeEmailVerify)
+	if err != nil {
+		return err
+	}
+	return s.users.MarkEmailVerified(ctx, userID)
+}
+
+// IsEmailVerified is used to gate restaurant creation.
+func (s *AccountService) IsEmailVerified(ctx context.Context, userID string) (bool, error) {
+	return s.users.IsEmailVerified(ctx, userID)
+}
+
+// --------------------------------------------------
+// Helpers
+// --------------------------------------------------
+
+func (s *AccountService) issueToken(
+	ctx context.Context,
+	userID string,
+	purpose string,
+	ttl time.Duration,
+) (string, error) {
+	plain, err := newOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.tokens.Save(ctx, &AccountToken{
+		UserID:    userID,
+		Purpose:   purpose,
+		TokenHash: hashToken(plain),
+		ExpiresAt: time.Now().Add(ttl),
+	}); err != nil {
+		return "", err
+	}
+
+	return plain, nil
+}
+
+func (s *AccountService) link(path string, token string) string {
+	return s.baseURL + path + "?token=" + url.QueryEscape(token)
+}
@@ -0,0 +1,161 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+
+	"bhojanalya/internal/mail"
+)
+
+type captureMailer struct {
+	sent []mail.Message
+}
+
+func (m *captureMailer) Send(ctx context.Context, msg mail.Message) error {
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+var tokenInLink = regexp.MustCompile(`\?token=(\S+)`)
+
+func (m *captureMailer) lastToken(t *testing.T) string {
+	t.Helper()
+	if len(m.sent) == 0 {
+		t.Fatalf("no email sent")
+	}
+	match := tokenInLink.FindStringSubmatch(m.sent[len(m.sent)-1].Body)
+	if match == nil {
+		t.Fatalf("no token link in email body")
+	}
+	token, _ := url.QueryUnescape(match[1])
+	return token
+}
+
+func newTestAccountService(t *testing.T) (*AccountService, *InMemoryUserRepository, *captureMailer, *User) {
+	users := NewInMemoryUserRepository()
+	mailer := &captureMailer{}
+	accounts := NewAccountService(
+		users,
+		NewInMemoryAccountTokenRepository(),
+		NewInMemorySessionRepository(),
+		mailer,
+	)
+
+	user, err := NewService(users).Register("Test User", "test@example.com", "Password@123")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	return accounts, users, mailer, user
+}
+
+func TestPasswordResetFlow(t *testing.T) {
+	accounts, users, mailer, _ := newTestAccountService(t)
+	ctx := context.Background()
+
+	if err := accounts.RequestPasswordReset(ctx, "test@example.com"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	token := mailer.lastToken(t)
+
+	if err := accounts.ResetPassword(ctx, token, "NewPassword@456"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := NewService(users).Login("test@example.com", "NewPassword@456"); err != nil {
+		t.Fatalf("expected login with new password, got %v", err)
+	}
+
+	// Tokens are single-use
+	if err := accounts.ResetPassword(ctx, token, "Another@789"); !errors.Is(err, ErrInvalidAccountToken) {
+		t.Fatalf("expected invalid token on reuse, got %v", err)
+	}
+}
+
+func TestPasswordResetUnknownEmailSendsNothing(t *testing.T) {
+	accounts, _, mailer, _ := newTestAccountService(t)
+
+	if err := accounts.RequestPasswordReset(context.Background(), "nobody@example.com"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(mailer.sent) != 0 {
+		t.Fatalf("expected no email for unknown address")
+	}
+}
+
+func TestPasswordResetRejectsWeakPassword(t *testing.T) {
+	accounts, _, mailer, _ := newTestAccountService(t)
+	ctx := context.Background()
+
+	_ = accounts.RequestPasswordReset(ctx, "test@example.com")
+	token := mailer.lastToken(t)
+
+	if err := accounts.ResetPassword(ctx, token, "short"); !errors.Is(err, ErrWeakPassword) {
+		t.Fatalf("expected weak password error, got %v", err)
+	}
+
+	// A rejected attempt must not burn the token
+	if err := accounts.ResetPassword(ctx, token, "LongEnough@1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestNewResetTokenInvalidatesOlder(t *testing.T) {
+	accounts, _, mailer, _ := newTestAccountService(t)
+	ctx := context.Background()
+
+	_ = accounts.RequestPasswordReset(ctx, "test@example.com")
+	first := mailer.lastToken(t)
+	_ = accounts.RequestPasswordReset(ctx, "test@example.com")
+
+	if err := accounts.ResetPassword(ctx, first, "NewPassword@456"); !errors.Is(err, ErrInvalidAccountToken) {
+		t.Fatalf("expected older token to be invalidated, got %v", err)
+	}
+}
+
+func TestExpiredAccountToken(t *testing.T) {
+	repo := NewInMemoryAccountTokenRepository()
+	ctx := context.Background()
+
+	_ = repo.Save(ctx, &AccountToken{
+		UserID:    "user-1",
+		Purpose:   TokenPurposeEmailVerify,
+		TokenHash: hashToken("expired"),
+		ExpiresAt: time.Now().Add(-time.Minute),
+	})
+
+	if _, err := repo.Consume(ctx, hashToken("expired"), TokenPurposeEmailVerify); !errors.Is(err, ErrInvalidAccountToken) {
+		t.Fatalf("expected expired token to be rejected, got %v", err)
+	}
+}
+
+func TestEmailVerificationFlow(t *testing.T) {
+	accounts, _, mailer, user := newTestAccountService(t)
+	ctx := context.Background()
+
+	if verified, _ := accounts.IsEmailVerified(ctx, user.ID); verified {
+		t.Fatalf("new users must start unverified")
+	}
+
+	if err := accounts.SendVerificationEmail(ctx, user); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	token := mailer.lastToken(t)
+
+	// A verification token cannot be used to reset a password
+	if err := accounts.ResetPassword(ctx, token, "NewPassword@456"); !errors.Is(err, ErrInvalidAccountToken) {
+		t.Fatalf("expected purpose mismatch to be rejected, got %v", err)
+	}
+
+	if err := accounts.VerifyEmail(ctx, token); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if verified, _ := accounts.IsEmailVerified(ctx, user.ID); !verified {
+		t.Fatalf("expected email to be verified")
+	}
+}
@@ -0,0 +1,34 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Purposes of single-use account tokens.
+const (
+	TokenPurposePasswordReset = "PASSWORD_RESET"
+	TokenPurposeEmailVerify   = "EMAIL_VERIFY"
+)
+
+var ErrInvalidAccountToken = errors.New("invalid or expired token")
+
+// AccountToken is a single-use, expiring token stored by hash.
+type AccountToken struct {
+	ID        string
+	UserID    string
+	Purpose   string
+	TokenHash string
+	ExpiresAt time.Time
+}
+
+type AccountTokenRepository interface {
+	// Save stores a new token and invalidates older unused tokens of the
+	// same purpose for that user.
+	Save(ctx context.Context, token *AccountToken) error
+
+	// Consume marks a valid token as used and returns its user ID.
+	// It returns ErrInvalidAccountToken if the token is unknown, used or expired.
+	Consume(ctx context.Context, tokenHash string, purpose string) (string, error)
+}
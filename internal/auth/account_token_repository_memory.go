@@ -0,0 +1,55 @@
+package auth
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type inMemoryAccountToken struct {
+	AccountToken
+	used bool
+}
+
+type InMemoryAccountTokenRepository struct {
+	mu     sync.Mutex
+	tokens map[string]*inMemoryAccountToken // keyed by token hash
+}
+
+func NewInMemoryAccountTokenRepository() *InMemoryAccountTokenRepository {
+	return &InMemoryAccountTokenRepository{
+		tokens: make(map[string]*inMemoryAccountToken),
+	}
+}
+
+func (r *InMemoryAccountTokenRepository) Save(ctx context.Context, token *AccountToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, t := range r.tokens {
+		if t.UserID == token.UserID && t.Purpose == token.Purpose {
+			t.used = true
+		}
+	}
+
+	if token.ID == "" {
+		token.ID = uuid.New().String()
+	}
+	r.tokens[token.TokenHash] = &inMemoryAccountToken{AccountToken: *token}
+	return nil
+}
+
+func (r *InMemoryAccountTokenRepository) Consume(ctx context.Context, tokenHash string, purpose string) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	t, ok := r.tokens[tokenHash]
+	if !ok || t.used || t.Purpose != purpose || time.Now().After(t.ExpiresAt) {
+		return "", ErrInvalidAccountToken
+	}
+
+	t.used = true
+	return t.UserID, nil
+}
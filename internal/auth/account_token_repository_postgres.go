@@ -0,0 +1,81 @@
+package auth
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PostgresAccountTokenRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewPostgresAccountTokenRepository(db *pgxpool.Pool) *PostgresAccountTokenRepository {
+	return &PostgresAccountTokenRepository{db: db}
+}
+
+func (r *PostgresAccountTokenRepository) Save(ctx context.Context, token *AccountToken) error {
+	if token.ID == "" {
+		token.ID = uuid.New().String()
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	// Only the most recent token of each purpose stays usable.
+	_, err = tx.Exec(ctx, `
+		UPDATE account_tokens
+		SET used_at = now()
+		WHERE user_id = $1
+		  AND purpose = $2
+		  AND used_at IS NULL
+	`, token.UserID, token.Purpose)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO account_tokens (id, user_id, purpose, token_hash, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`,
+		token.ID,
+		token.UserID,
+		token.Purpose,
+		token.TokenHash,
+		token.ExpiresAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *PostgresAccountTokenRepository) Consume(ctx context.Context, tokenHash string, purpose string) (string, error) {
+	var userID string
+
+	err := r.db.QueryRow(ctx, `
+		UPDATE account_tokens
+		SET used_at = now()
+		WHERE token_hash = $1
+		  AND purpose = $2
+		  AND used_at IS NULL
+		  AND expires_at > now()
+		RETURNING user_id
+	`, tokenHash, purpose).Scan(&userID)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", ErrInvalidAccountToken
+		}
+		return "", err
+	}
+
+	return userID, nil
+}
@@ -0,0 +1,56 @@
+package auth
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AdminHandler struct {
+	guard *LoginGuard
+}
+
+func NewAdminHandler(guard *LoginGuard) *AdminHandler {
+	return &AdminHandler{guard: guard}
+}
+
+// --------------------------------------------------
+// GET /admin/lockouts
+// --------------------------------------------------
+func (h *AdminHandler) ListLockouts(c *gin.Context) {
+	lockouts, err := h.guard.ListLockouts(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch lockouts"})
+		return
+	}
+
+	if lockouts == nil {
+		lockouts = []LoginAttempt{}
+	}
+
+	c.JSON(http.StatusOK, lockouts)
+}
+
+// --------------------------------------------------
+// DELETE /admin/lockouts/:scope/:subject
+// --------------------------------------------------
+func (h *AdminHandler) ClearLockout(c *gin.Context) {
+	scope := c.Param("scope")
+	subject := c.Param("subject")
+
+	if scope != "account" && scope != "ip" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "scope must be account or ip"})
+		return
+	}
+
+	if err := h.guard.ClearLockout(c.Request.Context(), scope, subject); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "lockout cleared",
+		"scope":   scope,
+		"subject": subject,
+	})
+}
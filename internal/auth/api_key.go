@@ -0,0 +1,288 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"bhojanalya/internal/core"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidAPIKey         = errors.New("invalid api key")
+	ErrAPIKeyNotFound        = errors.New("api key not found")
+	ErrAPIKeyNameRequired    = errors.New("name is required")
+	ErrAPIKeyScopesRequired  = errors.New("at least one scope is required")
+	ErrAPIKeyScopeNotGranted = errors.New("your role does not grant one of the requested scopes")
+	ErrAPIKeyScopeReserved   = errors.New("admin scopes cannot be granted to api keys")
+	ErrAPIKeyExpiry          = errors.New("expires_in_days must be between 1 and 365")
+	ErrAPIKeyRestaurant      = errors.New("you are not a member of that restaurant")
+	ErrAPIKeyLimit           = errors.New("too many active api keys")
+)
+
+const (
+	apiKeyPrefix            = "bk_"
+	defaultAPIKeyExpiryDays = 90
+	maxAPIKeyExpiryDays     = 365
+	maxActiveAPIKeys        = 25
+
+	// last_used_at is only written when it is older than this, so a busy
+	// integration does not cause a write per request.
+	apiKeyLastUsedGranularity = time.Minute
+)
+
+// reservedAPIKeyScopes are admin actions that need a bearer session, and
+// with it the admin's second factor. No key may carry them.
+var reservedAPIKeyScopes = map[Permission]bool{
+	PermRestaurantsApprove:   true,
+	PermDealsApprove:         true,
+	PermPipelineManage:       true,
+	PermCompetitionRecompute: true,
+	PermLockoutsManage:       true,
+}
+
+// CreateAPIKeyRequest is the body of POST /api-keys.
+type CreateAPIKeyRequest struct {
+	Name          string   `json:"name"`
+	Scopes        []string `json:"scopes"`
+	RestaurantID  *int     `json:"restaurant_id"`
+	ExpiresInDays int      `json:"expires_in_days"`
+}
+
+// APIKeyPrincipal is who a request authenticated with an API key acts as.
+// Role is the owner's current role, so demoting a user also narrows
+// their keys.
+type APIKeyPrincipal struct {
+	KeyID        string
+	UserID       string
+	Email        string
+	Role         string
+	Scopes       []Permission
+	RestaurantID *int
+}
+
+type APIKeyService struct {
+	repo       APIKeyRepository
+	users      UserRepository
+	authorizer *Authorizer
+	access     core.RestaurantAccess
+	now        func() time.Time
+}
+
+func NewAPIKeyService(
+	repo APIKeyRepository,
+	users UserRepository,
+	authorizer *Authorizer,
+	access core.RestaurantAccess,
+) *APIKeyService {
+	return &APIKeyService{
+		repo:       repo,
+		users:      users,
+		authorizer: authorizer,
+		access:     access,
+		now:        time.Now,
+	}
+}
+
+// Create returns the plaintext key, which is never stored or shown again.
+func (s *APIKeyService) Create(ctx context.Context, userID string, req CreateAPIKeyRequest) (string, *APIKey, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" || len(name) > 100 {
+		return "", nil, ErrAPIKeyNameRequired
+	}
+
+	days := req.ExpiresInDays
+	if days == 0 {
+		days = defaultAPIKeyExpiryDays
+	}
+	if days < 1 || days > maxAPIKeyExpiryDays {
+		return "", nil, ErrAPIKeyExpiry
+	}
+
+	user, err := s.users.FindByID(ctx, userID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	scopes, err := s.parseScopes(ctx, user.Role, req.Scopes)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if req.RestaurantID != nil {
+		ok, err := s.access.CanAccess(ctx, *req.RestaurantID, userID, core.ActionViewRestaurant)
+		if err != nil {
+			return "", nil, err
+		}
+		if !ok {
+			return "", nil, ErrAPIKeyRestaurant
+		}
+	}
+
+	existing, err := s.repo.ListByUser(ctx, userID)
+	if err != nil {
+		return "", nil, err
+	}
+	active := 0
+	for _, k := range existing {
+		if s.usable(&k) {
+			active++
+		}
+	}
+	if active >= maxActiveAPIKeys {
+		return "", nil, ErrAPIKeyLimit
+	}
+
+	plain, prefix, err := newAPIKey()
+	if err != nil {
+		return "", nil, err
+	}
+
+	expiresAt := s.now().AddDate(0, 0, days)
+	key := &APIKey{
+		UserID:       userID,
+		RestaurantID: req.RestaurantID,
+		Name:         name,
+		Prefix:       prefix,
+		KeyHash:      hashToken(plain),
+		Scopes:       scopes,
+		ExpiresAt:    &expiresAt,
+	}
+	if err := s.repo.Create(ctx, key); err != nil {
+		return "", nil, err
+	}
+
+	return plain, key, nil
+}
+
+func (s *APIKeyService) List(ctx context.Context, userID string) ([]APIKey, error) {
+	return s.repo.ListByUser(ctx, userID)
+}
+
+func (s *APIKeyService) Revoke(ctx context.Context, userID string, id string) error {
+	if _, err := uuid.Parse(id); err != nil {
+		return ErrAPIKeyNotFound
+	}
+	return s.repo.Revoke(ctx, userID, id)
+}
+
+// Authenticate resolves an X-API-Key header value. Every kind of failure
+// is reported as ErrInvalidAPIKey.
+func (s *APIKeyService) Authenticate(ctx context.Context, plain string) (*APIKeyPrincipal, error) {
+	plain = strings.TrimSpace(plain)
+	prefix, ok := parseAPIKey(plain)
+	if !ok {
+		return nil, ErrInvalidAPIKey
+	}
+
+	key, err := s.repo.GetByPrefix(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+	if key == nil {
+		return nil, ErrInvalidAPIKey
+	}
+	if subtle.ConstantTimeCompare([]byte(key.KeyHash), []byte(hashToken(plain))) != 1 {
+		return nil, ErrInvalidAPIKey
+	}
+	if !s.usable(key) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	user, err := s.users.FindByID(ctx, key.UserID)
+	if err != nil {
+		return nil, ErrInvalidAPIKey
+	}
+
+	now := s.now()
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= apiKeyLastUsedGranularity {
+		if err := s.repo.TouchLastUsed(ctx, key.ID, now); err != nil {
+			log.Printf("[APIKEY] failed to record last use of %s: %v", key.Prefix, err)
+		}
+	}
+
+	return &APIKeyPrincipal{
+		KeyID:        key.ID,
+		UserID:       user.ID,
+		Email:        user.Email,
+		Role:         user.Role,
+		Scopes:       key.Scopes,
+		RestaurantID: key.RestaurantID,
+	}, nil
+}
+
+// --------------------------------------------------
+
+func (s *APIKeyService) usable(key *APIKey) bool {
+	if key.RevokedAt != nil {
+		return false
+	}
+	return key.ExpiresAt == nil || s.now().Before(*key.ExpiresAt)
+}
+
+// parseScopes validates the requested scopes and checks the user's role
+// holds each one; a key can never do more than its owner.
+func (s *APIKeyService) parseScopes(ctx context.Context, role string, requested []string) ([]Permission, error) {
+	seen := make(map[Permission]bool)
+	var scopes []Permission
+
+	for _, raw := range requested {
+		p, err := ParsePermission(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q", err, raw)
+		}
+		if seen[p] {
+			continue
+		}
+		if reservedAPIKeyScopes[p] {
+			return nil, fmt.Errorf("%w: %s", ErrAPIKeyScopeReserved, p)
+		}
+
+		ok, err := s.authorizer.HasPermission(ctx, role, p)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrAPIKeyScopeNotGranted, p)
+		}
+
+		seen[p] = true
+		scopes = append(scopes, p)
+	}
+
+	if len(scopes) == 0 {
+		return nil, ErrAPIKeyScopesRequired
+	}
+	return scopes, nil
+}
+
+// newAPIKey returns "bk_<prefix>_<secret>" and its prefix.
+func newAPIKey() (string, string, error) {
+	buf := make([]byte, 38)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+	prefix := hex.EncodeToString(buf[:6])
+	secret := hex.EncodeToString(buf[6:])
+	return apiKeyPrefix + prefix + "_" + secret, prefix, nil
+}
+
+func parseAPIKey(plain string) (string, bool) {
+	rest, ok := strings.CutPrefix(plain, apiKeyPrefix)
+	if !ok {
+		return "", false
+	}
+	prefix, secret, ok := strings.Cut(rest, "_")
+	if !ok || len(prefix) != 12 || secret == "" {
+		return "", false
+	}
+	return prefix, true
+}
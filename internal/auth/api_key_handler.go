@@ -0,0 +1,87 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type APIKeyHandler struct {
+	keys *APIKeyService
+}
+
+func NewAPIKeyHandler(keys *APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{keys: keys}
+}
+
+// --------------------------------------------------
+// POST /api-keys
+// --------------------------------------------------
+func (h *APIKeyHandler) Create(c *gin.Context) {
+	var req CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	plain, key, err := h.keys.Create(c.Request.Context(), c.GetString("userID"), req)
+	if err != nil {
+		writeAPIKeyError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		// Shown once; only the prefix and a hash are stored.
+		"key":     plain,
+		"api_key": key,
+	})
+}
+
+// --------------------------------------------------
+// GET /api-keys
+// --------------------------------------------------
+func (h *APIKeyHandler) List(c *gin.Context) {
+	keys, err := h.keys.List(c.Request.Context(), c.GetString("userID"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch api keys"})
+		return
+	}
+
+	if keys == nil {
+		keys = []APIKey{}
+	}
+	c.JSON(http.StatusOK, keys)
+}
+
+// --------------------------------------------------
+// DELETE /api-keys/:id
+// --------------------------------------------------
+func (h *APIKeyHandler) Revoke(c *gin.Context) {
+	if err := h.keys.Revoke(c.Request.Context(), c.GetString("userID"), c.Param("id")); err != nil {
+		writeAPIKeyError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "api key revoked"})
+}
+
+func writeAPIKeyError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, ErrAPIKeyNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrAPIKeyScopeNotGranted),
+		errors.Is(err, ErrAPIKeyScopeReserved),
+		errors.Is(err, ErrAPIKeyRestaurant):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrAPIKeyLimit):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrAPIKeyNameRequired),
+		errors.Is(err, ErrAPIKeyScopesRequired),
+		errors.Is(err, ErrAPIKeyExpiry),
+		errors.Is(err, ErrUnknownPermission):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to process request"})
+	}
+}
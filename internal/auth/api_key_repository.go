@@ -0,0 +1,36 @@
+package auth
+
+import (
+	"context"
+	"time"
+)
+
+type APIKey struct {
+	ID           string       `json:"id"`
+	UserID       string       `json:"user_id"`
+	RestaurantID *int         `json:"restaurant_id,omitempty"`
+	Name         string       `json:"name"`
+	Prefix       string       `json:"prefix"`
+	KeyHash      string       `json:"-"`
+	Scopes       []Permission `json:"scopes"`
+	ExpiresAt    *time.Time   `json:"expires_at"`
+	LastUsedAt   *time.Time   `json:"last_used_at"`
+	RevokedAt    *time.Time   `json:"revoked_at,omitempty"`
+	CreatedAt    time.Time    `json:"created_at"`
+}
+
+type APIKeyRepository interface {
+	Create(ctx context.Context, key *APIKey) error
+
+	// GetByPrefix returns nil when no key has the prefix.
+	GetByPrefix(ctx context.Context, prefix string) (*APIKey, error)
+
+	// ListByUser includes revoked and expired keys, newest first.
+	ListByUser(ctx context.Context, userID string) ([]APIKey, error)
+
+	// Revoke returns ErrAPIKeyNotFound unless the key belongs to userID and
+	// is still active.
+	Revoke(ctx context.Context, userID string, id string) error
+
+	TouchLastUsed(ctx context.Context, id string, at time.Time) error
+}
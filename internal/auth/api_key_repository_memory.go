@@ -0,0 +1,85 @@
+package auth
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type InMemoryAPIKeyRepository struct {
+	mu   sync.Mutex
+	keys map[string]*APIKey
+}
+
+func NewInMemoryAPIKeyRepository() *InMemoryAPIKeyRepository {
+	return &InMemoryAPIKeyRepository{
+		keys: make(map[string]*APIKey),
+	}
+}
+
+func (r *InMemoryAPIKeyRepository) Create(ctx context.Context, key *APIKey) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if key.ID == "" {
+		key.ID = uuid.New().String()
+	}
+	key.CreatedAt = time.Now()
+
+	stored := *key
+	r.keys[key.ID] = &stored
+	return nil
+}
+
+func (r *InMemoryAPIKeyRepository) GetByPrefix(ctx context.Context, prefix string) (*APIKey, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, k := range r.keys {
+		if k.Prefix == prefix {
+			copied := *k
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *InMemoryAPIKeyRepository) ListByUser(ctx context.Context, userID string) ([]APIKey, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var out []APIKey
+	for _, k := range r.keys {
+		if k.UserID == userID {
+			out = append(out, *k)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.After(out[j].CreatedAt) })
+	return out, nil
+}
+
+func (r *InMemoryAPIKeyRepository) Revoke(ctx context.Context, userID string, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	k, ok := r.keys[id]
+	if !ok || k.UserID != userID || k.RevokedAt != nil {
+		return ErrAPIKeyNotFound
+	}
+	now := time.Now()
+	k.RevokedAt = &now
+	return nil
+}
+
+func (r *InMemoryAPIKeyRepository) TouchLastUsed(ctx context.Context, id string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if k, ok := r.keys[id]; ok {
+		k.LastUsedAt = &at
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PostgresAPIKeyRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewPostgresAPIKeyRepository(db *pgxpool.Pool) *PostgresAPIKeyRepository {
+	return &PostgresAPIKeyRepository{db: db}
+}
+
+const apiKeyColumns = `
+	id, user_id, restaurant_id, name, prefix, key_hash, scopes,
+	expires_at, last_used_at, revoked_at, created_at
+`
+
+func scanAPIKey(row pgx.Row) (*APIKey, error) {
+	var k APIKey
+	var scopes []string
+
+	err := row.Scan(
+		&k.ID,
+		&k.UserID,
+		&k.RestaurantID,
+		&k.Name,
+		&k.Prefix,
+		&k.KeyHash,
+		&scopes,
+		&k.ExpiresAt,
+		&k.LastUsedAt,
+		&k.RevokedAt,
+		&k.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, s := range scopes {
+		k.Scopes = append(k.Scopes, Permission(s))
+	}
+	return &k, nil
+}
+
+func (r *PostgresAPIKeyRepository) Create(ctx context.Context, key *APIKey) error {
+	if key.ID == "" {
+		key.ID = uuid.New().String()
+	}
+
+	scopes := make([]string, len(key.Scopes))
+	for i, s := range key.Scopes {
+		scopes[i] = string(s)
+	}
+
+	return r.db.QueryRow(ctx, `
+		INSERT INTO api_keys (id, user_id, restaurant_id, name, prefix, key_hash, scopes, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING created_at
+	`,
+		key.ID,
+		key.UserID,
+		key.RestaurantID,
+		key.Name,
+		key.Prefix,
+		key.KeyHash,
+		scopes,
+		key.ExpiresAt,
+	).Scan(&key.CreatedAt)
+}
+
+func (r *PostgresAPIKeyRepository) GetByPrefix(ctx context.Context, prefix string) (*APIKey, error) {
+	k, err := scanAPIKey(r.db.QueryRow(ctx, `
+		SELECT `+apiKeyColumns+`
+		FROM api_keys
+		WHERE prefix = $1
+	`, prefix))
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return k, err
+}
+
+func (r *PostgresAPIKeyRepository) ListByUser(ctx context.Context, userID string) ([]APIKey, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT `+apiKeyColumns+`
+		FROM api_keys
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []APIKey
+	for rows.Next() {
+		k, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *k)
+	}
+
+	return out, rows.Err()
+}
+
+func (r *PostgresAPIKeyRepository) Revoke(ctx context.Context, userID string, id string) error {
+	tag, err := r.db.Exec(ctx, `
+		UPDATE api_keys
+		SET revoked_at = now()
+		WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL
+	`, id, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+func (r *PostgresAPIKeyRepository) TouchLastUsed(ctx context.Context, id string, at time.Time) error {
+	_, err := r.db.Exec(ctx, `
+		UPDATE api_keys SET last_used_at = $2 WHERE id = $1
+	`, id, at)
+	return err
+}
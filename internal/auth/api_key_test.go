@@ -0,0 +1,152 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"bhojanalya/internal/core"
+)
+
+type memberOf map[int]bool
+
+func (m memberOf) CanAccess(ctx context.Context, restaurantID int, userID string, action core.RestaurantAction) (bool, error) {
+	return m[restaurantID], nil
+}
+
+func newTestAPIKeyService(t *testing.T) (*APIKeyService, *InMemoryAPIKeyRepository, *User) {
+	t.Helper()
+
+	users := NewInMemoryUserRepository()
+	user := &User{Name: "Owner", Email: "owner@example.com", Role: string(RoleRestaurant)}
+	if err := users.Save(user); err != nil {
+		t.Fatalf("save user: %v", err)
+	}
+
+	repo := NewInMemoryAPIKeyRepository()
+	authorizer := NewAuthorizer(NewInMemoryPermissionRepository())
+	return NewAPIKeyService(repo, users, authorizer, memberOf{7: true}), repo, user
+}
+
+func TestAPIKeyCreateAndAuthenticate(t *testing.T) {
+	ctx := context.Background()
+	service, repo, user := newTestAPIKeyService(t)
+
+	restaurantID := 7
+	plain, key, err := service.Create(ctx, user.ID, CreateAPIKeyRequest{
+		Name:         "POS",
+		Scopes:       []string{"restaurants:manage"},
+		RestaurantID: &restaurantID,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(plain, "bk_"+key.Prefix+"_") {
+		t.Fatalf("expected key to start with its prefix, got %s", plain)
+	}
+	if key.KeyHash == "" || strings.Contains(key.KeyHash, plain) {
+		t.Fatalf("expected only a hash of the key to be stored")
+	}
+	if key.ExpiresAt == nil || key.ExpiresAt.Sub(time.Now()) < 89*24*time.Hour {
+		t.Fatalf("expected default 90 day expiry, got %v", key.ExpiresAt)
+	}
+
+	principal, err := service.Authenticate(ctx, plain)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if principal.UserID != user.ID || principal.Role != string(RoleRestaurant) || *principal.RestaurantID != 7 {
+		t.Fatalf("unexpected principal: %+v", principal)
+	}
+
+	stored, _ := repo.GetByPrefix(ctx, key.Prefix)
+	if stored.LastUsedAt == nil {
+		t.Fatalf("expected last_used_at to be recorded")
+	}
+
+	tampered := plain[:len(plain)-1] + "0"
+	if tampered == plain {
+		tampered = plain[:len(plain)-1] + "1"
+	}
+	for _, bad := range []string{"", "bk_nope", tampered, "Bearer " + plain} {
+		if _, err := service.Authenticate(ctx, bad); !errors.Is(err, ErrInvalidAPIKey) {
+			t.Errorf("%q: expected ErrInvalidAPIKey, got %v", bad, err)
+		}
+	}
+
+	if err := service.Revoke(ctx, "someone-else", key.ID); !errors.Is(err, ErrAPIKeyNotFound) {
+		t.Fatalf("expected other users not to revoke the key, got %v", err)
+	}
+	if err := service.Revoke(ctx, user.ID, key.ID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := service.Authenticate(ctx, plain); !errors.Is(err, ErrInvalidAPIKey) {
+		t.Fatalf("expected revoked key to be rejected, got %v", err)
+	}
+}
+
+func TestAPIKeyCreateValidation(t *testing.T) {
+	ctx := context.Background()
+	service, _, user := newTestAPIKeyService(t)
+	other := 8
+
+	cases := map[string]struct {
+		req  CreateAPIKeyRequest
+		want error
+	}{
+		"no name":       {CreateAPIKeyRequest{Scopes: []string{"restaurants:manage"}}, ErrAPIKeyNameRequired},
+		"no scopes":     {CreateAPIKeyRequest{Name: "k"}, ErrAPIKeyScopesRequired},
+		"unknown scope": {CreateAPIKeyRequest{Name: "k", Scopes: []string{"root"}}, ErrUnknownPermission},
+		"beyond role":   {CreateAPIKeyRequest{Name: "k", Scopes: []string{"menus:review"}}, ErrAPIKeyScopeNotGranted},
+		"long expiry":   {CreateAPIKeyRequest{Name: "k", Scopes: []string{"restaurants:manage"}, ExpiresInDays: 400}, ErrAPIKeyExpiry},
+		"not a member":  {CreateAPIKeyRequest{Name: "k", Scopes: []string{"restaurants:manage"}, RestaurantID: &other}, ErrAPIKeyRestaurant},
+	}
+
+	for name, tc := range cases {
+		if _, _, err := service.Create(ctx, user.ID, tc.req); !errors.Is(err, tc.want) {
+			t.Errorf("%s: expected %v, got %v", name, tc.want, err)
+		}
+	}
+}
+
+func TestAPIKeyRefusesAdminScopes(t *testing.T) {
+	ctx := context.Background()
+	service, _, _ := newTestAPIKeyService(t)
+
+	admin := &User{Name: "Admin", Email: "admin@example.com", Role: string(RoleAdmin)}
+	if err := service.users.Save(admin); err != nil {
+		t.Fatalf("save admin: %v", err)
+	}
+
+	for _, scope := range []string{"pipeline:manage", "restaurants:approve", "deals:approve", "competition:recompute", "lockouts:manage"} {
+		_, _, err := service.Create(ctx, admin.ID, CreateAPIKeyRequest{Name: "k", Scopes: []string{"restaurants:read", scope}})
+		if !errors.Is(err, ErrAPIKeyScopeReserved) {
+			t.Errorf("%s: expected ErrAPIKeyScopeReserved, got %v", scope, err)
+		}
+	}
+
+	if _, _, err := service.Create(ctx, admin.ID, CreateAPIKeyRequest{Name: "k", Scopes: []string{"restaurants:read"}}); err != nil {
+		t.Fatalf("read-only admin key: %v", err)
+	}
+}
+
+func TestAPIKeyExpiry(t *testing.T) {
+	ctx := context.Background()
+	service, _, user := newTestAPIKeyService(t)
+
+	plain, _, err := service.Create(ctx, user.ID, CreateAPIKeyRequest{
+		Name:          "dashboard",
+		Scopes:        []string{"restaurants:manage"},
+		ExpiresInDays: 1,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	service.now = func() time.Time { return time.Now().Add(25 * time.Hour) }
+	if _, err := service.Authenticate(ctx, plain); !errors.Is(err, ErrInvalidAPIKey) {
+		t.Fatalf("expected expired key to be rejected, got %v", err)
+	}
+}
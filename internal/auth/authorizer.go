@@ -0,0 +1,135 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+const permissionCacheTTL = 30 * time.Second
+
+var ErrUnknownRole = errors.New("unknown role")
+
+// Authorizer answers "does this platform role have this permission" from
+// the role_permissions table. Mappings are cached briefly so a grant or
+// revoke made with cmd/admin takes effect within permissionCacheTTL.
+type Authorizer struct {
+	repo PermissionRepository
+	ttl  time.Duration
+	now  func() time.Time
+
+	mu       sync.Mutex
+	roles    map[string]map[Permission]bool
+	loadedAt time.Time
+}
+
+func NewAuthorizer(repo PermissionRepository) *Authorizer {
+	return &Authorizer{
+		repo: repo,
+		ttl:  permissionCacheTTL,
+		now:  time.Now,
+	}
+}
+
+// HasPermission reports whether role grants perm. Unknown roles have no
+// permissions.
+func (a *Authorizer) HasPermission(ctx context.Context, role string, perm Permission) (bool, error) {
+	roles, err := a.load(ctx)
+	if err != nil {
+		return false, err
+	}
+	return roles[role][perm], nil
+}
+
+// RoleExists is used before assigning a role to a user.
+func (a *Authorizer) RoleExists(ctx context.Context, role string) (bool, error) {
+	roles, err := a.load(ctx)
+	if err != nil {
+		return false, err
+	}
+	_, ok := roles[role]
+	return ok, nil
+}
+
+// ListRoles returns every role with its permissions.
+func (a *Authorizer) ListRoles(ctx context.Context) (map[string][]Permission, error) {
+	return a.repo.ListRolePermissions(ctx)
+}
+
+func (a *Authorizer) CreateRole(ctx context.Context, role string) error {
+	role = NormalizeRole(role)
+	if role == "" {
+		return ErrUnknownRole
+	}
+	defer a.Invalidate()
+	return a.repo.CreateRole(ctx, role)
+}
+
+func (a *Authorizer) Grant(ctx context.Context, role string, perm string) error {
+	p, err := ParsePermission(perm)
+	if err != nil {
+		return err
+	}
+	role = NormalizeRole(role)
+	if role == "" {
+		return ErrUnknownRole
+	}
+	defer a.Invalidate()
+	return a.repo.Grant(ctx, role, p)
+}
+
+func (a *Authorizer) Revoke(ctx context.Context, role string, perm string) error {
+	p, err := ParsePermission(perm)
+	if err != nil {
+		return err
+	}
+	defer a.Invalidate()
+	return a.repo.Revoke(ctx, NormalizeRole(role), p)
+}
+
+// Invalidate drops the cache so the next check reloads from the repository.
+func (a *Authorizer) Invalidate() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.roles = nil
+}
+
+func (a *Authorizer) load(ctx context.Context) (map[string]map[Permission]bool, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.roles != nil && a.now().Sub(a.loadedAt) < a.ttl {
+		return a.roles, nil
+	}
+
+	list, err := a.repo.ListRolePermissions(ctx)
+	if err != nil {
+		// Keep serving the last known mappings rather than locking
+		// everyone out during a database blip.
+		if a.roles != nil {
+			log.Printf("[AUTHZ] reloading role permissions failed, using cached copy: %v", err)
+			return a.roles, nil
+		}
+		return nil, err
+	}
+
+	roles := make(map[string]map[Permission]bool, len(list))
+	for role, perms := range list {
+		roles[role] = make(map[Permission]bool, len(perms))
+		for _, p := range perms {
+			roles[role][p] = true
+		}
+	}
+
+	a.roles = roles
+	a.loadedAt = a.now()
+	return roles, nil
+}
+
+// NormalizeRole upper-cases a role name as stored in users.role.
+func NormalizeRole(role string) string {
+	return strings.ToUpper(strings.TrimSpace(role))
+}
@@ -0,0 +1,120 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestAuthorizerDefaultRoles(t *testing.T) {
+	ctx := context.Background()
+	a := NewAuthorizer(NewInMemoryPermissionRepository())
+
+	cases := []struct {
+		role    Role
+		perm    Permission
+		allowed bool
+	}{
+		{RoleAdmin, PermRestaurantsApprove, true},
+		{RoleAdmin, PermRestaurantsManage, false},
+		{RoleRestaurant, PermRestaurantsManage, true},
+		{RoleRestaurant, PermRestaurantsRead, false},
+		{RoleAnalyst, PermRestaurantsRead, true},
+		{RoleAnalyst, PermMenusReview, false},
+		{RoleAnalyst, PermCompetitionRecompute, false},
+		{RoleReviewer, PermMenusReview, true},
+		{RoleReviewer, PermRestaurantsApprove, false},
+		{RoleReviewer, PermDealsApprove, false},
+		{"admin", PermRestaurantsRead, false},
+		{"NOBODY", PermRestaurantsRead, false},
+	}
+
+	for _, tc := range cases {
+		ok, err := a.HasPermission(ctx, string(tc.role), tc.perm)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if ok != tc.allowed {
+			t.Errorf("%s %s: expected %v, got %v", tc.role, tc.perm, tc.allowed, ok)
+		}
+	}
+}
+
+func TestAuthorizerCustomRole(t *testing.T) {
+	ctx := context.Background()
+	a := NewAuthorizer(NewInMemoryPermissionRepository())
+
+	if ok, _ := a.RoleExists(ctx, "AUDITOR"); ok {
+		t.Fatalf("expected AUDITOR not to exist yet")
+	}
+
+	if err := a.Grant(ctx, "auditor", "deals:approve"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ok, _ := a.HasPermission(ctx, "AUDITOR", PermDealsApprove); !ok {
+		t.Fatalf("expected grant to apply immediately")
+	}
+	if ok, _ := a.RoleExists(ctx, "AUDITOR"); !ok {
+		t.Fatalf("expected grant to create the role")
+	}
+
+	if err := a.Revoke(ctx, "AUDITOR", "deals:approve"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ok, _ := a.HasPermission(ctx, "AUDITOR", PermDealsApprove); ok {
+		t.Fatalf("expected revoke to apply immediately")
+	}
+
+	if err := a.Grant(ctx, "AUDITOR", "everything:*"); !errors.Is(err, ErrUnknownPermission) {
+		t.Fatalf("expected ErrUnknownPermission, got %v", err)
+	}
+}
+
+type flakyPermissionRepository struct {
+	*InMemoryPermissionRepository
+	fail bool
+}
+
+func (r *flakyPermissionRepository) ListRolePermissions(ctx context.Context) (map[string][]Permission, error) {
+	if r.fail {
+		return nil, errors.New("db down")
+	}
+	return r.InMemoryPermissionRepository.ListRolePermissions(ctx)
+}
+
+func TestAuthorizerCacheReload(t *testing.T) {
+	ctx := context.Background()
+	repo := &flakyPermissionRepository{InMemoryPermissionRepository: NewInMemoryPermissionRepository()}
+	clock := time.Now()
+	a := NewAuthorizer(repo)
+	a.now = func() time.Time { return clock }
+
+	if ok, _ := a.HasPermission(ctx, "ANALYST", PermMenusReview); ok {
+		t.Fatalf("expected ANALYST without menus:review")
+	}
+
+	// Changes made elsewhere (e.g. cmd/admin) show up once the cache expires.
+	repo.Grant(ctx, "ANALYST", PermMenusReview)
+	if ok, _ := a.HasPermission(ctx, "ANALYST", PermMenusReview); ok {
+		t.Fatalf("expected cached mappings before the TTL")
+	}
+	clock = clock.Add(permissionCacheTTL)
+	if ok, _ := a.HasPermission(ctx, "ANALYST", PermMenusReview); !ok {
+		t.Fatalf("expected reload after the TTL")
+	}
+
+	// A failed reload keeps the last known mappings.
+	repo.fail = true
+	clock = clock.Add(permissionCacheTTL)
+	ok, err := a.HasPermission(ctx, "ANALYST", PermMenusReview)
+	if err != nil || !ok {
+		t.Fatalf("expected stale mappings, got %v, %v", ok, err)
+	}
+
+	// With nothing cached the error is returned.
+	a.Invalidate()
+	if _, err := a.HasPermission(ctx, "ANALYST", PermMenusReview); err == nil {
+		t.Fatalf("expected an error with an empty cache")
+	}
+}
@@ -1,17 +1,37 @@
 package auth
 
 import (
+	"errors"
+	"log"
+	"math"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
 
 type Handler struct {
-	service *Service
+	service  *Service
+	sessions *SessionService
+	accounts *AccountService
+	guard    *LoginGuard
+	mfa      *MFAService
 }
 
-func NewHandler(service *Service) *Handler {
-	return &Handler{service: service}
+func NewHandler(
+	service *Service,
+	sessions *SessionService,
+	accounts *AccountService,
+	guard *LoginGuard,
+	mfa *MFAService,
+) *Handler {
+	return &Handler{
+		service:  service,
+		sessions: sessions,
+		accounts: accounts,
+		guard:    guard,
+		mfa:      mfa,
+	}
 }
 
 type RegisterRequest struct {
@@ -25,6 +45,23 @@ type LoginRequest struct {
 	Password string `json:"password"`
 }
 
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token"`
+}
+
 // REGISTER HANDLER
 func (h *Handler) Register(c *gin.Context) {
 	var req RegisterRequest
@@ -43,6 +80,10 @@ func (h *Handler) Register(c *gin.Context) {
 		return
 	}
 
+	if err := h.accounts.SendVerificationEmail(c.Request.Context(), user); err != nil {
+		log.Printf("Failed to send verification email to %s: %v", user.Email, err)
+	}
+
 	c.JSON(http.StatusCreated, gin.H{
 		"name":  user.Name,
 		"email": user.Email,
@@ -57,26 +98,191 @@ func (h *Handler) Login(c *gin.Context) {
 		return
 	}
 
+	ctx := c.Request.Context()
+	ip := c.ClientIP()
+
+	if err := h.guard.Check(ctx, req.Email, ip); err != nil {
+		var locked *LockedError
+		if errors.As(err, &locked) {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(locked.RetryAfter.Seconds()))))
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": locked.Error()})
+			return
+		}
+		log.Printf("Login throttle check failed: %v", err)
+	}
+
 	user, err := h.service.Login(req.Email, req.Password)
 	if err != nil {
+		h.guard.RecordFailure(ctx, req.Email, ip)
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid credentials"})
 		return
 	}
 
-	token, err := GenerateToken(user.ID, user.Email, user.Role)
+	requirement, err := h.mfa.Requirement(ctx, user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check two-factor status"})
+		return
+	}
+
+	// The failure counter is only reset once every factor has passed,
+	// otherwise re-entering the password would reset code guessing.
+	if requirement != MFANone {
+		mfaToken, claims, err := GenerateMFAPendingToken(user)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate token"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"message":                 "two-factor verification required",
+			"mfa_required":            true,
+			"mfa_enrollment_required": requirement == MFAEnrollmentRequired,
+			"mfa_token":               mfaToken,
+			"expires_at":              claims.ExpiresAt,
+		})
+		return
+	}
+
+	h.guard.RecordSuccess(ctx, req.Email)
+	h.respondWithSession(c, user, nil)
+}
+
+// respondWithSession starts a session and writes the login response.
+// recoveryCodes is only set right after MFA enrollment.
+func (h *Handler) respondWithSession(c *gin.Context, user *User, recoveryCodes []string) {
+	tokens, err := h.sessions.StartSession(c.Request.Context(), user)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate token"})
 		return
 	}
 
+	resp := gin.H{
+		"message":            "login successful",
+		"name":               user.Name,
+		"email":              user.Email,
+		"token":              tokens.AccessToken,
+		"expires_at":         tokens.ExpiresAt,
+		"refresh_token":      tokens.RefreshToken,
+		"refresh_expires_at": tokens.RefreshExpiresAt,
+	}
+	if recoveryCodes != nil {
+		resp["recovery_codes"] = recoveryCodes
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// REFRESH HANDLER
+func (h *Handler) Refresh(c *gin.Context) {
+	var req RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.RefreshToken == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "refresh_token is required"})
+		return
+	}
+
+	tokens, err := h.sessions.Refresh(c.Request.Context(), req.RefreshToken)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+// LOGOUT HANDLER
+func (h *Handler) Logout(c *gin.Context) {
+	claims, ok := claimsFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if err := h.sessions.Logout(c.Request.Context(), claims); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to logout"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "logged out"})
+}
+
+// FORGOT PASSWORD HANDLER
+func (h *Handler) ForgotPassword(c *gin.Context) {
+	var req ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.Email == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "email is required"})
+		return
+	}
+
+	if err := h.accounts.RequestPasswordReset(c.Request.Context(), req.Email); err != nil {
+		log.Printf("Password reset request failed for %s: %v", req.Email, err)
+	}
+
+	// Same response whether or not the email exists
 	c.JSON(http.StatusOK, gin.H{
-		"message": "login successful",
-		"name":    user.Name,
-		"email":   user.Email,
-		"token":   token,
+		"message": "if that email is registered, a reset link has been sent",
 	})
 }
 
+// RESET PASSWORD HANDLER
+func (h *Handler) ResetPassword(c *gin.Context) {
+	var req ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.Token == "" || req.Password == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "token and password are required"})
+		return
+	}
+
+	if err := h.accounts.ResetPassword(c.Request.Context(), req.Token, req.Password); err != nil {
+		if errors.Is(err, ErrInvalidAccountToken) || errors.Is(err, ErrWeakPassword) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to reset password"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "password updated, please log in again"})
+}
+
+// VERIFY EMAIL HANDLER
+func (h *Handler) VerifyEmail(c *gin.Context) {
+	var req VerifyEmailRequest
+	_ = c.ShouldBindJSON(&req)
+	if req.Token == "" {
+		req.Token = c.Query("token")
+	}
+	if req.Token == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "token is required"})
+		return
+	}
+
+	if err := h.accounts.VerifyEmail(c.Request.Context(), req.Token); err != nil {
+		if errors.Is(err, ErrInvalidAccountToken) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to verify email"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "email verified"})
+}
+
+// RESEND VERIFICATION HANDLER
+func (h *Handler) ResendVerification(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if err := h.accounts.ResendVerificationEmail(c.Request.Context(), userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to send verification email"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "verification email sent"})
+}
+
 type UpdateStatusRequest struct {
 	Status string `json:"onboarding_status"`
 }
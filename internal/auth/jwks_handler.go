@@ -0,0 +1,24 @@
+package auth
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type JWKSHandler struct {
+	keys *KeySet
+}
+
+func NewJWKSHandler(keys *KeySet) *JWKSHandler {
+	return &JWKSHandler{keys: keys}
+}
+
+// --------------------------------------------------
+// GET /.well-known/jwks.json
+// --------------------------------------------------
+func (h *JWKSHandler) Get(c *gin.Context) {
+	// Short cache so verifiers pick up a rotated key quickly.
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.keys.JWKS())
+}
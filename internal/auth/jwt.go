@@ -6,8 +6,32 @@ import (
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
+const (
+	defaultAccessTokenTTL  = 15 * time.Minute
+	defaultRefreshTokenTTL = 30 * 24 * time.Hour
+
+	// mfaPendingTokenTTL bounds how long a user has to enter their code
+	// after the password step.
+	mfaPendingTokenTTL = 5 * time.Minute
+)
+
+// TokenClaims is the decoded content of an access token.
+type TokenClaims struct {
+	UserID    string
+	Email     string
+	Role      string
+	JTI       string
+	SessionID string
+	ExpiresAt time.Time
+
+	// MFAPending marks a token issued after the password step only. It is
+	// accepted by the MFA endpoints and nowhere else.
+	MFAPending bool
+}
+
 func getJWTSecret() ([]byte, error) {
 	secret := os.Getenv("JWT_SECRET")
 	if secret == "" {
@@ -16,51 +40,166 @@ func getJWTSecret() ([]byte, error) {
 	return []byte(secret), nil
 }
 
+// accessTokenTTL reads ACCESS_TOKEN_TTL (e.g. "15m"), falling back to the default.
+func accessTokenTTL() time.Duration {
+	return durationFromEnv("ACCESS_TOKEN_TTL", defaultAccessTokenTTL)
+}
+
+// refreshTokenTTL reads REFRESH_TOKEN_TTL (e.g. "720h"), falling back to the default.
+func refreshTokenTTL() time.Duration {
+	return durationFromEnv("REFRESH_TOKEN_TTL", defaultRefreshTokenTTL)
+}
+
+func durationFromEnv(key string, fallback time.Duration) time.Duration {
+	if v := os.Getenv(key); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			return d
+		}
+	}
+	return fallback
+}
+
+// GenerateToken issues a standalone access token that is not tied to a session.
 func GenerateToken(userID, email, role string) (string, error) {
-	if userID == "" {
-		return "", errors.New("empty userID passed to GenerateToken")
+	token, _, err := generateAccessToken(userID, email, role, "", accessTokenTTL())
+	return token, err
+}
+
+// GenerateMFAPendingToken issues the short-lived token returned by login when
+// the user still has to pass the second factor.
+func GenerateMFAPendingToken(user *User) (string, *TokenClaims, error) {
+	return signToken(&TokenClaims{
+		UserID:     user.ID,
+		Email:      user.Email,
+		Role:       user.Role,
+		MFAPending: true,
+	}, mfaPendingTokenTTL)
+}
+
+func generateAccessToken(
+	userID, email, role, sessionID string,
+	ttl time.Duration,
+) (string, *TokenClaims, error) {
+	return signToken(&TokenClaims{
+		UserID:    userID,
+		Email:     email,
+		Role:      role,
+		SessionID: sessionID,
+	}, ttl)
+}
+
+// accessClaims is the wire format of every token this package issues.
+type accessClaims struct {
+	jwt.RegisteredClaims
+	Email     string `json:"email"`
+	Role      string `json:"role"`
+	SessionID string `json:"sid,omitempty"`
+	MFA       string `json:"mfa,omitempty"`
+}
+
+// tokenIssuer reads JWT_ISSUER, the value of the iss claim.
+func tokenIssuer() string {
+	if v := os.Getenv("JWT_ISSUER"); v != "" {
+		return v
+	}
+	return defaultIssuer
+}
+
+// tokenAudience reads JWT_AUDIENCE, the value of the aud claim.
+func tokenAudience() string {
+	if v := os.Getenv("JWT_AUDIENCE"); v != "" {
+		return v
+	}
+	return defaultAudience
+}
+
+func signToken(tc *TokenClaims, ttl time.Duration) (string, *TokenClaims, error) {
+	if tc.UserID == "" {
+		return "", nil, errors.New("empty userID passed to GenerateToken")
 	}
 
-	secret, err := getJWTSecret()
+	keys, err := currentKeySet()
 	if err != nil {
-		return "", err
+		return "", nil, err
+	}
+
+	now := time.Now()
+	tc.JTI = uuid.New().String()
+	tc.ExpiresAt = now.Add(ttl)
+
+	claims := accessClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   tc.UserID,
+			Issuer:    tokenIssuer(),
+			Audience:  jwt.ClaimStrings{tokenAudience()},
+			ID:        tc.JTI,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(tc.ExpiresAt),
+		},
+		Email:     tc.Email,
+		Role:      tc.Role,
+		SessionID: tc.SessionID,
+	}
+	if tc.MFAPending {
+		claims.MFA = "pending"
 	}
 
-	claims := jwt.MapClaims{
-		"userID": userID,
-		"email":  email,
-		"role":   role,
-		"exp":    time.Now().Add(24 * time.Hour).Unix(),
+	signed, err := keys.sign(claims)
+	if err != nil {
+		return "", nil, err
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(secret)
+	return signed, tc, nil
 }
 
-func ValidateToken(tokenString string) (string, string, string, error) {
-	secret, err := getJWTSecret()
+// ParseToken verifies an access token and returns its claims.
+func ParseToken(tokenString string) (*TokenClaims, error) {
+	keys, err := currentKeySet()
 	if err != nil {
-		return "", "", "", err
+		return nil, err
 	}
 
-	token, err := jwt.Parse(tokenString, func(t *jwt.Token) (interface{}, error) {
-		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("unexpected signing method")
-		}
-		return secret, nil
-	})
+	var claims accessClaims
+	token, err := jwt.ParseWithClaims(
+		tokenString,
+		&claims,
+		keys.keyFunc,
+		jwt.WithIssuer(tokenIssuer()),
+		jwt.WithAudience(tokenAudience()),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	)
 	if err != nil || !token.Valid {
-		return "", "", "", errors.New("invalid token")
+		return nil, errors.New("invalid token")
+	}
+
+	if claims.Subject == "" {
+		return nil, errors.New("invalid token claims")
 	}
 
-	claims, ok := token.Claims.(jwt.MapClaims)
-	if !ok {
-		return "", "", "", errors.New("invalid token claims")
+	tc := &TokenClaims{
+		UserID:     claims.Subject,
+		Email:      claims.Email,
+		Role:       claims.Role,
+		JTI:        claims.ID,
+		SessionID:  claims.SessionID,
+		MFAPending: claims.MFA == "pending",
 	}
+	if claims.ExpiresAt != nil {
+		tc.ExpiresAt = claims.ExpiresAt.Time
+	}
+
+	return tc, nil
+}
 
-	userID, _ := claims["userID"].(string)
-	email, _ := claims["email"].(string)
-	role, _ := claims["role"].(string)
+func ValidateToken(tokenString string) (string, string, string, error) {
+	claims, err := ParseToken(tokenString)
+	if err != nil {
+		return "", "", "", err
+	}
+	if claims.MFAPending {
+		return "", "", "", errors.New("mfa verification required")
+	}
 
-	return userID, email, role, nil
+	return claims.UserID, claims.Email, claims.Role, nil
 }
@@ -0,0 +1,283 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync/atomic"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Keys are loaded from JWT_KEYS_DIR. Every *.pem file in it is a key whose
+// kid is the file name without extension:
+//
+//	2026-01.pem       private key (RSA PKCS#1/PKCS#8 or Ed25519 PKCS#8)
+//	2025-07.pub.pem   public key kept only to verify older tokens
+//
+// JWT_SIGNING_KEY_ID selects the private key used to sign. To rotate, add
+// the new private key, switch JWT_SIGNING_KEY_ID, and delete the old file
+// once every token it signed has expired. Other services fetch the public
+// keys from /.well-known/jwks.json.
+//
+// Without JWT_KEYS_DIR the legacy HS256 JWT_SECRET is used. HMAC keys are
+// never published in the JWKS.
+
+const (
+	hmacKeyID       = "hs256"
+	minRSAKeyBits   = 2048
+	defaultIssuer   = "bhojanalya"
+	defaultAudience = "bhojanalya-api"
+)
+
+type verificationKey struct {
+	id     string
+	method jwt.SigningMethod
+	public crypto.PublicKey // []byte for HMAC
+}
+
+// KeySet holds the key used to sign new tokens and every key accepted
+// when verifying.
+type KeySet struct {
+	signingID  string
+	signingKey interface{}
+	verify     map[string]verificationKey
+}
+
+var activeKeys atomic.Pointer[KeySet]
+
+// UseKeySet makes ks the key set used by token generation and parsing.
+func UseKeySet(ks *KeySet) {
+	activeKeys.Store(ks)
+}
+
+// currentKeySet falls back to JWT_SECRET when no key set was installed,
+// which keeps tests and tools that only set the secret working.
+func currentKeySet() (*KeySet, error) {
+	if ks := activeKeys.Load(); ks != nil {
+		return ks, nil
+	}
+
+	secret, err := getJWTSecret()
+	if err != nil {
+		return nil, err
+	}
+	return NewHMACKeySet(secret), nil
+}
+
+func NewHMACKeySet(secret []byte) *KeySet {
+	return &KeySet{
+		signingID:  hmacKeyID,
+		signingKey: secret,
+		verify: map[string]verificationKey{
+			hmacKeyID: {id: hmacKeyID, method: jwt.SigningMethodHS256, public: secret},
+		},
+	}
+}
+
+// KeySetFromEnv loads keys from JWT_KEYS_DIR, or falls back to JWT_SECRET.
+func KeySetFromEnv() (*KeySet, error) {
+	dir := os.Getenv("JWT_KEYS_DIR")
+	if dir == "" {
+		secret, err := getJWTSecret()
+		if err != nil {
+			return nil, errors.New("set JWT_KEYS_DIR or JWT_SECRET")
+		}
+		return NewHMACKeySet(secret), nil
+	}
+
+	return LoadKeySet(dir, os.Getenv("JWT_SIGNING_KEY_ID"))
+}
+
+// LoadKeySet reads every *.pem file in dir. When signingID is empty the
+// directory must contain exactly one private key.
+func LoadKeySet(dir string, signingID string) (*KeySet, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	ks := &KeySet{verify: make(map[string]verificationKey)}
+	private := map[string]interface{}{}
+
+	for _, path := range paths {
+		kid := strings.TrimSuffix(strings.TrimSuffix(filepath.Base(path), ".pem"), ".pub")
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		priv, pub, err := parsePEMKey(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+
+		method, err := signingMethodFor(pub)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+
+		if _, dup := ks.verify[kid]; dup {
+			return nil, fmt.Errorf("duplicate key id %q", kid)
+		}
+		ks.verify[kid] = verificationKey{id: kid, method: method, public: pub}
+		if priv != nil {
+			private[kid] = priv
+		}
+	}
+
+	if len(ks.verify) == 0 {
+		return nil, fmt.Errorf("no *.pem keys found in %s", dir)
+	}
+
+	if signingID == "" {
+		if len(private) != 1 {
+			return nil, fmt.Errorf("found %d private keys, set JWT_SIGNING_KEY_ID", len(private))
+		}
+		for kid := range private {
+			signingID = kid
+		}
+	}
+
+	signer, ok := private[signingID]
+	if !ok {
+		return nil, fmt.Errorf("no private key with id %q", signingID)
+	}
+	ks.signingID = signingID
+	ks.signingKey = signer
+
+	return ks, nil
+}
+
+func parsePEMKey(data []byte) (crypto.Signer, crypto.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, nil, errors.New("no PEM block")
+	}
+
+	switch block.Type {
+	case "PRIVATE KEY":
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, nil, err
+		}
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, nil, errors.New("unsupported private key type")
+		}
+		return signer, signer.Public(), nil
+
+	case "RSA PRIVATE KEY":
+		key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, nil, err
+		}
+		return key, key.Public(), nil
+
+	case "PUBLIC KEY":
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, nil, err
+		}
+		return nil, key, nil
+
+	default:
+		return nil, nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+}
+
+func signingMethodFor(pub crypto.PublicKey) (jwt.SigningMethod, error) {
+	switch k := pub.(type) {
+	case *rsa.PublicKey:
+		if k.N.BitLen() < minRSAKeyBits {
+			return nil, fmt.Errorf("RSA key must be at least %d bits", minRSAKeyBits)
+		}
+		return jwt.SigningMethodRS256, nil
+	case ed25519.PublicKey:
+		return jwt.SigningMethodEdDSA, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", pub)
+	}
+}
+
+// keyFunc resolves the verification key from the token's kid header and
+// refuses any algorithm other than the one bound to that key.
+func (ks *KeySet) keyFunc(t *jwt.Token) (interface{}, error) {
+	kid, _ := t.Header["kid"].(string)
+	key, ok := ks.verify[kid]
+	if !ok {
+		return nil, errors.New("unknown signing key")
+	}
+	if t.Method.Alg() != key.method.Alg() {
+		return nil, errors.New("unexpected signing method")
+	}
+	return key.public, nil
+}
+
+func (ks *KeySet) sign(claims jwt.Claims) (string, error) {
+	key := ks.verify[ks.signingID]
+
+	token := jwt.NewWithClaims(key.method, claims)
+	token.Header["kid"] = ks.signingID
+
+	return token.SignedString(ks.signingKey)
+}
+
+// --------------------------------------------------
+// JWKS
+// --------------------------------------------------
+
+// JWK is the public part of one key, RFC 7517.
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS lists the public verification keys, sorted by kid.
+func (ks *KeySet) JWKS() JWKS {
+	out := JWKS{Keys: []JWK{}}
+
+	for _, key := range ks.verify {
+		jwk := JWK{KeyID: key.id, Use: "sig", Algorithm: key.method.Alg()}
+
+		switch pub := key.public.(type) {
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		default:
+			continue
+		}
+
+		out.Keys = append(out.Keys, jwk)
+	}
+
+	sort.Slice(out.Keys, func(i, j int) bool { return out.Keys[i].KeyID < out.Keys[j].KeyID })
+	return out
+}
@@ -0,0 +1,184 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func writeKey(t *testing.T, dir, name string, key interface{}, publicOnly bool) {
+	t.Helper()
+
+	var block *pem.Block
+	if publicOnly {
+		der, err := x509.MarshalPKIXPublicKey(key)
+		if err != nil {
+			t.Fatalf("marshal public key: %v", err)
+		}
+		block = &pem.Block{Type: "PUBLIC KEY", Bytes: der}
+	} else {
+		der, err := x509.MarshalPKCS8PrivateKey(key)
+		if err != nil {
+			t.Fatalf("marshal private key: %v", err)
+		}
+		block = &pem.Block{Type: "PRIVATE KEY", Bytes: der}
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, name), pem.EncodeToMemory(block), 0o600); err != nil {
+		t.Fatalf("write key: %v", err)
+	}
+}
+
+func useTestKeySet(t *testing.T, ks *KeySet) {
+	t.Helper()
+	UseKeySet(ks)
+	t.Cleanup(func() { activeKeys.Store(nil) })
+}
+
+func TestKeyRotation(t *testing.T) {
+	dir := t.TempDir()
+
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	writeKey(t, dir, "2025-07.pem", rsaKey, false)
+	writeKey(t, dir, "2026-01.pem", edKey, false)
+
+	if _, err := LoadKeySet(dir, ""); err == nil {
+		t.Fatalf("expected an error when the signing key is ambiguous")
+	}
+
+	old, err := LoadKeySet(dir, "2025-07")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	useTestKeySet(t, old)
+
+	oldToken, err := GenerateToken("user-1", "user@example.com", "RESTAURANT")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	parsed, _, _ := jwt.NewParser().ParseUnverified(oldToken, &accessClaims{})
+	if parsed.Header["kid"] != "2025-07" || parsed.Method.Alg() != "RS256" {
+		t.Fatalf("unexpected header: %v", parsed.Header)
+	}
+
+	// Switch signing to the Ed25519 key and keep the RSA key for verification only.
+	os.Remove(filepath.Join(dir, "2025-07.pem"))
+	writeKey(t, dir, "2025-07.pub.pem", &rsaKey.PublicKey, true)
+
+	rotated, err := LoadKeySet(dir, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	useTestKeySet(t, rotated)
+
+	claims, err := ParseToken(oldToken)
+	if err != nil {
+		t.Fatalf("expected token signed by the retired key to verify, got %v", err)
+	}
+	if claims.UserID != "user-1" {
+		t.Fatalf("expected sub user-1, got %s", claims.UserID)
+	}
+
+	newToken, _ := GenerateToken("user-2", "other@example.com", "RESTAURANT")
+	parsed, _, _ = jwt.NewParser().ParseUnverified(newToken, &accessClaims{})
+	if parsed.Header["kid"] != "2026-01" || parsed.Method.Alg() != "EdDSA" {
+		t.Fatalf("unexpected header after rotation: %v", parsed.Header)
+	}
+	if _, err := ParseToken(newToken); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	jwks := rotated.JWKS()
+	if len(jwks.Keys) != 2 {
+		t.Fatalf("expected 2 published keys, got %d", len(jwks.Keys))
+	}
+	if jwks.Keys[0].KeyType != "RSA" || jwks.Keys[0].N == "" || jwks.Keys[0].E != "AQAB" {
+		t.Fatalf("unexpected RSA jwk: %+v", jwks.Keys[0])
+	}
+	if jwks.Keys[1].KeyType != "OKP" || jwks.Keys[1].Curve != "Ed25519" || jwks.Keys[1].X == "" {
+		t.Fatalf("unexpected Ed25519 jwk: %+v", jwks.Keys[1])
+	}
+}
+
+func TestParseTokenRejectsForeignTokens(t *testing.T) {
+	dir := t.TempDir()
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	writeKey(t, dir, "main.pem", rsaKey, false)
+
+	ks, err := LoadKeySet(dir, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	useTestKeySet(t, ks)
+
+	sign := func(method jwt.SigningMethod, kid string, key interface{}, claims accessClaims) string {
+		token := jwt.NewWithClaims(method, claims)
+		token.Header["kid"] = kid
+		s, err := token.SignedString(key)
+		if err != nil {
+			t.Fatalf("sign: %v", err)
+		}
+		return s
+	}
+
+	now := time.Now()
+	valid := accessClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   "user-1",
+			Issuer:    tokenIssuer(),
+			Audience:  jwt.ClaimStrings{tokenAudience()},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Minute)),
+		},
+	}
+	if _, err := ParseToken(sign(jwt.SigningMethodRS256, "main", rsaKey, valid)); err != nil {
+		t.Fatalf("expected valid token, got %v", err)
+	}
+
+	wrongAudience := valid
+	wrongAudience.Audience = jwt.ClaimStrings{"another-service"}
+
+	wrongIssuer := valid
+	wrongIssuer.Issuer = "someone-else"
+
+	noExpiry := valid
+	noExpiry.ExpiresAt = nil
+
+	pubDER, _ := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	otherKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+
+	cases := map[string]string{
+		"audience":      sign(jwt.SigningMethodRS256, "main", rsaKey, wrongAudience),
+		"issuer":        sign(jwt.SigningMethodRS256, "main", rsaKey, wrongIssuer),
+		"no expiry":     sign(jwt.SigningMethodRS256, "main", rsaKey, noExpiry),
+		"unknown kid":   sign(jwt.SigningMethodRS256, "other", otherKey, valid),
+		"wrong key":     sign(jwt.SigningMethodRS256, "main", otherKey, valid),
+		"alg confusion": sign(jwt.SigningMethodHS256, "main", pubDER, valid),
+	}
+
+	for name, token := range cases {
+		if _, err := ParseToken(token); err == nil {
+			t.Errorf("%s: expected token to be rejected", name)
+		}
+	}
+}
+
+func TestLoadKeySetRejectsWeakRSA(t *testing.T) {
+	dir := t.TempDir()
+	weak, _ := rsa.GenerateKey(rand.Reader, 1024)
+	writeKey(t, dir, "weak.pem", weak, false)
+
+	if _, err := LoadKeySet(dir, ""); err == nil {
+		t.Fatalf("expected 1024-bit RSA key to be rejected")
+	}
+}
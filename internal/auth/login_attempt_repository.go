@@ -0,0 +1,29 @@
+package auth
+
+import (
+	"context"
+	"time"
+)
+
+// LoginAttempt is the failure counter for one account or IP.
+type LoginAttempt struct {
+	Key           string     `json:"key"`
+	Failures      int        `json:"failures"`
+	LastFailureAt time.Time  `json:"last_failure_at"`
+	LockedUntil   *time.Time `json:"locked_until"`
+}
+
+type LoginAttemptRepository interface {
+	// Get returns nil when the key has no recorded failures.
+	Get(ctx context.Context, key string) (*LoginAttempt, error)
+
+	// RecordFailure increments the counter, restarting it from 1 when the
+	// previous failure is older than window, and returns the new count.
+	RecordFailure(ctx context.Context, key string, window time.Duration) (int, error)
+
+	Lock(ctx context.Context, key string, until time.Time) error
+	Reset(ctx context.Context, key string) error
+
+	// ListLocked returns keys whose lockout has not yet expired.
+	ListLocked(ctx context.Context) ([]LoginAttempt, error)
+}
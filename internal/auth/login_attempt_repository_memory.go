@@ -0,0 +1,86 @@
+package auth
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+type InMemoryLoginAttemptRepository struct {
+	mu       sync.Mutex
+	attempts map[string]*LoginAttempt
+	now      func() time.Time
+}
+
+func NewInMemoryLoginAttemptRepository() *InMemoryLoginAttemptRepository {
+	return &InMemoryLoginAttemptRepository{
+		attempts: make(map[string]*LoginAttempt),
+		now:      time.Now,
+	}
+}
+
+func (r *InMemoryLoginAttemptRepository) Get(ctx context.Context, key string) (*LoginAttempt, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	a, ok := r.attempts[key]
+	if !ok {
+		return nil, nil
+	}
+	copied := *a
+	return &copied, nil
+}
+
+func (r *InMemoryLoginAttemptRepository) RecordFailure(ctx context.Context, key string, window time.Duration) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	a, ok := r.attempts[key]
+	if !ok {
+		a = &LoginAttempt{Key: key}
+		r.attempts[key] = a
+	}
+
+	if a.Failures > 0 && now.Sub(a.LastFailureAt) > window {
+		a.Failures = 0
+	}
+	a.Failures++
+	a.LastFailureAt = now
+
+	return a.Failures, nil
+}
+
+func (r *InMemoryLoginAttemptRepository) Lock(ctx context.Context, key string, until time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if a, ok := r.attempts[key]; ok {
+		a.LockedUntil = &until
+	}
+	return nil
+}
+
+func (r *InMemoryLoginAttemptRepository) Reset(ctx context.Context, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.attempts, key)
+	return nil
+}
+
+func (r *InMemoryLoginAttemptRepository) ListLocked(ctx context.Context) ([]LoginAttempt, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	var locked []LoginAttempt
+	for _, a := range r.attempts {
+		if a.LockedUntil != nil && a.LockedUntil.After(now) {
+			locked = append(locked, *a)
+		}
+	}
+	sort.Slice(locked, func(i, j int) bool { return locked[i].Key < locked[j].Key })
+	return locked, nil
+}
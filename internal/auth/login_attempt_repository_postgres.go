@@ -0,0 +1,157 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PostgresLoginAttemptRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewPostgresLoginAttemptRepository(db *pgxpool.Pool) *PostgresLoginAttemptRepository {
+	return &PostgresLoginAttemptRepository{db: db}
+}
+
+func (r *PostgresLoginAttemptRepository) Get(ctx context.Context, key string) (*LoginAttempt, error) {
+	var a LoginAttempt
+
+	err := r.db.QueryRow(ctx, `
+		SELECT key, failures, last_failure_at, locked_until
+		FROM login_attempts
+		WHERE key = $1
+	`, key).Scan(&a.Key, &a.Failures, &a.LastFailureAt, &a.LockedUntil)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &a, nil
+}
+
+func (r *PostgresLoginAttemptRepository) RecordFailure(ctx context.Context, key string, window time.Duration) (int, error) {
+	var failures int
+
+	err := r.db.QueryRow(ctx, `
+		INSERT INTO login_attempts (key, failures, last_failure_at)
+		VALUES ($1, 1, now())
+		ON CONFLICT (key) DO UPDATE SET
+			failures = CASE
+				WHEN login_attempts.last_failure_at < now() - make_interval(secs => $2)
+				THEN 1
+				ELSE login_attempts.failures + 1
+			END,
+			last_failure_at = now()
+		RETURNING failures
+	`, key, window.Seconds()).Scan(&failures)
+
+	return failures, err
+}
+
+func (r *PostgresLoginAttemptRepository) Lock(ctx context.Context, key string, until time.Time) error {
+	_, err := r.db.Exec(ctx, `
+		UPDATE login_attempts
+		SET locked_until = $2
+		WHERE key = $1
+	`, key, until)
+	return err
+}
+
+func (r *PostgresLoginAttemptRepository) Reset(ctx context.Context, key string) error {
+	_, err := r.db.Exec(ctx, `
+		DELETE FROM login_attempts
+		WHERE key = $1
+	`, key)
+	return err
+}
+
+func (r *PostgresLoginAttemptRepository) ListLocked(ctx context.Context) ([]LoginAttempt, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT key, failures, last_failure_at, locked_until
+		FROM login_attempts
+		WHERE locked_until > now()
+		ORDER BY locked_until DESC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var locked []LoginAttempt
+	for rows.Next() {
+		var a LoginAttempt
+		if err := rows.Scan(&a.Key, &a.Failures, &a.LastFailureAt, &a.LockedUntil); err != nil {
+			return nil, err
+		}
+		locked = append(locked, a)
+	}
+
+	return locked, rows.Err()
+}
+
+// --------------------------------------------------
+// Fallback (Postgres → in-memory)
+// --------------------------------------------------
+
+// FallbackLoginAttemptRepository keeps throttling working when the primary
+// store is unavailable by switching to the fallback for that call.
+type FallbackLoginAttemptRepository struct {
+	primary  LoginAttemptRepository
+	fallback LoginAttemptRepository
+}
+
+func NewFallbackLoginAttemptRepository(primary, fallback LoginAttemptRepository) *FallbackLoginAttemptRepository {
+	return &FallbackLoginAttemptRepository{primary: primary, fallback: fallback}
+}
+
+func (r *FallbackLoginAttemptRepository) Get(ctx context.Context, key string) (*LoginAttempt, error) {
+	a, err := r.primary.Get(ctx, key)
+	if err != nil {
+		log.Printf("[LOGIN THROTTLE] primary store failed, using fallback: %v", err)
+		return r.fallback.Get(ctx, key)
+	}
+	if a == nil {
+		// Failures recorded while the primary was down still count.
+		return r.fallback.Get(ctx, key)
+	}
+	return a, nil
+}
+
+func (r *FallbackLoginAttemptRepository) RecordFailure(ctx context.Context, key string, window time.Duration) (int, error) {
+	n, err := r.primary.RecordFailure(ctx, key, window)
+	if err != nil {
+		log.Printf("[LOGIN THROTTLE] primary store failed, using fallback: %v", err)
+		return r.fallback.RecordFailure(ctx, key, window)
+	}
+	return n, nil
+}
+
+func (r *FallbackLoginAttemptRepository) Lock(ctx context.Context, key string, until time.Time) error {
+	// The failure may have been counted in either store; the fallback
+	// ignores keys it has never seen.
+	_ = r.fallback.Lock(ctx, key, until)
+	return r.primary.Lock(ctx, key, until)
+}
+
+func (r *FallbackLoginAttemptRepository) Reset(ctx context.Context, key string) error {
+	_ = r.fallback.Reset(ctx, key)
+	return r.primary.Reset(ctx, key)
+}
+
+func (r *FallbackLoginAttemptRepository) ListLocked(ctx context.Context) ([]LoginAttempt, error) {
+	locked, err := r.primary.ListLocked(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	fallbackLocked, _ := r.fallback.ListLocked(ctx)
+	return append(locked, fallbackLocked...), nil
+}
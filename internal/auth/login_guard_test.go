@@ -0,0 +1,207 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"bhojanalya/internal/mail"
+
+	"github.com/gin-gonic/gin"
+)
+
+type fakeClock struct {
+	t time.Time
+}
+
+func (c *fakeClock) now() time.Time { return c.t }
+
+func newTestLoginGuard() (*LoginGuard, *fakeClock) {
+	clock := &fakeClock{t: time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)}
+
+	repo := NewInMemoryLoginAttemptRepository()
+	repo.now = clock.now
+
+	guard := NewLoginGuard(repo)
+	guard.now = clock.now
+
+	return guard, clock
+}
+
+func TestLockoutPolicyBackoff(t *testing.T) {
+	p := LockoutPolicy{Threshold: 3, BaseLockout: 10 * time.Second, MaxLockout: time.Minute}
+
+	cases := map[int]time.Duration{
+		1: 0,
+		2: 0,
+		3: 10 * time.Second,
+		4: 20 * time.Second,
+		5: 40 * time.Second,
+		6: time.Minute,
+		9: time.Minute,
+	}
+
+	for failures, want := range cases {
+		if got := p.lockoutFor(failures); got != want {
+			t.Errorf("failures=%d: expected %s, got %s", failures, want, got)
+		}
+	}
+}
+
+func TestLoginGuardLocksAccount(t *testing.T) {
+	guard, clock := newTestLoginGuard()
+	ctx := context.Background()
+
+	for i := 0; i < DefaultAccountLockoutPolicy.Threshold; i++ {
+		if err := guard.Check(ctx, "victim@example.com", "10.0.0.1"); err != nil {
+			t.Fatalf("attempt %d: unexpected lock: %v", i+1, err)
+		}
+		guard.RecordFailure(ctx, "victim@example.com", "10.0.0.1")
+	}
+
+	// Email matching is case-insensitive and the lock holds from any IP.
+	var locked *LockedError
+	err := guard.Check(ctx, "Victim@Example.com", "10.0.0.2")
+	if !errors.As(err, &locked) {
+		t.Fatalf("expected account to be locked, got %v", err)
+	}
+	if locked.RetryAfter != DefaultAccountLockoutPolicy.BaseLockout {
+		t.Fatalf("expected retry after %s, got %s", DefaultAccountLockoutPolicy.BaseLockout, locked.RetryAfter)
+	}
+
+	clock.t = clock.t.Add(DefaultAccountLockoutPolicy.BaseLockout + time.Second)
+	if err := guard.Check(ctx, "victim@example.com", "10.0.0.2"); err != nil {
+		t.Fatalf("expected lock to expire, got %v", err)
+	}
+
+	// The next failure doubles the lockout.
+	guard.RecordFailure(ctx, "victim@example.com", "10.0.0.2")
+	if err := guard.Check(ctx, "victim@example.com", "10.0.0.2"); !errors.As(err, &locked) ||
+		locked.RetryAfter != 2*DefaultAccountLockoutPolicy.BaseLockout {
+		t.Fatalf("expected doubled lockout, got %v", err)
+	}
+}
+
+func TestLoginGuardLocksIP(t *testing.T) {
+	guard, _ := newTestLoginGuard()
+	ctx := context.Background()
+
+	// Spraying many accounts from one IP trips the IP limit.
+	for i := 0; i < DefaultIPLockoutPolicy.Threshold; i++ {
+		guard.RecordFailure(ctx, "user"+string(rune('a'+i))+"@example.com", "10.0.0.9")
+	}
+
+	if err := guard.Check(ctx, "fresh@example.com", "10.0.0.9"); err == nil {
+		t.Fatalf("expected IP to be locked")
+	}
+	if err := guard.Check(ctx, "fresh@example.com", "10.0.0.10"); err != nil {
+		t.Fatalf("expected other IPs to be unaffected, got %v", err)
+	}
+}
+
+func TestLoginGuardSuccessAndClear(t *testing.T) {
+	guard, _ := newTestLoginGuard()
+	ctx := context.Background()
+
+	for i := 0; i < DefaultAccountLockoutPolicy.Threshold-1; i++ {
+		guard.RecordFailure(ctx, "user@example.com", "10.0.0.1")
+	}
+	guard.RecordSuccess(ctx, "user@example.com")
+	guard.RecordFailure(ctx, "user@example.com", "10.0.0.1")
+
+	if err := guard.Check(ctx, "user@example.com", "10.0.0.1"); err != nil {
+		t.Fatalf("expected success to reset the account counter, got %v", err)
+	}
+
+	for i := 0; i < DefaultAccountLockoutPolicy.Threshold; i++ {
+		guard.RecordFailure(ctx, "user@example.com", "10.0.0.1")
+	}
+
+	lockouts, _ := guard.ListLockouts(ctx)
+	if len(lockouts) != 1 || lockouts[0].Key != "account:user@example.com" {
+		t.Fatalf("unexpected lockouts: %+v", lockouts)
+	}
+
+	if err := guard.ClearLockout(ctx, "account", "user@example.com"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := guard.Check(ctx, "user@example.com", "10.0.0.1"); err != nil {
+		t.Fatalf("expected lockout to be cleared, got %v", err)
+	}
+}
+
+type failingLoginAttemptRepository struct {
+	*InMemoryLoginAttemptRepository
+}
+
+func (*failingLoginAttemptRepository) Get(ctx context.Context, key string) (*LoginAttempt, error) {
+	return nil, errors.New("db down")
+}
+
+func (*failingLoginAttemptRepository) RecordFailure(ctx context.Context, key string, window time.Duration) (int, error) {
+	return 0, errors.New("db down")
+}
+
+func TestFallbackLoginAttemptRepository(t *testing.T) {
+	repo := NewFallbackLoginAttemptRepository(
+		&failingLoginAttemptRepository{NewInMemoryLoginAttemptRepository()},
+		NewInMemoryLoginAttemptRepository(),
+	)
+	guard := NewLoginGuard(repo)
+	ctx := context.Background()
+
+	for i := 0; i < DefaultAccountLockoutPolicy.Threshold; i++ {
+		guard.RecordFailure(ctx, "user@example.com", "10.0.0.1")
+	}
+
+	if err := guard.Check(ctx, "user@example.com", "10.0.0.1"); err == nil {
+		t.Fatalf("expected lockout to be tracked in the fallback store")
+	}
+}
+
+func TestLoginIgnoresForwardedForFromUntrustedClients(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	t.Setenv("TRUSTED_PROXIES", "")
+
+	repo := NewInMemoryUserRepository()
+	sessionRepo := NewInMemorySessionRepository()
+	handler := NewHandler(
+		NewService(repo),
+		NewSessionService(sessionRepo, repo),
+		NewAccountService(repo, NewInMemoryAccountTokenRepository(), sessionRepo, mail.NewLogMailer("")),
+		NewLoginGuard(NewInMemoryLoginAttemptRepository()),
+		NewMFAService(NewInMemoryMFARepository(), testSecretBox(), MFAPolicy{}),
+	)
+
+	r := gin.New()
+	if err := r.SetTrustedProxies(TrustedProxiesFromEnv()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	r.POST("/auth/login", handler.Login)
+
+	// A new email and a new spoofed IP on every attempt: only the
+	// connection's address ties them together.
+	login := func(i int) int {
+		body := fmt.Sprintf(`{"email":"user%d@example.com","password":"wrong"}`, i)
+		req := httptest.NewRequest(http.MethodPost, "/auth/login", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Forwarded-For", fmt.Sprintf("203.0.113.%d", i))
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	for i := 0; i < DefaultIPLockoutPolicy.Threshold; i++ {
+		if code := login(i); code != http.StatusUnauthorized {
+			t.Fatalf("attempt %d: expected 401, got %d", i+1, code)
+		}
+	}
+	if code := login(DefaultIPLockoutPolicy.Threshold); code != http.StatusTooManyRequests {
+		t.Fatalf("expected the connection's IP to be locked, got %d", code)
+	}
+}
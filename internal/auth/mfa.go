@@ -0,0 +1,311 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"os"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidMFACode     = errors.New("invalid verification code")
+	ErrMFANotEnabled      = errors.New("two-factor authentication is not enabled")
+	ErrMFAAlreadyEnabled  = errors.New("two-factor authentication is already enabled")
+	ErrMFANotEnrolled     = errors.New("start two-factor enrollment first")
+	ErrMFARequiredForRole = errors.New("two-factor authentication is mandatory for this role")
+)
+
+const recoveryCodeCount = 10
+
+var recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// MFARequirement tells the login handler what the user has to do after a
+// correct password.
+type MFARequirement int
+
+const (
+	MFANone MFARequirement = iota
+	MFAChallenge
+	MFAEnrollmentRequired
+)
+
+// MFAPolicy decides which roles cannot log in without a second factor.
+type MFAPolicy struct {
+	RequireForAdmin bool
+}
+
+// MFAPolicyFromEnv reads MFA_REQUIRED_FOR_ADMIN, which defaults to true.
+func MFAPolicyFromEnv() MFAPolicy {
+	return MFAPolicy{
+		RequireForAdmin: os.Getenv("MFA_REQUIRED_FOR_ADMIN") != "false",
+	}
+}
+
+func (p MFAPolicy) requires(role string) bool {
+	return p.RequireForAdmin && role == string(RoleAdmin)
+}
+
+// MFAEnrollment is returned once when enrolment starts.
+type MFAEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"otpauth_uri"`
+}
+
+// MFAService handles TOTP enrolment, verification and recovery codes.
+type MFAService struct {
+	repo   MFARepository
+	box    *SecretBox
+	policy MFAPolicy
+	issuer string
+	now    func() time.Time
+}
+
+// NewMFAService stores secrets encrypted with box; see SecretBoxFromEnv.
+func NewMFAService(repo MFARepository, box *SecretBox, policy MFAPolicy) *MFAService {
+	issuer := os.Getenv("MFA_ISSUER")
+	if issuer == "" {
+		issuer = "Bhojanalya"
+	}
+
+	return &MFAService{
+		repo:   repo,
+		box:    box,
+		policy: policy,
+		issuer: issuer,
+		now:    time.Now,
+	}
+}
+
+// Requirement is checked by login once the password has been accepted.
+func (s *MFAService) Requirement(ctx context.Context, user *User) (MFARequirement, error) {
+	cfg, err := s.repo.Get(ctx, user.ID)
+	if err != nil {
+		return MFANone, err
+	}
+
+	switch {
+	case cfg.Enabled():
+		return MFAChallenge, nil
+	case s.policy.requires(user.Role):
+		return MFAEnrollmentRequired, nil
+	default:
+		return MFANone, nil
+	}
+}
+
+// --------------------------------------------------
+// Enrollment
+// --------------------------------------------------
+
+// BeginEnrollment generates a fresh secret. It replaces any enrolment the
+// user started but never confirmed.
+func (s *MFAService) BeginEnrollment(ctx context.Context, user *User) (*MFAEnrollment, error) {
+	cfg, err := s.repo.Get(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Enabled() {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	secret, err := newTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := s.box.Seal(user.ID, secret)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.SaveSecret(ctx, user.ID, sealed); err != nil {
+		return nil, err
+	}
+
+	return &MFAEnrollment{
+		Secret: secret,
+		URI:    totpURI(s.issuer, user.Email, secret),
+	}, nil
+}
+
+// ConfirmEnrollment enables MFA once the user enters a valid code and
+// returns the plain recovery codes. They are never shown again.
+func (s *MFAService) ConfirmEnrollment(ctx context.Context, userID string, code string) ([]string, error) {
+	cfg, err := s.repo.Get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if cfg == nil {
+		return nil, ErrMFANotEnrolled
+	}
+	if cfg.Enabled() {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	if err := s.unseal(ctx, cfg); err != nil {
+		return nil, err
+	}
+	if err := s.checkCode(ctx, cfg, code); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.Enable(ctx, userID, hashes); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// --------------------------------------------------
+// Verification
+// --------------------------------------------------
+
+// Verify accepts either a TOTP code or a recovery code.
+func (s *MFAService) Verify(ctx context.Context, userID string, code string, recoveryCode string) error {
+	cfg, err := s.repo.Get(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !cfg.Enabled() {
+		return ErrMFANotEnabled
+	}
+
+	if recoveryCode != "" {
+		ok, err := s.repo.ConsumeRecoveryCode(ctx, userID, hashRecoveryCode(recoveryCode))
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrInvalidMFACode
+		}
+		return nil
+	}
+
+	if err := s.unseal(ctx, cfg); err != nil {
+		return err
+	}
+	return s.checkCode(ctx, cfg, code)
+}
+
+// RegenerateRecoveryCodes invalidates all previous codes.
+func (s *MFAService) RegenerateRecoveryCodes(ctx context.Context, userID string, code string) ([]string, error) {
+	if err := s.Verify(ctx, userID, code, ""); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// Disable turns MFA off after a final code check. Roles covered by the
+// policy cannot opt out.
+func (s *MFAService) Disable(ctx context.Context, user *User, code string) error {
+	if s.policy.requires(user.Role) {
+		return ErrMFARequiredForRole
+	}
+	if err := s.Verify(ctx, user.ID, code, ""); err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, user.ID)
+}
+
+// SealLegacySecret encrypts a secret stored in plain text before secrets
+// were encrypted. It reports whether there was one. Used by the admin
+// CLI; other legacy secrets are sealed the next time they are used.
+func (s *MFAService) SealLegacySecret(ctx context.Context, userID string) (bool, error) {
+	cfg, err := s.repo.Get(ctx, userID)
+	if err != nil || cfg == nil {
+		return false, err
+	}
+	_, legacy, err := s.box.Open(userID, cfg.Secret)
+	if err != nil || !legacy {
+		return false, err
+	}
+	return true, s.unseal(ctx, cfg)
+}
+
+// Reset removes MFA without a code. Used by the admin CLI for lost devices.
+func (s *MFAService) Reset(ctx context.Context, userID string) error {
+	return s.repo.Delete(ctx, userID)
+}
+
+// --------------------------------------------------
+// Helpers
+// --------------------------------------------------
+
+// unseal decrypts cfg.Secret in place, sealing and saving it first if it
+// is still stored in plain text.
+func (s *MFAService) unseal(ctx context.Context, cfg *MFAConfig) error {
+	secret, legacy, err := s.box.Open(cfg.UserID, cfg.Secret)
+	if err != nil {
+		return err
+	}
+	if legacy {
+		sealed, err := s.box.Seal(cfg.UserID, secret)
+		if err != nil {
+			return err
+		}
+		if err := s.repo.UpdateSecret(ctx, cfg.UserID, sealed); err != nil {
+			return err
+		}
+	}
+	cfg.Secret = secret
+	return nil
+}
+
+func (s *MFAService) checkCode(ctx context.Context, cfg *MFAConfig, code string) error {
+	step, ok := matchTOTP(cfg.Secret, code, s.now())
+	if !ok {
+		return ErrInvalidMFACode
+	}
+
+	fresh, err := s.repo.MarkStepUsed(ctx, cfg.UserID, step)
+	if err != nil {
+		return err
+	}
+	if !fresh {
+		return ErrInvalidMFACode
+	}
+
+	return nil
+}
+
+// newRecoveryCodes returns the plain codes for the user and their hashes
+// for storage. Codes look like "abcd-efgh".
+func newRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+
+	for i := range codes {
+		buf := make([]byte, 5)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, nil, err
+		}
+		raw := strings.ToLower(recoveryCodeEncoding.EncodeToString(buf))
+		codes[i] = raw[:4] + "-" + raw[4:]
+		hashes[i] = hashRecoveryCode(codes[i])
+	}
+
+	return codes, hashes, nil
+}
+
+// hashRecoveryCode ignores case, spaces and dashes so codes can be typed
+// loosely.
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(code)
+	normalized = strings.ReplaceAll(normalized, "-", "")
+	normalized = strings.ReplaceAll(normalized, " ", "")
+	return hashToken(normalized)
+}
@@ -0,0 +1,225 @@
+package auth
+
+import (
+	"errors"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type MFACodeRequest struct {
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recovery_code"`
+}
+
+func claimsFromContext(c *gin.Context) (*TokenClaims, bool) {
+	claimsVal, exists := c.Get("tokenClaims")
+	if !exists {
+		return nil, false
+	}
+	claims, ok := claimsVal.(*TokenClaims)
+	return claims, ok
+}
+
+// --------------------------------------------------
+// POST /auth/mfa/enroll
+// POST /auth/mfa/login/enroll  (mfa pending token)
+// --------------------------------------------------
+func (h *Handler) MFAEnroll(c *gin.Context) {
+	claims, ok := claimsFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	user, err := h.service.FindUser(c.Request.Context(), claims.UserID)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	enrollment, err := h.mfa.BeginEnrollment(c.Request.Context(), user)
+	if err != nil {
+		if errors.Is(err, ErrMFAAlreadyEnabled) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to start enrollment"})
+		return
+	}
+
+	c.JSON(http.StatusOK, enrollment)
+}
+
+// --------------------------------------------------
+// POST /auth/mfa/enroll/confirm
+// --------------------------------------------------
+func (h *Handler) MFAConfirmEnrollment(c *gin.Context) {
+	claims, ok := claimsFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.Code == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "code is required"})
+		return
+	}
+
+	codes, err := h.mfa.ConfirmEnrollment(c.Request.Context(), claims.UserID, req.Code)
+	if err != nil {
+		writeMFAError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":        "two-factor authentication enabled",
+		"recovery_codes": codes,
+	})
+}
+
+// --------------------------------------------------
+// POST /auth/mfa/login/verify  (mfa pending token)
+// --------------------------------------------------
+
+// MFALoginVerify completes a login started with a password. If the user is
+// still enrolling, the code also confirms the enrollment.
+func (h *Handler) MFALoginVerify(c *gin.Context) {
+	claims, ok := claimsFromContext(c)
+	if !ok || !claims.MFAPending {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil || (req.Code == "" && req.RecoveryCode == "") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "code or recovery_code is required"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	ip := c.ClientIP()
+
+	if err := h.guard.Check(ctx, claims.Email, ip); err != nil {
+		var locked *LockedError
+		if errors.As(err, &locked) {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(locked.RetryAfter.Seconds()))))
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": locked.Error()})
+			return
+		}
+		log.Printf("Login throttle check failed: %v", err)
+	}
+
+	user, err := h.service.FindUser(ctx, claims.UserID)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	requirement, err := h.mfa.Requirement(ctx, user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check two-factor status"})
+		return
+	}
+
+	var recoveryCodes []string
+	switch requirement {
+	case MFAChallenge:
+		err = h.mfa.Verify(ctx, user.ID, req.Code, req.RecoveryCode)
+	case MFAEnrollmentRequired:
+		recoveryCodes, err = h.mfa.ConfirmEnrollment(ctx, user.ID, req.Code)
+	default:
+		err = ErrMFANotEnabled
+	}
+
+	if err != nil {
+		if errors.Is(err, ErrInvalidMFACode) {
+			h.guard.RecordFailure(ctx, claims.Email, ip)
+		}
+		writeMFAError(c, err)
+		return
+	}
+
+	h.guard.RecordSuccess(ctx, claims.Email)
+
+	// The pending token is single-use.
+	if err := h.sessions.Logout(ctx, claims); err != nil {
+		log.Printf("Failed to revoke mfa token for %s: %v", user.Email, err)
+	}
+
+	h.respondWithSession(c, user, recoveryCodes)
+}
+
+// --------------------------------------------------
+// POST /auth/mfa/recovery-codes
+// --------------------------------------------------
+func (h *Handler) MFARegenerateRecoveryCodes(c *gin.Context) {
+	claims, ok := claimsFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.Code == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "code is required"})
+		return
+	}
+
+	codes, err := h.mfa.RegenerateRecoveryCodes(c.Request.Context(), claims.UserID, req.Code)
+	if err != nil {
+		writeMFAError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"recovery_codes": codes})
+}
+
+// --------------------------------------------------
+// POST /auth/mfa/disable
+// --------------------------------------------------
+func (h *Handler) MFADisable(c *gin.Context) {
+	claims, ok := claimsFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.Code == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "code is required"})
+		return
+	}
+
+	user, err := h.service.FindUser(c.Request.Context(), claims.UserID)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if err := h.mfa.Disable(c.Request.Context(), user, req.Code); err != nil {
+		writeMFAError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "two-factor authentication disabled"})
+}
+
+func writeMFAError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, ErrInvalidMFACode):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrMFARequiredForRole):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrMFANotEnabled),
+		errors.Is(err, ErrMFANotEnrolled),
+		errors.Is(err, ErrMFAAlreadyEnabled):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "two-factor verification failed"})
+	}
+}
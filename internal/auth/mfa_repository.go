@@ -0,0 +1,48 @@
+package auth
+
+import (
+	"context"
+	"time"
+)
+
+// MFAConfig is a user's TOTP enrolment. EnabledAt stays nil until the user
+// has proved they can produce a code from the secret.
+type MFAConfig struct {
+	UserID       string
+	Secret       string // sealed by SecretBox as stored
+	EnabledAt    *time.Time
+	LastUsedStep int64
+}
+
+func (c *MFAConfig) Enabled() bool {
+	return c != nil && c.EnabledAt != nil
+}
+
+type MFARepository interface {
+	// Get returns nil when the user has never started enrolment.
+	Get(ctx context.Context, userID string) (*MFAConfig, error)
+
+	// SaveSecret stores a new, not yet enabled secret, replacing any
+	// unconfirmed one.
+	SaveSecret(ctx context.Context, userID string, secret string) error
+
+	// UpdateSecret replaces the stored secret without touching anything
+	// else, to encrypt a secret stored before encryption.
+	UpdateSecret(ctx context.Context, userID string, secret string) error
+
+	// Enable activates the stored secret and replaces the recovery codes.
+	Enable(ctx context.Context, userID string, recoveryCodeHashes []string) error
+
+	// MarkStepUsed records the time step of an accepted code. It returns
+	// false if that step (or a later one) was already used, which blocks
+	// replaying a code inside its validity window.
+	MarkStepUsed(ctx context.Context, userID string, step int64) (bool, error)
+
+	ReplaceRecoveryCodes(ctx context.Context, userID string, hashes []string) error
+
+	// ConsumeRecoveryCode marks a matching unused code as used.
+	ConsumeRecoveryCode(ctx context.Context, userID string, hash string) (bool, error)
+
+	// Delete removes the secret and all recovery codes.
+	Delete(ctx context.Context, userID string) error
+}
@@ -0,0 +1,117 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+type InMemoryMFARepository struct {
+	mu      sync.Mutex
+	configs map[string]*MFAConfig
+	codes   map[string]map[string]bool // userID -> code hash -> used
+}
+
+func NewInMemoryMFARepository() *InMemoryMFARepository {
+	return &InMemoryMFARepository{
+		configs: make(map[string]*MFAConfig),
+		codes:   make(map[string]map[string]bool),
+	}
+}
+
+func (r *InMemoryMFARepository) Get(ctx context.Context, userID string) (*MFAConfig, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c, ok := r.configs[userID]
+	if !ok {
+		return nil, nil
+	}
+	cp := *c
+	return &cp, nil
+}
+
+func (r *InMemoryMFARepository) SaveSecret(ctx context.Context, userID string, secret string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if c, ok := r.configs[userID]; ok && c.Enabled() {
+		return errors.New("mfa already enabled")
+	}
+	r.configs[userID] = &MFAConfig{UserID: userID, Secret: secret}
+	return nil
+}
+
+func (r *InMemoryMFARepository) UpdateSecret(ctx context.Context, userID string, secret string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if c, ok := r.configs[userID]; ok {
+		c.Secret = secret
+	}
+	return nil
+}
+
+func (r *InMemoryMFARepository) Enable(ctx context.Context, userID string, recoveryCodeHashes []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c, ok := r.configs[userID]
+	if !ok {
+		return errors.New("mfa not enrolled")
+	}
+	now := time.Now()
+	c.EnabledAt = &now
+	r.replaceCodes(userID, recoveryCodeHashes)
+	return nil
+}
+
+func (r *InMemoryMFARepository) MarkStepUsed(ctx context.Context, userID string, step int64) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c, ok := r.configs[userID]
+	if !ok || step <= c.LastUsedStep {
+		return false, nil
+	}
+	c.LastUsedStep = step
+	return true, nil
+}
+
+func (r *InMemoryMFARepository) ReplaceRecoveryCodes(ctx context.Context, userID string, hashes []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.replaceCodes(userID, hashes)
+	return nil
+}
+
+func (r *InMemoryMFARepository) ConsumeRecoveryCode(ctx context.Context, userID string, hash string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	used, ok := r.codes[userID][hash]
+	if !ok || used {
+		return false, nil
+	}
+	r.codes[userID][hash] = true
+	return true, nil
+}
+
+func (r *InMemoryMFARepository) Delete(ctx context.Context, userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.configs, userID)
+	delete(r.codes, userID)
+	return nil
+}
+
+func (r *InMemoryMFARepository) replaceCodes(userID string, hashes []string) {
+	codes := make(map[string]bool, len(hashes))
+	for _, h := range hashes {
+		codes[h] = false
+	}
+	r.codes[userID] = codes
+}
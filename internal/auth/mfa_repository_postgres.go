@@ -0,0 +1,167 @@
+package auth
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PostgresMFARepository struct {
+	db *pgxpool.Pool
+}
+
+func NewPostgresMFARepository(db *pgxpool.Pool) *PostgresMFARepository {
+	return &PostgresMFARepository{db: db}
+}
+
+func (r *PostgresMFARepository) Get(ctx context.Context, userID string) (*MFAConfig, error) {
+	c := MFAConfig{UserID: userID}
+
+	err := r.db.QueryRow(ctx, `
+		SELECT secret, enabled_at, last_used_step
+		FROM user_mfa
+		WHERE user_id = $1
+	`, userID).Scan(&c.Secret, &c.EnabledAt, &c.LastUsedStep)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &c, nil
+}
+
+func (r *PostgresMFARepository) SaveSecret(ctx context.Context, userID string, secret string) error {
+	tag, err := r.db.Exec(ctx, `
+		INSERT INTO user_mfa (user_id, secret)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE SET
+			secret = EXCLUDED.secret,
+			last_used_step = 0,
+			created_at = now()
+		WHERE user_mfa.enabled_at IS NULL
+	`, userID, secret)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return errors.New("mfa already enabled")
+	}
+	return nil
+}
+
+func (r *PostgresMFARepository) UpdateSecret(ctx context.Context, userID string, secret string) error {
+	_, err := r.db.Exec(ctx, `
+		UPDATE user_mfa
+		SET secret = $2
+		WHERE user_id = $1
+	`, userID, secret)
+	return err
+}
+
+func (r *PostgresMFARepository) Enable(ctx context.Context, userID string, recoveryCodeHashes []string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `
+		UPDATE user_mfa
+		SET enabled_at = now()
+		WHERE user_id = $1
+	`, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return errors.New("mfa not enrolled")
+	}
+
+	if err := replaceRecoveryCodes(ctx, tx, userID, recoveryCodeHashes); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *PostgresMFARepository) MarkStepUsed(ctx context.Context, userID string, step int64) (bool, error) {
+	tag, err := r.db.Exec(ctx, `
+		UPDATE user_mfa
+		SET last_used_step = $2
+		WHERE user_id = $1
+		  AND last_used_step < $2
+	`, userID, step)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r *PostgresMFARepository) ReplaceRecoveryCodes(ctx context.Context, userID string, hashes []string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := replaceRecoveryCodes(ctx, tx, userID, hashes); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *PostgresMFARepository) ConsumeRecoveryCode(ctx context.Context, userID string, hash string) (bool, error) {
+	tag, err := r.db.Exec(ctx, `
+		UPDATE mfa_recovery_codes
+		SET used_at = now()
+		WHERE user_id = $1
+		  AND code_hash = $2
+		  AND used_at IS NULL
+	`, userID, hash)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r *PostgresMFARepository) Delete(ctx context.Context, userID string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM mfa_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM user_mfa WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func replaceRecoveryCodes(ctx context.Context, tx pgx.Tx, userID string, hashes []string) error {
+	if _, err := tx.Exec(ctx, `DELETE FROM mfa_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+
+	for _, h := range hashes {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO mfa_recovery_codes (id, user_id, code_hash)
+			VALUES ($1, $2, $3)
+		`, uuid.New().String(), userID, h)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
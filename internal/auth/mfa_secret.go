@@ -0,0 +1,83 @@
+package auth
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// sealedSecretPrefix marks a TOTP secret encrypted by SecretBox. Secrets
+// stored before encryption have no prefix.
+const sealedSecretPrefix = "v1:"
+
+// SecretBox encrypts TOTP secrets at rest with AES-256-GCM, so reading
+// the database is not enough to mint second factors. Each secret is bound
+// to its user and cannot be copied onto another account.
+type SecretBox struct {
+	aead cipher.AEAD
+}
+
+// NewSecretBox takes a 32-byte key.
+func NewSecretBox(key []byte) (*SecretBox, error) {
+	if len(key) != 32 {
+		return nil, fmt.Errorf("mfa encryption key must be 32 bytes, got %d", len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &SecretBox{aead: aead}, nil
+}
+
+// SecretBoxFromEnv reads MFA_ENCRYPTION_KEY, 32 random bytes in base64
+// (openssl rand -base64 32).
+func SecretBoxFromEnv() (*SecretBox, error) {
+	encoded := strings.TrimSpace(os.Getenv("MFA_ENCRYPTION_KEY"))
+	if encoded == "" {
+		return nil, errors.New("MFA_ENCRYPTION_KEY is not set")
+	}
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("MFA_ENCRYPTION_KEY is not base64: %w", err)
+	}
+	return NewSecretBox(key)
+}
+
+// Seal encrypts userID's secret for storage.
+func (b *SecretBox) Seal(userID string, secret string) (string, error) {
+	nonce := make([]byte, b.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := b.aead.Seal(nonce, nonce, []byte(secret), []byte(userID))
+	return sealedSecretPrefix + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// Open decrypts a stored secret. legacy reports a secret stored in plain
+// text before encryption, which the caller should seal and save again.
+func (b *SecretBox) Open(userID string, stored string) (secret string, legacy bool, err error) {
+	encoded, ok := strings.CutPrefix(stored, sealedSecretPrefix)
+	if !ok {
+		return stored, true, nil
+	}
+
+	sealed, err := base64.RawStdEncoding.DecodeString(encoded)
+	if err != nil || len(sealed) < b.aead.NonceSize() {
+		return "", false, errors.New("corrupt mfa secret")
+	}
+	nonce, ciphertext := sealed[:b.aead.NonceSize()], sealed[b.aead.NonceSize():]
+	plain, err := b.aead.Open(nil, nonce, ciphertext, []byte(userID))
+	if err != nil {
+		return "", false, errors.New("mfa secret does not decrypt with MFA_ENCRYPTION_KEY")
+	}
+	return string(plain), false, nil
+}
@@ -0,0 +1,185 @@
+package auth
+
+import (
+	"context"
+	"encoding/base32"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTOTPCodeRFC6238Vectors(t *testing.T) {
+	// RFC 6238 appendix B, SHA1, truncated to 6 digits.
+	secret := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+	cases := map[int64]string{
+		59:         "287082",
+		1111111109: "081804",
+		1234567890: "005924",
+		2000000000: "279037",
+	}
+
+	for ts, want := range cases {
+		got, err := totpCode(secret, totpStep(time.Unix(ts, 0)))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got != want {
+			t.Errorf("t=%d: expected %s, got %s", ts, want, got)
+		}
+	}
+}
+
+func testSecretBox() *SecretBox {
+	box, err := NewSecretBox([]byte("0123456789abcdef0123456789abcdef"))
+	if err != nil {
+		panic(err)
+	}
+	return box
+}
+
+func newTestMFAService(policy MFAPolicy) (*MFAService, *fakeClock) {
+	clock := &fakeClock{t: time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)}
+	s := NewMFAService(NewInMemoryMFARepository(), testSecretBox(), policy)
+	s.now = clock.now
+	return s, clock
+}
+
+func currentCode(t *testing.T, secret string, now time.Time) string {
+	t.Helper()
+	code, err := totpCode(secret, totpStep(now))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return code
+}
+
+func TestMFAEnrollAndVerify(t *testing.T) {
+	s, clock := newTestMFAService(MFAPolicy{})
+	ctx := context.Background()
+	user := &User{ID: "user-1", Email: "owner@example.com", Role: string(RoleRestaurant)}
+
+	if req, _ := s.Requirement(ctx, user); req != MFANone {
+		t.Fatalf("expected no requirement before enrollment, got %v", req)
+	}
+
+	enrollment, err := s.BeginEnrollment(ctx, user)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if enrollment.URI == "" || enrollment.Secret == "" {
+		t.Fatalf("expected secret and otpauth uri, got %+v", enrollment)
+	}
+
+	if _, err := s.ConfirmEnrollment(ctx, user.ID, "000000"); !errors.Is(err, ErrInvalidMFACode) {
+		t.Fatalf("expected invalid code, got %v", err)
+	}
+
+	codes, err := s.ConfirmEnrollment(ctx, user.ID, currentCode(t, enrollment.Secret, clock.t))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(codes) != recoveryCodeCount {
+		t.Fatalf("expected %d recovery codes, got %d", recoveryCodeCount, len(codes))
+	}
+
+	if req, _ := s.Requirement(ctx, user); req != MFAChallenge {
+		t.Fatalf("expected challenge after enrollment, got %v", req)
+	}
+
+	// The code used for enrollment cannot be replayed in the same step.
+	if err := s.Verify(ctx, user.ID, currentCode(t, enrollment.Secret, clock.t), ""); !errors.Is(err, ErrInvalidMFACode) {
+		t.Fatalf("expected replayed code to be rejected, got %v", err)
+	}
+
+	clock.t = clock.t.Add(totpPeriod)
+	if err := s.Verify(ctx, user.ID, currentCode(t, enrollment.Secret, clock.t), ""); err != nil {
+		t.Fatalf("expected next code to verify, got %v", err)
+	}
+}
+
+func TestMFARecoveryCodesAreSingleUse(t *testing.T) {
+	s, clock := newTestMFAService(MFAPolicy{})
+	ctx := context.Background()
+	user := &User{ID: "user-1", Email: "owner@example.com"}
+
+	enrollment, _ := s.BeginEnrollment(ctx, user)
+	codes, err := s.ConfirmEnrollment(ctx, user.ID, currentCode(t, enrollment.Secret, clock.t))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Typed loosely: upper case without the dash.
+	loose := strings.ToUpper(codes[0][:4] + codes[0][5:])
+	if err := s.Verify(ctx, user.ID, "", " "+loose+" "); err != nil {
+		t.Fatalf("expected recovery code to verify, got %v", err)
+	}
+	if err := s.Verify(ctx, user.ID, "", codes[0]); !errors.Is(err, ErrInvalidMFACode) {
+		t.Fatalf("expected used recovery code to be rejected, got %v", err)
+	}
+}
+
+func TestMFAMandatoryForAdmin(t *testing.T) {
+	s, clock := newTestMFAService(MFAPolicy{RequireForAdmin: true})
+	ctx := context.Background()
+	admin := &User{ID: "admin-1", Email: "admin@example.com", Role: string(RoleAdmin)}
+	owner := &User{ID: "user-1", Email: "owner@example.com", Role: string(RoleRestaurant)}
+
+	if req, _ := s.Requirement(ctx, admin); req != MFAEnrollmentRequired {
+		t.Fatalf("expected admin to require enrollment, got %v", req)
+	}
+	if req, _ := s.Requirement(ctx, owner); req != MFANone {
+		t.Fatalf("expected owner to be optional, got %v", req)
+	}
+
+	enrollment, _ := s.BeginEnrollment(ctx, admin)
+	if _, err := s.ConfirmEnrollment(ctx, admin.ID, currentCode(t, enrollment.Secret, clock.t)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	clock.t = clock.t.Add(totpPeriod)
+	if err := s.Disable(ctx, admin, currentCode(t, enrollment.Secret, clock.t)); !errors.Is(err, ErrMFARequiredForRole) {
+		t.Fatalf("expected admin to be unable to disable mfa, got %v", err)
+	}
+}
+
+func TestMFASecretEncryptedAtRest(t *testing.T) {
+	repo := NewInMemoryMFARepository()
+	s := NewMFAService(repo, testSecretBox(), MFAPolicy{})
+	ctx := context.Background()
+	user := &User{ID: "user-1", Email: "owner@example.com", Role: string(RoleRestaurant)}
+
+	enrollment, err := s.BeginEnrollment(ctx, user)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	stored, _ := repo.Get(ctx, user.ID)
+	if strings.Contains(stored.Secret, enrollment.Secret) || !strings.HasPrefix(stored.Secret, sealedSecretPrefix) {
+		t.Fatalf("expected the secret sealed in storage, got %q", stored.Secret)
+	}
+
+	// Bound to the user: another account's row cannot reuse it.
+	if _, _, err := s.box.Open("user-2", stored.Secret); err == nil {
+		t.Fatal("expected a sealed secret not to open for another user")
+	}
+	other, _ := NewSecretBox([]byte("fedcba9876543210fedcba9876543210"))
+	if _, _, err := other.Open(user.ID, stored.Secret); err == nil {
+		t.Fatal("expected a sealed secret not to open with another key")
+	}
+
+	// A secret stored in plain text before encryption still works and
+	// is sealed on use.
+	legacy := &User{ID: "user-3", Email: "old@example.com", Role: string(RoleRestaurant)}
+	secret, _ := newTOTPSecret()
+	_ = repo.SaveSecret(ctx, legacy.ID, secret)
+	if _, err := s.ConfirmEnrollment(ctx, legacy.ID, currentCode(t, secret, time.Now())); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stored, _ := repo.Get(ctx, legacy.ID); !strings.HasPrefix(stored.Secret, sealedSecretPrefix) {
+		t.Fatalf("expected the legacy secret sealed, got %q", stored.Secret)
+	}
+	if ok, err := s.SealLegacySecret(ctx, legacy.ID); ok || err != nil {
+		t.Fatalf("expected nothing left to seal, got %v, %v", ok, err)
+	}
+}
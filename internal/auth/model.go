@@ -7,4 +7,6 @@ type User struct {
 	Email    string
 	Password string
 	Role     string
+
+	EmailVerified bool
 }
@@ -0,0 +1,15 @@
+package auth
+
+import "context"
+
+type PermissionRepository interface {
+	// ListRolePermissions returns every known role, including roles that
+	// have no permissions yet.
+	ListRolePermissions(ctx context.Context) (map[string][]Permission, error)
+
+	CreateRole(ctx context.Context, role string) error
+
+	// Grant creates the role if needed. Grant and Revoke are idempotent.
+	Grant(ctx context.Context, role string, perm Permission) error
+	Revoke(ctx context.Context, role string, perm Permission) error
+}
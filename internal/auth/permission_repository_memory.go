@@ -0,0 +1,70 @@
+package auth
+
+import (
+	"context"
+	"sync"
+)
+
+type InMemoryPermissionRepository struct {
+	mu    sync.Mutex
+	roles map[string]map[Permission]bool
+}
+
+// NewInMemoryPermissionRepository starts from DefaultRolePermissions.
+func NewInMemoryPermissionRepository() *InMemoryPermissionRepository {
+	r := &InMemoryPermissionRepository{
+		roles: make(map[string]map[Permission]bool),
+	}
+	for role, perms := range DefaultRolePermissions {
+		r.roles[string(role)] = make(map[Permission]bool)
+		for _, p := range perms {
+			r.roles[string(role)][p] = true
+		}
+	}
+	return r
+}
+
+func (r *InMemoryPermissionRepository) ListRolePermissions(ctx context.Context) (map[string][]Permission, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	out := make(map[string][]Permission, len(r.roles))
+	for role, perms := range r.roles {
+		list := []Permission{}
+		for p := range perms {
+			list = append(list, p)
+		}
+		sortPermissions(list)
+		out[role] = list
+	}
+	return out, nil
+}
+
+func (r *InMemoryPermissionRepository) CreateRole(ctx context.Context, role string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.roles[role] == nil {
+		r.roles[role] = make(map[Permission]bool)
+	}
+	return nil
+}
+
+func (r *InMemoryPermissionRepository) Grant(ctx context.Context, role string, perm Permission) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.roles[role] == nil {
+		r.roles[role] = make(map[Permission]bool)
+	}
+	r.roles[role][perm] = true
+	return nil
+}
+
+func (r *InMemoryPermissionRepository) Revoke(ctx context.Context, role string, perm Permission) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.roles[role], perm)
+	return nil
+}
@@ -0,0 +1,86 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PostgresPermissionRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewPostgresPermissionRepository(db *pgxpool.Pool) *PostgresPermissionRepository {
+	return &PostgresPermissionRepository{db: db}
+}
+
+func (r *PostgresPermissionRepository) ListRolePermissions(ctx context.Context) (map[string][]Permission, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT r.name, rp.permission
+		FROM roles r
+		LEFT JOIN role_permissions rp ON rp.role = r.name
+		ORDER BY r.name, rp.permission
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make(map[string][]Permission)
+	for rows.Next() {
+		var role string
+		var perm *string
+		if err := rows.Scan(&role, &perm); err != nil {
+			return nil, err
+		}
+		if _, ok := out[role]; !ok {
+			out[role] = []Permission{}
+		}
+		if perm != nil {
+			out[role] = append(out[role], Permission(*perm))
+		}
+	}
+
+	return out, rows.Err()
+}
+
+func (r *PostgresPermissionRepository) CreateRole(ctx context.Context, role string) error {
+	_, err := r.db.Exec(ctx, `
+		INSERT INTO roles (name) VALUES ($1)
+		ON CONFLICT (name) DO NOTHING
+	`, role)
+	return err
+}
+
+func (r *PostgresPermissionRepository) Grant(ctx context.Context, role string, perm Permission) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO roles (name) VALUES ($1)
+		ON CONFLICT (name) DO NOTHING
+	`, role); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO role_permissions (role, permission)
+		VALUES ($1, $2)
+		ON CONFLICT (role, permission) DO NOTHING
+	`, role, string(perm)); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *PostgresPermissionRepository) Revoke(ctx context.Context, role string, perm Permission) error {
+	_, err := r.db.Exec(ctx, `
+		DELETE FROM role_permissions
+		WHERE role = $1 AND permission = $2
+	`, role, string(perm))
+	return err
+}
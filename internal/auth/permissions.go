@@ -0,0 +1,84 @@
+package auth
+
+import (
+	"errors"
+	"sort"
+	"strings"
+)
+
+// Permission is a single capability a platform role can be granted.
+// Role→permission mappings live in the role_permissions table.
+type Permission string
+
+const (
+	// Restaurant portal: create restaurants, upload menus, manage deals
+	// and staff. Per-restaurant checks still apply on top.
+	PermRestaurantsManage Permission = "restaurants:manage"
+
+	PermRestaurantsRead    Permission = "restaurants:read"
+	PermRestaurantsApprove Permission = "restaurants:approve"
+	PermDealsApprove       Permission = "deals:approve"
+	PermMenusReview        Permission = "menus:review"
+
+	// Requeue dead-lettered uploads and reparse menus; admin only since
+	// both spend OCR and LLM quota across many restaurants.
+	PermPipelineManage Permission = "pipeline:manage"
+
+	PermCompetitionRecompute Permission = "competition:recompute"
+
+	PermLockoutsManage Permission = "lockouts:manage"
+)
+
+var ErrUnknownPermission = errors.New("unknown permission")
+
+// Permissions is the full catalogue, in display order.
+var Permissions = []Permission{
+	PermRestaurantsManage,
+	PermRestaurantsRead,
+	PermRestaurantsApprove,
+	PermDealsApprove,
+	PermMenusReview,
+	PermPipelineManage,
+	PermCompetitionRecompute,
+	PermLockoutsManage,
+}
+
+// DefaultRolePermissions mirrors the seed data in migrations
+// 0011_role_permissions and 0022_pipeline_permission. The in-memory
+// repository starts from it.
+var DefaultRolePermissions = map[Role][]Permission{
+	// Admins run the platform; they do not operate restaurants.
+	RoleAdmin: {
+		PermRestaurantsRead,
+		PermRestaurantsApprove,
+		PermDealsApprove,
+		PermMenusReview,
+		PermPipelineManage,
+		PermCompetitionRecompute,
+		PermLockoutsManage,
+	},
+	RoleRestaurant: {
+		PermRestaurantsManage,
+	},
+	RoleAnalyst: {
+		PermRestaurantsRead,
+	},
+	RoleReviewer: {
+		PermRestaurantsRead,
+		PermMenusReview,
+	},
+}
+
+func ParsePermission(s string) (Permission, error) {
+	p := Permission(strings.ToLower(strings.TrimSpace(s)))
+	for _, known := range Permissions {
+		if p == known {
+			return p, nil
+		}
+	}
+	return "", ErrUnknownPermission
+}
+
+func sortPermissions(perms []Permission) {
+	sort.Slice(perms, func(i, j int) bool { return perms[i] < perms[j] })
+}
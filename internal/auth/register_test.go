@@ -7,6 +7,8 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"bhojanalya/internal/mail"
+
 	"github.com/gin-gonic/gin"
 )
 func setupTestRouter() *gin.Engine {
@@ -15,7 +17,14 @@ func setupTestRouter() *gin.Engine {
 
 	repo := NewInMemoryUserRepository()
 	service := NewService(repo)
-	handler := NewHandler(service)
+	sessionRepo := NewInMemorySessionRepository()
+	handler := NewHandler(
+		service,
+		NewSessionService(sessionRepo, repo),
+		NewAccountService(repo, NewInMemoryAccountTokenRepository(), sessionRepo, mail.NewLogMailer("")),
+		NewLoginGuard(NewInMemoryLoginAttemptRepository()),
+		NewMFAService(NewInMemoryMFARepository(), testSecretBox(), MFAPolicy{RequireForAdmin: true}),
+	)
 
 	r.POST("/auth/register", handler.Register)
 
@@ -91,3 +100,88 @@ func TestRegisterDuplicateEmail(t *testing.T) {
 		t.Fatalf("expected status 409, got %d", w2.Code)
 	}
 }
+
+func TestLoginLockedAfterRepeatedFailures(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+
+	repo := NewInMemoryUserRepository()
+	service := NewService(repo)
+	sessionRepo := NewInMemorySessionRepository()
+	handler := NewHandler(
+		service,
+		NewSessionService(sessionRepo, repo),
+		NewAccountService(repo, NewInMemoryAccountTokenRepository(), sessionRepo, mail.NewLogMailer("")),
+		NewLoginGuard(NewInMemoryLoginAttemptRepository()),
+		NewMFAService(NewInMemoryMFARepository(), testSecretBox(), MFAPolicy{RequireForAdmin: true}),
+	)
+	r.POST("/auth/login", handler.Login)
+
+	body, _ := json.Marshal(map[string]string{
+		"email":    "nobody@example.com",
+		"password": "wrong-password",
+	})
+
+	var last *httptest.ResponseRecorder
+	for i := 0; i <= DefaultAccountLockoutPolicy.Threshold; i++ {
+		req := httptest.NewRequest(http.MethodPost, "/auth/login", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		last = httptest.NewRecorder()
+		r.ServeHTTP(last, req)
+	}
+
+	if last.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected status 429, got %d", last.Code)
+	}
+	if last.Header().Get("Retry-After") == "" {
+		t.Fatalf("expected Retry-After header")
+	}
+}
+
+func TestAdminLoginRequiresMFA(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	t.Setenv("JWT_SECRET", "test-secret-key-for-testing-only")
+	r := gin.New()
+
+	repo := NewInMemoryUserRepository()
+	hash, _ := HashPassword("Password@123")
+	_ = repo.Save(&User{Name: "Admin", Email: "admin@example.com", Password: hash, Role: string(RoleAdmin)})
+
+	sessionRepo := NewInMemorySessionRepository()
+	handler := NewHandler(
+		NewService(repo),
+		NewSessionService(sessionRepo, repo),
+		NewAccountService(repo, NewInMemoryAccountTokenRepository(), sessionRepo, mail.NewLogMailer("")),
+		NewLoginGuard(NewInMemoryLoginAttemptRepository()),
+		NewMFAService(NewInMemoryMFARepository(), testSecretBox(), MFAPolicy{RequireForAdmin: true}),
+	)
+	r.POST("/auth/login", handler.Login)
+
+	body, _ := json.Marshal(map[string]string{
+		"email":    "admin@example.com",
+		"password": "Password@123",
+	})
+	req := httptest.NewRequest(http.MethodPost, "/auth/login", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+
+	var resp map[string]interface{}
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+
+	if resp["mfa_enrollment_required"] != true {
+		t.Fatalf("expected mfa enrollment to be required, got %v", resp)
+	}
+	if _, ok := resp["token"]; ok {
+		t.Fatalf("no access token must be issued before the second factor")
+	}
+
+	claims, err := ParseToken(resp["mfa_token"].(string))
+	if err != nil || !claims.MFAPending {
+		t.Fatalf("expected an mfa pending token, got %v (%v)", claims, err)
+	}
+}
@@ -6,6 +6,12 @@ type UserRepository interface {
 	Save(user *User) error
 	ExistsByEmail(email string) (bool, error)
 	FindByEmail(email string) (*User, error)
+	FindByID(ctx context.Context, id string) (*User, error)
+	ListUsers(ctx context.Context) ([]*User, error)
+	UpdateRole(ctx context.Context, userID string, role string) error
+	UpdatePassword(ctx context.Context, userID string, passwordHash string) error
+	MarkEmailVerified(ctx context.Context, userID string) error
+	IsEmailVerified(ctx context.Context, userID string) (bool, error)
 	// Add these two:
 	GetOnboardingStatus(ctx context.Context, userID string) (string, error)
 	UpdateOnboardingStatus(ctx context.Context, userID string, status string) error
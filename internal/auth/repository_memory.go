@@ -3,6 +3,7 @@ package auth
 import (
 	"context"
 	"errors"
+	"sort"
 
 	"github.com/google/uuid"
 )
@@ -38,6 +39,60 @@ func (r *InMemoryUserRepository) FindByEmail(email string) (*User, error) {
 	}
 	return user, nil
 }
+
+func (r *InMemoryUserRepository) FindByID(ctx context.Context, id string) (*User, error) {
+	for _, user := range r.users {
+		if user.ID == id {
+			return user, nil
+		}
+	}
+	return nil, errors.New("user not found")
+}
+
+func (r *InMemoryUserRepository) ListUsers(ctx context.Context) ([]*User, error) {
+	users := make([]*User, 0, len(r.users))
+	for _, user := range r.users {
+		users = append(users, user)
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].Email < users[j].Email })
+	return users, nil
+}
+
+func (r *InMemoryUserRepository) UpdateRole(ctx context.Context, userID string, role string) error {
+	user, err := r.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	user.Role = role
+	return nil
+}
+
+func (r *InMemoryUserRepository) UpdatePassword(ctx context.Context, userID string, passwordHash string) error {
+	user, err := r.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	user.Password = passwordHash
+	return nil
+}
+
+func (r *InMemoryUserRepository) MarkEmailVerified(ctx context.Context, userID string) error {
+	user, err := r.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	user.EmailVerified = true
+	return nil
+}
+
+func (r *InMemoryUserRepository) IsEmailVerified(ctx context.Context, userID string) (bool, error) {
+	user, err := r.FindByID(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	return user.EmailVerified, nil
+}
+
 func (r *InMemoryUserRepository) GetOnboardingStatus(ctx context.Context, userID string) (string, error) {
 	return "PENDING", nil
 }
@@ -23,11 +23,11 @@ func (r *PostgresUserRepository) Save(user *User) error {
 	}
 
 	query := `
-		INSERT INTO users (id, name, email, password, role)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO users (id, name, email, password, role, email_verified_at)
+		VALUES ($1, $2, $3, $4, $5, CASE WHEN $6 THEN now() END)
 	`
 	_, err := r.db.Exec(context.Background(), query,
-		user.ID, user.Name, user.Email, user.Password, user.Role,
+		user.ID, user.Name, user.Email, user.Password, user.Role, user.EmailVerified,
 	)
 	return err
 }
@@ -46,18 +46,108 @@ func (r *PostgresUserRepository) ExistsByEmail(email string) (bool, error) {
 
 func (r *PostgresUserRepository) FindByEmail(email string) (*User, error) {
 	query := `
-		SELECT id, name, email, password, role
+		SELECT id, name, email, password, role, email_verified_at IS NOT NULL
 		FROM users WHERE email=$1
 	`
 	row := r.db.QueryRow(context.Background(), query, email)
 
 	user := &User{}
-	if err := row.Scan(&user.ID, &user.Name, &user.Email, &user.Password, &user.Role); err != nil {
+	if err := row.Scan(&user.ID, &user.Name, &user.Email, &user.Password, &user.Role, &user.EmailVerified); err != nil {
 		return nil, errors.New("user not found")
 	}
 	return user, nil
 }
 
+func (r *PostgresUserRepository) FindByID(ctx context.Context, id string) (*User, error) {
+	query := `
+		SELECT id, name, email, password, role, email_verified_at IS NOT NULL
+		FROM users WHERE id=$1
+	`
+	row := r.db.QueryRow(ctx, query, id)
+
+	user := &User{}
+	if err := row.Scan(&user.ID, &user.Name, &user.Email, &user.Password, &user.Role, &user.EmailVerified); err != nil {
+		return nil, errors.New("user not found")
+	}
+	return user, nil
+}
+
+func (r *PostgresUserRepository) ListUsers(ctx context.Context) ([]*User, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT id, name, email, password, role, email_verified_at IS NOT NULL
+		FROM users
+		ORDER BY created_at
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []*User
+	for rows.Next() {
+		user := &User{}
+		if err := rows.Scan(&user.ID, &user.Name, &user.Email, &user.Password, &user.Role, &user.EmailVerified); err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+
+	return users, rows.Err()
+}
+
+func (r *PostgresUserRepository) UpdateRole(ctx context.Context, userID string, role string) error {
+	cmd, err := r.db.Exec(ctx, `
+		UPDATE users
+		SET role = $1
+		WHERE id = $2
+	`, role, userID)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return errors.New("user not found")
+	}
+	return nil
+}
+
+func (r *PostgresUserRepository) UpdatePassword(ctx context.Context, userID string, passwordHash string) error {
+	cmd, err := r.db.Exec(ctx, `
+		UPDATE users
+		SET password = $1
+		WHERE id = $2
+	`, passwordHash, userID)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return errors.New("user not found")
+	}
+	return nil
+}
+
+// --------------------------------------------------
+// Email verification
+// --------------------------------------------------
+
+func (r *PostgresUserRepository) MarkEmailVerified(ctx context.Context, userID string) error {
+	_, err := r.db.Exec(ctx, `
+		UPDATE users
+		SET email_verified_at = COALESCE(email_verified_at, now())
+		WHERE id = $1
+	`, userID)
+	return err
+}
+
+func (r *PostgresUserRepository) IsEmailVerified(ctx context.Context, userID string) (bool, error) {
+	var verified bool
+	err := r.db.QueryRow(ctx, `
+		SELECT email_verified_at IS NOT NULL
+		FROM users
+		WHERE id = $1
+	`, userID).Scan(&verified)
+	return verified, err
+}
+
 // --------------------------------------------------
 // Onboarding Status
 // --------------------------------------------------
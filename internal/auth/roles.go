@@ -5,4 +5,9 @@ type Role string
 const (
 	RoleAdmin      Role = "ADMIN"
 	RoleRestaurant Role = "RESTAURANT"
+
+	// RoleAnalyst can read restaurant and market data but change nothing.
+	RoleAnalyst Role = "ANALYST"
+	// RoleReviewer can only moderate uploaded menus.
+	RoleReviewer Role = "REVIEWER"
 )
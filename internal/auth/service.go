@@ -4,16 +4,30 @@ import (
 	"context"
 	"errors"
 	"log"
+	"sync"
 
 	"golang.org/x/crypto/bcrypt"
 )
 
 var (
 	ErrInvalidCredentials = errors.New("invalid email or password")
-	AdminEmail            = "admin@bhojanalya.com"
-	AdminPassword         = "Bhojanalya@12345"
+	ErrWeakPassword       = errors.New("password must be at least 8 characters")
 )
 
+const minPasswordLength = 8
+
+var (
+	dummyHashOnce sync.Once
+	dummyHash     []byte
+)
+
+func dummyPasswordHash() []byte {
+	dummyHashOnce.Do(func() {
+		dummyHash, _ = bcrypt.GenerateFromPassword([]byte("not-a-real-password"), bcrypt.DefaultCost)
+	})
+	return dummyHash
+}
+
 type Service struct {
 	repo UserRepository
 }
@@ -33,10 +47,7 @@ func (s *Service) Register(name, email, password string) (*User, error) {
 		return nil, errors.New("email already exists")
 	}
 
-	hashedPassword, err := bcrypt.GenerateFromPassword(
-		[]byte(password),
-		bcrypt.DefaultCost,
-	)
+	hashedPassword, err := HashPassword(password)
 	if err != nil {
 		return nil, err
 	}
@@ -44,7 +55,7 @@ func (s *Service) Register(name, email, password string) (*User, error) {
 	user := &User{
 		Name:     name,
 		Email:    email,
-		Password: string(hashedPassword),
+		Password: hashedPassword,
 		Role:     string(RoleRestaurant),
 	}
 
@@ -59,54 +70,12 @@ func (s *Service) Register(name, email, password string) (*User, error) {
 func (s *Service) Login(email, password string) (*User, error) {
 	log.Printf("Login attempt for email: %s", email)
 
-	// Check if it's the admin credentials
-	if email == AdminEmail && password == AdminPassword {
-		log.Printf("Admin login successful for email: %s", email)
-
-		// Check if admin already exists in database
-		adminUser, err := s.repo.FindByEmail(email)
-		if err != nil {
-			// Admin doesn't exist, create them with ADMIN role
-			hashedPassword, err := bcrypt.GenerateFromPassword(
-				[]byte(password),
-				bcrypt.DefaultCost,
-			)
-			if err != nil {
-				return nil, err
-			}
-
-			adminUser := &User{
-				Name:     "Bhojanalya Admin",
-				Email:    email,
-				Password: string(hashedPassword),
-				Role:     string(RoleAdmin), // Stored as ADMIN role in database
-			}
-
-			if err := s.repo.Save(adminUser); err != nil {
-				log.Printf("Error saving admin user: %v", err)
-				return nil, err
-			}
-
-			log.Printf("Admin user created in database with ADMIN role")
-			return adminUser, nil
-		}
-
-		// Admin exists, verify password and return
-		err = bcrypt.CompareHashAndPassword(
-			[]byte(adminUser.Password),
-			[]byte(password),
-		)
-		if err != nil {
-			log.Printf("Admin password mismatch for email: %s", email)
-			return nil, ErrInvalidCredentials
-		}
-
-		return adminUser, nil
-	}
-
 	user, err := s.repo.FindByEmail(email)
 	if err != nil {
 		log.Printf("User not found: %s", email)
+		// Spend the same bcrypt time as a real check so response timing
+		// does not reveal which emails are registered.
+		_ = bcrypt.CompareHashAndPassword(dummyPasswordHash(), []byte(password))
 		return nil, ErrInvalidCredentials
 	}
 
@@ -122,6 +91,11 @@ func (s *Service) Login(email, password string) (*User, error) {
 	log.Printf("Login successful for email: %s with role: %s", email, user.Role)
 	return user, nil
 }
+
+func (s *Service) FindUser(ctx context.Context, userID string) (*User, error) {
+	return s.repo.FindByID(ctx, userID)
+}
+
 func (s *Service) GetOnboardingStatus(ctx context.Context, userID string) (string, error) {
 	return s.repo.GetOnboardingStatus(ctx, userID)
 }
@@ -129,3 +103,23 @@ func (s *Service) GetOnboardingStatus(ctx context.Context, userID string) (strin
 func (s *Service) UpdateOnboardingStatus(ctx context.Context, userID string, status string) error {
 	return s.repo.UpdateOnboardingStatus(ctx, userID, status)
 }
+
+// HashPassword bcrypt-hashes a plain password for storage.
+func HashPassword(password string) (string, error) {
+	hashed, err := bcrypt.GenerateFromPassword(
+		[]byte(password),
+		bcrypt.DefaultCost,
+	)
+	if err != nil {
+		return "", err
+	}
+	return string(hashed), nil
+}
+
+// ValidatePasswordStrength enforces the minimum policy for new passwords.
+func ValidatePasswordStrength(password string) error {
+	if len(password) < minPasswordLength {
+		return ErrWeakPassword
+	}
+	return nil
+}
@@ -1,6 +1,9 @@
 package auth
 
-import "testing"
+import (
+	"context"
+	"testing"
+)
 
 func TestPasswordIsHashedBeforeSaving(t *testing.T) {
 	repo := NewInMemoryUserRepository()
@@ -22,3 +25,38 @@ func TestPasswordIsHashedBeforeSaving(t *testing.T) {
 		t.Fatalf("password was stored in plain text")
 	}
 }
+
+func TestLoginHasNoBuiltInAdmin(t *testing.T) {
+	repo := NewInMemoryUserRepository()
+	service := NewService(repo)
+
+	if _, err := service.Login("admin@bhojanalya.com", "Bhojanalya@12345"); err != ErrInvalidCredentials {
+		t.Fatalf("expected invalid credentials, got %v", err)
+	}
+
+	if exists, _ := repo.ExistsByEmail("admin@bhojanalya.com"); exists {
+		t.Fatalf("login must not create users")
+	}
+}
+
+func TestLoginPromotedAdmin(t *testing.T) {
+	repo := NewInMemoryUserRepository()
+	service := NewService(repo)
+
+	user, err := service.Register("Ops", "ops@example.com", "Password@123")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := repo.UpdateRole(context.Background(), user.ID, string(RoleAdmin)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	loggedIn, err := service.Login("ops@example.com", "Password@123")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if loggedIn.Role != string(RoleAdmin) {
+		t.Fatalf("expected ADMIN role, got %s", loggedIn.Role)
+	}
+}
@@ -0,0 +1,179 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"log"
+	"time"
+)
+
+var (
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token reuse detected")
+	ErrSessionRevoked      = errors.New("session revoked")
+)
+
+// TokenPair is what the client receives on login and refresh.
+type TokenPair struct {
+	AccessToken      string    `json:"token"`
+	RefreshToken     string    `json:"refresh_token"`
+	ExpiresAt        time.Time `json:"expires_at"`
+	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
+}
+
+// SessionService issues access/refresh token pairs and handles revocation.
+type SessionService struct {
+	sessions   SessionRepository
+	users      UserRepository
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+}
+
+func NewSessionService(sessions SessionRepository, users UserRepository) *SessionService {
+	return &SessionService{
+		sessions:   sessions,
+		users:      users,
+		accessTTL:  accessTokenTTL(),
+		refreshTTL: refreshTokenTTL(),
+	}
+}
+
+// --------------------------------------------------
+// Start session (LOGIN)
+// --------------------------------------------------
+func (s *SessionService) StartSession(ctx context.Context, user *User) (*TokenPair, error) {
+	session := &Session{UserID: user.ID}
+	if err := s.sessions.CreateSession(ctx, session); err != nil {
+		return nil, err
+	}
+
+	return s.issuePair(ctx, user, session.ID)
+}
+
+// --------------------------------------------------
+// Refresh (ROTATING)
+// --------------------------------------------------
+func (s *SessionService) Refresh(ctx context.Context, refreshToken string) (*TokenPair, error) {
+	if refreshToken == "" {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	stored, err := s.sessions.FindRefreshToken(ctx, hashToken(refreshToken))
+	if err != nil {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	revoked, err := s.sessions.IsRevoked(ctx, "", stored.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, ErrSessionRevoked
+	}
+
+	// A rotated token coming back means it leaked: kill the whole family.
+	fresh, err := s.sessions.MarkRefreshTokenUsed(ctx, stored.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !fresh {
+		log.Printf("Refresh token reuse detected for session %s, revoking", stored.SessionID)
+		_ = s.sessions.RevokeSession(ctx, stored.SessionID, "refresh_token_reuse")
+		return nil, ErrRefreshTokenReused
+	}
+
+	if time.Now().After(stored.ExpiresAt) {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	// Re-read the user so role changes take effect on the next access token.
+	user, err := s.users.FindByID(ctx, stored.UserID)
+	if err != nil {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	return s.issuePair(ctx, user, stored.SessionID)
+}
+
+// --------------------------------------------------
+// Logout
+// --------------------------------------------------
+func (s *SessionService) Logout(ctx context.Context, claims *TokenClaims) error {
+	if claims.JTI != "" {
+		if err := s.sessions.RevokeAccessToken(ctx, claims.JTI, claims.ExpiresAt); err != nil {
+			return err
+		}
+	}
+
+	if claims.SessionID != "" {
+		return s.sessions.RevokeSession(ctx, claims.SessionID, "logout")
+	}
+
+	return nil
+}
+
+// RevokeUserSessions logs a user out everywhere.
+func (s *SessionService) RevokeUserSessions(ctx context.Context, userID string, reason string) error {
+	return s.sessions.RevokeUserSessions(ctx, userID, reason)
+}
+
+// IsRevoked is used by the auth middleware on every request.
+func (s *SessionService) IsRevoked(ctx context.Context, jti string, sessionID string) (bool, error) {
+	return s.sessions.IsRevoked(ctx, jti, sessionID)
+}
+
+// --------------------------------------------------
+// Helpers
+// --------------------------------------------------
+
+func (s *SessionService) issuePair(ctx context.Context, user *User, sessionID string) (*TokenPair, error) {
+	accessToken, claims, err := generateAccessToken(
+		user.ID,
+		user.Email,
+		user.Role,
+		sessionID,
+		s.accessTTL,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	plain, err := newOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	refresh := &RefreshToken{
+		SessionID: sessionID,
+		UserID:    user.ID,
+		TokenHash: hashToken(plain),
+		ExpiresAt: time.Now().Add(s.refreshTTL),
+	}
+	if err := s.sessions.SaveRefreshToken(ctx, refresh); err != nil {
+		return nil, err
+	}
+
+	return &TokenPair{
+		AccessToken:      accessToken,
+		RefreshToken:     plain,
+		ExpiresAt:        claims.ExpiresAt,
+		RefreshExpiresAt: refresh.ExpiresAt,
+	}, nil
+}
+
+func newOpaqueToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,43 @@
+package auth
+
+import (
+	"context"
+	"time"
+)
+
+// Session is one login. Every refresh token rotated from that login
+// belongs to the same session, so revoking it kills the whole family.
+type Session struct {
+	ID        string
+	UserID    string
+	CreatedAt time.Time
+	RevokedAt *time.Time
+}
+
+// RefreshToken is stored hashed; the plain value is only ever returned to the client.
+type RefreshToken struct {
+	ID        string
+	SessionID string
+	UserID    string
+	TokenHash string
+	ExpiresAt time.Time
+	CreatedAt time.Time
+	UsedAt    *time.Time
+}
+
+type SessionRepository interface {
+	CreateSession(ctx context.Context, session *Session) error
+	SaveRefreshToken(ctx context.Context, token *RefreshToken) error
+	FindRefreshToken(ctx context.Context, tokenHash string) (*RefreshToken, error)
+
+	// MarkRefreshTokenUsed flags a token as rotated. It returns false if the
+	// token had already been used, which means it is being replayed.
+	MarkRefreshTokenUsed(ctx context.Context, tokenID string) (bool, error)
+
+	RevokeSession(ctx context.Context, sessionID string, reason string) error
+	RevokeUserSessions(ctx context.Context, userID string, reason string) error
+	RevokeAccessToken(ctx context.Context, jti string, expiresAt time.Time) error
+
+	// IsRevoked reports whether the access token jti or its session was revoked.
+	IsRevoked(ctx context.Context, jti string, sessionID string) (bool, error)
+}
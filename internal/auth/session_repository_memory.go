@@ -0,0 +1,124 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type InMemorySessionRepository struct {
+	mu            sync.Mutex
+	sessions      map[string]*Session
+	refreshTokens map[string]*RefreshToken // keyed by token hash
+	revokedJTIs   map[string]time.Time
+}
+
+func NewInMemorySessionRepository() *InMemorySessionRepository {
+	return &InMemorySessionRepository{
+		sessions:      make(map[string]*Session),
+		refreshTokens: make(map[string]*RefreshToken),
+		revokedJTIs:   make(map[string]time.Time),
+	}
+}
+
+func (r *InMemorySessionRepository) CreateSession(ctx context.Context, session *Session) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if session.ID == "" {
+		session.ID = uuid.New().String()
+	}
+	session.CreatedAt = time.Now()
+	r.sessions[session.ID] = session
+	return nil
+}
+
+func (r *InMemorySessionRepository) SaveRefreshToken(ctx context.Context, token *RefreshToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if token.ID == "" {
+		token.ID = uuid.New().String()
+	}
+	token.CreatedAt = time.Now()
+	r.refreshTokens[token.TokenHash] = token
+	return nil
+}
+
+func (r *InMemorySessionRepository) FindRefreshToken(ctx context.Context, tokenHash string) (*RefreshToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	token, ok := r.refreshTokens[tokenHash]
+	if !ok {
+		return nil, errors.New("refresh token not found")
+	}
+	copied := *token
+	return &copied, nil
+}
+
+func (r *InMemorySessionRepository) MarkRefreshTokenUsed(ctx context.Context, tokenID string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, token := range r.refreshTokens {
+		if token.ID != tokenID {
+			continue
+		}
+		if token.UsedAt != nil {
+			return false, nil
+		}
+		now := time.Now()
+		token.UsedAt = &now
+		return true, nil
+	}
+	return false, errors.New("refresh token not found")
+}
+
+func (r *InMemorySessionRepository) RevokeSession(ctx context.Context, sessionID string, reason string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if s, ok := r.sessions[sessionID]; ok && s.RevokedAt == nil {
+		now := time.Now()
+		s.RevokedAt = &now
+	}
+	return nil
+}
+
+func (r *InMemorySessionRepository) RevokeUserSessions(ctx context.Context, userID string, reason string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for _, s := range r.sessions {
+		if s.UserID == userID && s.RevokedAt == nil {
+			s.RevokedAt = &now
+		}
+	}
+	return nil
+}
+
+func (r *InMemorySessionRepository) RevokeAccessToken(ctx context.Context, jti string, expiresAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.revokedJTIs[jti] = expiresAt
+	return nil
+}
+
+func (r *InMemorySessionRepository) IsRevoked(ctx context.Context, jti string, sessionID string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.revokedJTIs[jti]; ok && jti != "" {
+		return true, nil
+	}
+	if s, ok := r.sessions[sessionID]; ok && s.RevokedAt != nil {
+		return true, nil
+	}
+	return false, nil
+}
@@ -0,0 +1,142 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PostgresSessionRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewPostgresSessionRepository(db *pgxpool.Pool) *PostgresSessionRepository {
+	return &PostgresSessionRepository{db: db}
+}
+
+func (r *PostgresSessionRepository) CreateSession(ctx context.Context, session *Session) error {
+	if session.ID == "" {
+		session.ID = uuid.New().String()
+	}
+
+	return r.db.QueryRow(ctx, `
+		INSERT INTO auth_sessions (id, user_id)
+		VALUES ($1, $2)
+		RETURNING created_at
+	`, session.ID, session.UserID).Scan(&session.CreatedAt)
+}
+
+func (r *PostgresSessionRepository) SaveRefreshToken(ctx context.Context, token *RefreshToken) error {
+	if token.ID == "" {
+		token.ID = uuid.New().String()
+	}
+
+	return r.db.QueryRow(ctx, `
+		INSERT INTO refresh_tokens (id, session_id, user_id, token_hash, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING created_at
+	`,
+		token.ID,
+		token.SessionID,
+		token.UserID,
+		token.TokenHash,
+		token.ExpiresAt,
+	).Scan(&token.CreatedAt)
+}
+
+func (r *PostgresSessionRepository) FindRefreshToken(ctx context.Context, tokenHash string) (*RefreshToken, error) {
+	var t RefreshToken
+
+	err := r.db.QueryRow(ctx, `
+		SELECT id, session_id, user_id, token_hash, expires_at, created_at, used_at
+		FROM refresh_tokens
+		WHERE token_hash = $1
+	`, tokenHash).Scan(
+		&t.ID,
+		&t.SessionID,
+		&t.UserID,
+		&t.TokenHash,
+		&t.ExpiresAt,
+		&t.CreatedAt,
+		&t.UsedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("refresh token not found")
+		}
+		return nil, err
+	}
+
+	return &t, nil
+}
+
+func (r *PostgresSessionRepository) MarkRefreshTokenUsed(ctx context.Context, tokenID string) (bool, error) {
+	cmd, err := r.db.Exec(ctx, `
+		UPDATE refresh_tokens
+		SET used_at = now()
+		WHERE id = $1
+		  AND used_at IS NULL
+	`, tokenID)
+	if err != nil {
+		return false, err
+	}
+
+	return cmd.RowsAffected() == 1, nil
+}
+
+func (r *PostgresSessionRepository) RevokeSession(ctx context.Context, sessionID string, reason string) error {
+	_, err := r.db.Exec(ctx, `
+		UPDATE auth_sessions
+		SET revoked_at = now(),
+		    revoke_reason = $2
+		WHERE id = $1
+		  AND revoked_at IS NULL
+	`, sessionID, reason)
+	return err
+}
+
+func (r *PostgresSessionRepository) RevokeUserSessions(ctx context.Context, userID string, reason string) error {
+	_, err := r.db.Exec(ctx, `
+		UPDATE auth_sessions
+		SET revoked_at = now(),
+		    revoke_reason = $2
+		WHERE user_id = $1
+		  AND revoked_at IS NULL
+	`, userID, reason)
+	return err
+}
+
+func (r *PostgresSessionRepository) RevokeAccessToken(ctx context.Context, jti string, expiresAt time.Time) error {
+	// Expired entries can never match a valid token again, so prune them here.
+	_, _ = r.db.Exec(ctx, `DELETE FROM revoked_tokens WHERE expires_at < now()`)
+
+	_, err := r.db.Exec(ctx, `
+		INSERT INTO revoked_tokens (jti, expires_at)
+		VALUES ($1, $2)
+		ON CONFLICT (jti) DO NOTHING
+	`, jti, expiresAt)
+	return err
+}
+
+func (r *PostgresSessionRepository) IsRevoked(ctx context.Context, jti string, sessionID string) (bool, error) {
+	var revoked bool
+
+	err := r.db.QueryRow(ctx, `
+		SELECT
+			EXISTS (
+				SELECT 1 FROM revoked_tokens
+				WHERE jti::text = $1
+			)
+			OR EXISTS (
+				SELECT 1 FROM auth_sessions
+				WHERE id::text = $2
+				  AND revoked_at IS NOT NULL
+			)
+	`, jti, sessionID).Scan(&revoked)
+
+	return revoked, err
+}
@@ -0,0 +1,106 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func newTestSessionService(t *testing.T) (*SessionService, *User) {
+	t.Setenv("JWT_SECRET", "test-secret-key-for-testing-only")
+
+	users := NewInMemoryUserRepository()
+	service := NewService(users)
+
+	user, err := service.Register("Test User", "test@example.com", "Password@123")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	return NewSessionService(NewInMemorySessionRepository(), users), user
+}
+
+func TestRefreshRotatesToken(t *testing.T) {
+	sessions, user := newTestSessionService(t)
+	ctx := context.Background()
+
+	first, err := sessions.StartSession(ctx, user)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	second, err := sessions.Refresh(ctx, first.RefreshToken)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if second.RefreshToken == first.RefreshToken {
+		t.Fatalf("refresh token was not rotated")
+	}
+
+	claims, err := ParseToken(second.AccessToken)
+	if err != nil {
+		t.Fatalf("new access token invalid: %v", err)
+	}
+	if claims.UserID != user.ID || claims.SessionID == "" || claims.JTI == "" {
+		t.Fatalf("unexpected claims: %+v", claims)
+	}
+}
+
+func TestRefreshReuseRevokesFamily(t *testing.T) {
+	sessions, user := newTestSessionService(t)
+	ctx := context.Background()
+
+	first, _ := sessions.StartSession(ctx, user)
+	second, err := sessions.Refresh(ctx, first.RefreshToken)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Replaying the rotated token must fail and kill the session.
+	if _, err := sessions.Refresh(ctx, first.RefreshToken); !errors.Is(err, ErrRefreshTokenReused) {
+		t.Fatalf("expected reuse error, got %v", err)
+	}
+
+	if _, err := sessions.Refresh(ctx, second.RefreshToken); err == nil {
+		t.Fatalf("expected latest refresh token to be revoked with its family")
+	}
+
+	claims, _ := ParseToken(second.AccessToken)
+	revoked, _ := sessions.IsRevoked(ctx, claims.JTI, claims.SessionID)
+	if !revoked {
+		t.Fatalf("expected access token of revoked session to be rejected")
+	}
+}
+
+func TestLogoutRevokesSession(t *testing.T) {
+	sessions, user := newTestSessionService(t)
+	ctx := context.Background()
+
+	pair, _ := sessions.StartSession(ctx, user)
+	claims, err := ParseToken(pair.AccessToken)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := sessions.Logout(ctx, claims); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	revoked, _ := sessions.IsRevoked(ctx, claims.JTI, claims.SessionID)
+	if !revoked {
+		t.Fatalf("expected token to be revoked after logout")
+	}
+
+	if _, err := sessions.Refresh(ctx, pair.RefreshToken); err == nil {
+		t.Fatalf("expected refresh to fail after logout")
+	}
+}
+
+func TestRefreshUnknownToken(t *testing.T) {
+	sessions, _ := newTestSessionService(t)
+
+	if _, err := sessions.Refresh(context.Background(), "not-a-token"); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Fatalf("expected invalid refresh token error, got %v", err)
+	}
+}
@@ -0,0 +1,25 @@
+package core
+
+import "context"
+
+// RestaurantAction is something a restaurant member may be allowed to do.
+type RestaurantAction string
+
+const (
+	ActionViewRestaurant RestaurantAction = "restaurant:view"
+	ActionEditRestaurant RestaurantAction = "restaurant:edit"
+	ActionManageMenu     RestaurantAction = "menu:manage"
+	ActionManageDeals    RestaurantAction = "deals:manage"
+	ActionManageMembers  RestaurantAction = "members:manage"
+)
+
+// RestaurantAccess answers per-restaurant permission checks based on the
+// user's membership role.
+type RestaurantAccess interface {
+	CanAccess(
+		ctx context.Context,
+		restaurantID int,
+		userID string,
+		action RestaurantAction,
+	) (bool, error)
+}
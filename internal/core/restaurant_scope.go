@@ -0,0 +1,24 @@
+package core
+
+import "context"
+
+type boundRestaurantKey struct{}
+
+// WithBoundRestaurant marks a request as limited to one restaurant, e.g.
+// when it is authenticated by an API key bound to that restaurant.
+func WithBoundRestaurant(ctx context.Context, restaurantID int) context.Context {
+	return context.WithValue(ctx, boundRestaurantKey{}, restaurantID)
+}
+
+// BoundRestaurant returns the restaurant the request is limited to, if any.
+func BoundRestaurant(ctx context.Context) (int, bool) {
+	id, ok := ctx.Value(boundRestaurantKey{}).(int)
+	return id, ok
+}
+
+// RestaurantInScope reports whether the request may touch restaurantID at
+// all. Membership checks still apply on top.
+func RestaurantInScope(ctx context.Context, restaurantID int) bool {
+	bound, ok := BoundRestaurant(ctx)
+	return !ok || bound == restaurantID
+}
@@ -0,0 +1,84 @@
+package db
+
+import (
+	"io/fs"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoadMigrations_OrdersAndPairs(t *testing.T) {
+	fsys := fstest.MapFS{
+		"0002_second.up.sql":   {Data: []byte("CREATE TABLE b (id INT);")},
+		"0002_second.down.sql": {Data: []byte("DROP TABLE b;")},
+		"0001_first.up.sql":    {Data: []byte("CREATE TABLE a (id INT);")},
+	}
+
+	migrations, err := LoadMigrations(fsys)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(migrations) != 2 {
+		t.Fatalf("expected 2 migrations, got %d", len(migrations))
+	}
+
+	if migrations[0].Version != 1 || migrations[1].Version != 2 {
+		t.Fatalf("migrations not ordered by version: %+v", migrations)
+	}
+
+	if migrations[0].DownSQL != "" {
+		t.Errorf("expected no down SQL for first migration")
+	}
+
+	if migrations[1].DownSQL != "DROP TABLE b;" {
+		t.Errorf("down SQL not paired with up SQL")
+	}
+
+	if len(migrations[0].Checksum) != 64 {
+		t.Errorf("expected sha256 hex checksum, got %q", migrations[0].Checksum)
+	}
+}
+
+func TestLoadMigrations_Invalid(t *testing.T) {
+	cases := map[string]fstest.MapFS{
+		"bad filename": {
+			"init.sql": {Data: []byte("SELECT 1;")},
+		},
+		"missing up": {
+			"0001_first.down.sql": {Data: []byte("DROP TABLE a;")},
+		},
+		"conflicting names": {
+			"0001_first.up.sql": {Data: []byte("SELECT 1;")},
+			"0001_other.up.sql": {Data: []byte("SELECT 2;")},
+		},
+	}
+
+	for name, fsys := range cases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := LoadMigrations(fsys); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
+
+func TestEmbeddedMigrations_Load(t *testing.T) {
+	sub, err := fs.Sub(migrationFiles, "migrations")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	migrations, err := LoadMigrations(sub)
+	if err != nil {
+		t.Fatalf("embedded migrations invalid: %v", err)
+	}
+
+	for i, m := range migrations {
+		if m.Version != i+1 {
+			t.Fatalf("expected contiguous versions, got %d at position %d", m.Version, i)
+		}
+		if m.DownSQL == "" {
+			t.Errorf("migration %d (%s) has no down file", m.Version, m.Name)
+		}
+	}
+}
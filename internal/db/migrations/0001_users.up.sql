@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS users (
+	id UUID PRIMARY KEY,
+	name VARCHAR(255) NOT NULL,
+	email VARCHAR(255) UNIQUE NOT NULL,
+	password VARCHAR(255) NOT NULL,
+	role VARCHAR(50) NOT NULL DEFAULT 'RESTAURANT',
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE users
+	ADD COLUMN IF NOT EXISTS role VARCHAR(50) NOT NULL DEFAULT 'RESTAURANT';
+
+ALTER TABLE users
+	ADD COLUMN IF NOT EXISTS onboarding_status VARCHAR(50) NULL;
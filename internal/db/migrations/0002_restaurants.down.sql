@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS restaurant_images;
+DROP TABLE IF EXISTS restaurants;
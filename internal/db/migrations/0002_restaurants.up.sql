@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS restaurants (
+	id SERIAL PRIMARY KEY,
+	name VARCHAR(255) NOT NULL,
+	city VARCHAR(255) NOT NULL,
+	cuisine_type VARCHAR(255) NOT NULL,
+	owner_id UUID NOT NULL REFERENCES users(id),
+	status VARCHAR(50) NOT NULL DEFAULT 'pending',
+	short_description TEXT NOT NULL DEFAULT '',
+	opens_at VARCHAR(5) NOT NULL DEFAULT '',
+	closes_at VARCHAR(5) NOT NULL DEFAULT '',
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_restaurants_owner_id ON restaurants (owner_id);
+CREATE INDEX IF NOT EXISTS idx_restaurants_city_cuisine ON restaurants (city, cuisine_type);
+
+CREATE TABLE IF NOT EXISTS restaurant_images (
+	id SERIAL PRIMARY KEY,
+	restaurant_id INT NOT NULL REFERENCES restaurants(id) ON DELETE CASCADE,
+	image_url TEXT NOT NULL,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_restaurant_images_restaurant_id ON restaurant_images (restaurant_id);
@@ -0,0 +1,37 @@
+CREATE TABLE IF NOT EXISTS menu_uploads (
+	id SERIAL PRIMARY KEY,
+	restaurant_id INT NOT NULL REFERENCES restaurants(id) ON DELETE CASCADE,
+	image_url VARCHAR(500) NOT NULL,
+	original_filename VARCHAR(500) NOT NULL DEFAULT '',
+	status VARCHAR(50) NOT NULL DEFAULT 'MENU_UPLOADED',
+	raw_text TEXT NULL,
+	parsed_data JSONB NULL,
+	error_message TEXT NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE menu_uploads
+	ADD COLUMN IF NOT EXISTS original_filename VARCHAR(500) NOT NULL DEFAULT '';
+
+ALTER TABLE menu_uploads
+	ADD COLUMN IF NOT EXISTS raw_text TEXT NULL;
+
+ALTER TABLE menu_uploads
+	ADD COLUMN IF NOT EXISTS parsed_data JSONB NULL;
+
+ALTER TABLE menu_uploads
+	ADD COLUMN IF NOT EXISTS error_message TEXT NULL;
+
+-- Admin approval columns
+ALTER TABLE menu_uploads
+	ADD COLUMN IF NOT EXISTS approved_at TIMESTAMP NULL;
+
+ALTER TABLE menu_uploads
+	ADD COLUMN IF NOT EXISTS approved_by UUID NULL;
+
+ALTER TABLE menu_uploads
+	ADD COLUMN IF NOT EXISTS rejection_reason TEXT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_menu_uploads_restaurant_id ON menu_uploads (restaurant_id);
+CREATE INDEX IF NOT EXISTS idx_menu_uploads_status ON menu_uploads (status);
@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS deals (
+	id SERIAL PRIMARY KEY,
+	restaurant_id INT NOT NULL REFERENCES restaurants(id) ON DELETE CASCADE,
+	type VARCHAR(20) NOT NULL,
+	title VARCHAR(255) NOT NULL,
+	description TEXT NULL,
+	category VARCHAR(50) NULL,
+	discount_value DOUBLE PRECISION NOT NULL DEFAULT 0,
+	original_price DOUBLE PRECISION NULL,
+	final_price DOUBLE PRECISION NULL,
+	status VARCHAR(50) NOT NULL DEFAULT 'PENDING_APPROVAL',
+	suggested BOOLEAN NOT NULL DEFAULT FALSE,
+	approved_at TIMESTAMP NULL,
+	approved_by UUID NULL,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_deals_restaurant_id ON deals (restaurant_id);
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS competitive_snapshots;
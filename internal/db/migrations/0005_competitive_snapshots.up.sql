@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS competitive_snapshots (
+	id SERIAL PRIMARY KEY,
+	city VARCHAR(255) NOT NULL,
+	cuisine_type VARCHAR(255) NOT NULL,
+	avg_cost_for_two DOUBLE PRECISION NOT NULL DEFAULT 0,
+	median_cost_for_two DOUBLE PRECISION NOT NULL DEFAULT 0,
+	sample_size INT NOT NULL DEFAULT 0,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	UNIQUE (city, cuisine_type)
+);
@@ -0,0 +1,21 @@
+ALTER TABLE users
+	ADD COLUMN IF NOT EXISTS email_verified_at TIMESTAMPTZ NULL;
+
+-- Accounts that existed before verification was introduced are trusted.
+UPDATE users
+SET email_verified_at = created_at
+WHERE email_verified_at IS NULL;
+
+-- Single-use, expiring tokens for password reset and email verification.
+-- Only the sha256 of the token is stored.
+CREATE TABLE IF NOT EXISTS account_tokens (
+	id UUID PRIMARY KEY,
+	user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	purpose VARCHAR(30) NOT NULL,
+	token_hash CHAR(64) NOT NULL UNIQUE,
+	expires_at TIMESTAMPTZ NOT NULL,
+	used_at TIMESTAMPTZ NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_account_tokens_user_purpose ON account_tokens (user_id, purpose);
@@ -0,0 +1,9 @@
+-- Failed login counters keyed by "account:<email>" or "ip:<address>".
+CREATE TABLE IF NOT EXISTS login_attempts (
+	key VARCHAR(320) PRIMARY KEY,
+	failures INT NOT NULL DEFAULT 0,
+	last_failure_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	locked_until TIMESTAMPTZ NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_login_attempts_locked_until ON login_attempts (locked_until);
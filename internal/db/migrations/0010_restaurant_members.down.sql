@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS restaurant_invitations;
+DROP TABLE IF EXISTS restaurant_members;
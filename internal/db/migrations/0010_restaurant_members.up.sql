@@ -0,0 +1,37 @@
+-- Per-restaurant roles. restaurants.owner_id stays as the creator.
+CREATE TABLE IF NOT EXISTS restaurant_members (
+	restaurant_id INT NOT NULL REFERENCES restaurants(id) ON DELETE CASCADE,
+	user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	role VARCHAR(20) NOT NULL CHECK (role IN ('OWNER', 'MANAGER', 'STAFF')),
+	invited_by UUID NULL REFERENCES users(id) ON DELETE SET NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	PRIMARY KEY (restaurant_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_restaurant_members_user ON restaurant_members (user_id);
+
+-- Every existing restaurant keeps its owner.
+INSERT INTO restaurant_members (restaurant_id, user_id, role)
+SELECT id, owner_id, 'OWNER'
+FROM restaurants
+ON CONFLICT DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS restaurant_invitations (
+	id UUID PRIMARY KEY,
+	restaurant_id INT NOT NULL REFERENCES restaurants(id) ON DELETE CASCADE,
+	email VARCHAR(255) NOT NULL,
+	role VARCHAR(20) NOT NULL CHECK (role IN ('OWNER', 'MANAGER', 'STAFF')),
+	invited_by UUID NULL REFERENCES users(id) ON DELETE SET NULL,
+	status VARCHAR(20) NOT NULL DEFAULT 'PENDING',
+	expires_at TIMESTAMPTZ NOT NULL,
+	responded_at TIMESTAMPTZ NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- At most one open invitation per address and restaurant.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_restaurant_invitations_pending
+	ON restaurant_invitations (restaurant_id, lower(email))
+	WHERE status = 'PENDING';
+
+CREATE INDEX IF NOT EXISTS idx_restaurant_invitations_email ON restaurant_invitations (lower(email));
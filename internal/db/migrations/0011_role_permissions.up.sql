@@ -0,0 +1,29 @@
+-- Platform roles (users.role) and the permissions each one grants.
+CREATE TABLE IF NOT EXISTS roles (
+	name VARCHAR(50) PRIMARY KEY,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS role_permissions (
+	role VARCHAR(50) NOT NULL REFERENCES roles(name) ON DELETE CASCADE,
+	permission VARCHAR(100) NOT NULL,
+	PRIMARY KEY (role, permission)
+);
+
+-- Keep in sync with auth.DefaultRolePermissions.
+INSERT INTO roles (name) VALUES
+	('ADMIN'), ('RESTAURANT'), ('ANALYST'), ('REVIEWER')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role, permission) VALUES
+	('ADMIN', 'restaurants:read'),
+	('ADMIN', 'restaurants:approve'),
+	('ADMIN', 'deals:approve'),
+	('ADMIN', 'menus:review'),
+	('ADMIN', 'competition:recompute'),
+	('ADMIN', 'lockouts:manage'),
+	('RESTAURANT', 'restaurants:manage'),
+	('ANALYST', 'restaurants:read'),
+	('REVIEWER', 'restaurants:read'),
+	('REVIEWER', 'menus:review')
+ON CONFLICT (role, permission) DO NOTHING;
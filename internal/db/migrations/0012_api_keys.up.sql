@@ -0,0 +1,17 @@
+-- Machine-to-machine keys. The full key is shown once; only its prefix
+-- (for lookup and display) and sha256 are stored.
+CREATE TABLE IF NOT EXISTS api_keys (
+	id UUID PRIMARY KEY,
+	user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	restaurant_id INT NULL REFERENCES restaurants(id) ON DELETE CASCADE,
+	name VARCHAR(100) NOT NULL,
+	prefix VARCHAR(32) NOT NULL UNIQUE,
+	key_hash CHAR(64) NOT NULL,
+	scopes TEXT[] NOT NULL,
+	expires_at TIMESTAMPTZ NULL,
+	last_used_at TIMESTAMPTZ NULL,
+	revoked_at TIMESTAMPTZ NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_keys_user ON api_keys (user_id);
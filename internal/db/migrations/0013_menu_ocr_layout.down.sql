@@ -0,0 +1,3 @@
+ALTER TABLE menu_uploads DROP COLUMN IF EXISTS ocr_low_confidence;
+ALTER TABLE menu_uploads DROP COLUMN IF EXISTS ocr_confidence;
+ALTER TABLE menu_uploads DROP COLUMN IF EXISTS ocr_layout;
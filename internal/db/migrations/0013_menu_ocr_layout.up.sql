@@ -0,0 +1,10 @@
+-- Structured OCR output (pages, lines, boxes, word confidence) kept next
+-- to raw_text, plus the lines a reviewer should double-check.
+ALTER TABLE menu_uploads
+	ADD COLUMN IF NOT EXISTS ocr_layout JSONB NULL;
+
+ALTER TABLE menu_uploads
+	ADD COLUMN IF NOT EXISTS ocr_confidence DOUBLE PRECISION NULL;
+
+ALTER TABLE menu_uploads
+	ADD COLUMN IF NOT EXISTS ocr_low_confidence JSONB NULL;
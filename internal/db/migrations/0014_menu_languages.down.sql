@@ -0,0 +1,3 @@
+ALTER TABLE menu_uploads DROP COLUMN IF EXISTS ocr_language;
+ALTER TABLE menu_uploads DROP COLUMN IF EXISTS language_hint;
+ALTER TABLE restaurants DROP COLUMN IF EXISTS menu_language;
@@ -0,0 +1,11 @@
+-- Tesseract language hints ("hin+eng"). The upload hint wins over the
+-- restaurant default; with neither, the script is detected per upload.
+ALTER TABLE restaurants
+	ADD COLUMN IF NOT EXISTS menu_language VARCHAR(64) NULL;
+
+ALTER TABLE menu_uploads
+	ADD COLUMN IF NOT EXISTS language_hint VARCHAR(64) NULL;
+
+-- The languages the upload was actually OCRed with.
+ALTER TABLE menu_uploads
+	ADD COLUMN IF NOT EXISTS ocr_language VARCHAR(64) NULL;
@@ -0,0 +1,3 @@
+-- Preprocessed image the OCR actually ran on, for side-by-side review.
+ALTER TABLE menu_uploads
+	ADD COLUMN IF NOT EXISTS processed_image_key VARCHAR(500) NULL;
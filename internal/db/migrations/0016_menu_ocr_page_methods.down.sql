@@ -0,0 +1 @@
+ALTER TABLE menu_uploads DROP COLUMN IF EXISTS ocr_page_methods;
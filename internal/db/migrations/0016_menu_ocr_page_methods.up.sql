@@ -0,0 +1,4 @@
+-- Per page: whether the text came from the PDF's text layer or from OCR,
+-- e.g. [{"page": 1, "method": "text"}, {"page": 2, "method": "ocr"}].
+ALTER TABLE menu_uploads
+	ADD COLUMN IF NOT EXISTS ocr_page_methods JSONB NULL;
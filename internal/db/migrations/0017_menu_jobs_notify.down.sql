@@ -0,0 +1,2 @@
+DROP TRIGGER IF EXISTS menu_uploads_notify_jobs ON menu_uploads;
+DROP FUNCTION IF EXISTS notify_menu_jobs();
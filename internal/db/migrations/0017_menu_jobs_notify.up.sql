@@ -0,0 +1,17 @@
+-- Wake the OCR and LLM workers (LISTEN menu_jobs) as soon as an upload is
+-- ready for them. The payload is the status the upload moved to.
+CREATE OR REPLACE FUNCTION notify_menu_jobs() RETURNS trigger AS $$
+BEGIN
+	IF NEW.status IN ('MENU_UPLOADED', 'OCR_DONE')
+	   AND (TG_OP = 'INSERT' OR OLD.status IS DISTINCT FROM NEW.status) THEN
+		PERFORM pg_notify('menu_jobs', NEW.status);
+	END IF;
+	RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS menu_uploads_notify_jobs ON menu_uploads;
+
+CREATE TRIGGER menu_uploads_notify_jobs
+	AFTER INSERT OR UPDATE OF status ON menu_uploads
+	FOR EACH ROW EXECUTE FUNCTION notify_menu_jobs();
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_menu_uploads_lease;
+ALTER TABLE menu_uploads DROP COLUMN IF EXISTS attempts;
+ALTER TABLE menu_uploads DROP COLUMN IF EXISTS lease_expires_at;
+ALTER TABLE menu_uploads DROP COLUMN IF EXISTS claimed_by;
@@ -0,0 +1,14 @@
+-- Workers hold a renewable lease on the uploads they process. The reaper
+-- hands expired leases back to the queue and gives up after too many
+-- interrupted attempts.
+ALTER TABLE menu_uploads
+	ADD COLUMN IF NOT EXISTS claimed_by VARCHAR(255) NULL;
+
+ALTER TABLE menu_uploads
+	ADD COLUMN IF NOT EXISTS lease_expires_at TIMESTAMPTZ NULL;
+
+ALTER TABLE menu_uploads
+	ADD COLUMN IF NOT EXISTS attempts INT NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_menu_uploads_lease ON menu_uploads (lease_expires_at)
+	WHERE status IN ('OCR_PROCESSING', 'PARSING_LLM');
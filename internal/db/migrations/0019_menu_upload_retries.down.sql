@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS menu_upload_errors;
+ALTER TABLE menu_uploads DROP COLUMN IF EXISTS next_attempt_at;
+ALTER TABLE menu_uploads DROP COLUMN IF EXISTS retries;
@@ -0,0 +1,21 @@
+-- Transient OCR and LLM failures are retried automatically with backoff.
+-- An upload waiting for its retry keeps its queue status but is not
+-- claimed before next_attempt_at.
+ALTER TABLE menu_uploads
+	ADD COLUMN IF NOT EXISTS retries INT NOT NULL DEFAULT 0;
+
+ALTER TABLE menu_uploads
+	ADD COLUMN IF NOT EXISTS next_attempt_at TIMESTAMPTZ NULL;
+
+-- Every failed attempt, for the admin dead-letter view.
+CREATE TABLE IF NOT EXISTS menu_upload_errors (
+	id SERIAL PRIMARY KEY,
+	menu_upload_id INT NOT NULL REFERENCES menu_uploads(id) ON DELETE CASCADE,
+	stage VARCHAR(20) NOT NULL,
+	error TEXT NOT NULL,
+	transient BOOLEAN NOT NULL,
+	attempt INT NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_menu_upload_errors_upload ON menu_upload_errors (menu_upload_id, created_at);
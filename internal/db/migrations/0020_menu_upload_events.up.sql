@@ -0,0 +1,14 @@
+-- Append-only history of every status change of a menu upload.
+-- duration_ms is the time the upload spent in from_status.
+CREATE TABLE IF NOT EXISTS menu_upload_events (
+	id SERIAL PRIMARY KEY,
+	menu_upload_id INT NOT NULL REFERENCES menu_uploads(id) ON DELETE CASCADE,
+	from_status VARCHAR(50) NULL,
+	to_status VARCHAR(50) NOT NULL,
+	worker_id VARCHAR(255) NULL,
+	duration_ms BIGINT NULL,
+	error TEXT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_menu_upload_events_upload ON menu_upload_events (menu_upload_id, created_at);
@@ -0,0 +1,5 @@
+-- Long menus are parsed by the LLM in chunks. Each chunk's outcome is kept
+-- so a retry only re-sends the chunks that failed, and reviewers can see
+-- when a menu was parsed from partial results.
+ALTER TABLE menu_uploads
+	ADD COLUMN IF NOT EXISTS llm_chunks JSONB NULL;
@@ -0,0 +1,6 @@
+-- Requeueing dead letters and reparsing menus reruns the pipeline across
+-- restaurants, so it gets its own permission, granted to admins only.
+-- Keep in sync with auth.DefaultRolePermissions.
+INSERT INTO role_permissions (role, permission) VALUES
+	('ADMIN', 'pipeline:manage')
+ON CONFLICT (role, permission) DO NOTHING;
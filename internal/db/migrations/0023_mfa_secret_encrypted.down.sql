@@ -0,0 +1,4 @@
+-- Encrypted secrets do not fit VARCHAR(64); reset two-factor auth for
+-- those users (admin reset-mfa) before migrating down.
+ALTER TABLE user_mfa
+	ALTER COLUMN secret TYPE VARCHAR(64);
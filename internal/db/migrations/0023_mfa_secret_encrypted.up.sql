@@ -0,0 +1,5 @@
+-- TOTP secrets are now stored encrypted (AES-GCM under MFA_ENCRYPTION_KEY),
+-- which does not fit the old column. Secrets stored in plain text are
+-- encrypted on next use, or all at once with: admin seal-mfa-secrets
+ALTER TABLE user_mfa
+	ALTER COLUMN secret TYPE TEXT;
@@ -9,7 +9,25 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// ConnectPostgres opens the pool and applies any pending migrations.
 func ConnectPostgres() *pgxpool.Pool {
+	db := OpenPostgres()
+
+	migrator, err := NewMigrator(db)
+	if err != nil {
+		log.Fatal("Failed to load migrations:", err)
+	}
+
+	if _, err := migrator.Up(context.Background()); err != nil {
+		log.Fatal("Failed to apply migrations:", err)
+	}
+
+	log.Println("✅ Schema up to date")
+	return db
+}
+
+// OpenPostgres opens the pool without touching the schema.
+func OpenPostgres() *pgxpool.Pool {
 	dsn := os.Getenv("DATABASE_URL")
 	if dsn == "" {
 		log.Fatal("DATABASE_URL not set")
@@ -34,79 +52,5 @@ func ConnectPostgres() *pgxpool.Pool {
 	}
 
 	log.Println("✅ Connected to Aiven PostgreSQL")
-
-	// Initialize schema
-	if err := initSchema(db); err != nil {
-		log.Fatal("Failed to initialize schema:", err)
-	}
-
 	return db
 }
-
-// initSchema creates or updates the database schema
-func initSchema(db *pgxpool.Pool) error {
-	ctx := context.Background()
-
-	// -------------------------------
-	// USERS
-	// -------------------------------
-	userTableSQL := `
-		CREATE TABLE IF NOT EXISTS users (
-			id UUID PRIMARY KEY,
-			name VARCHAR(255) NOT NULL,
-			email VARCHAR(255) UNIQUE NOT NULL,
-			password VARCHAR(255) NOT NULL,
-			role VARCHAR(50) NOT NULL DEFAULT 'RESTAURANT',
-			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-		)
-	`
-	if _, err := db.Exec(ctx, userTableSQL); err != nil {
-		return err
-	}
-
-	addRoleColumnSQL := `
-		ALTER TABLE users
-		ADD COLUMN IF NOT EXISTS role VARCHAR(50) NOT NULL DEFAULT 'RESTAURANT'
-	`
-	if _, err := db.Exec(ctx, addRoleColumnSQL); err != nil {
-		log.Println("Note: role column may already exist")
-	}
-
-	// -------------------------------
-	// MENU UPLOADS
-	// -------------------------------
-	menuUploadsSQL := `
-		CREATE TABLE IF NOT EXISTS menu_uploads (
-			id SERIAL PRIMARY KEY,
-			restaurant_id UUID NOT NULL,
-			image_url VARCHAR(500) NOT NULL,
-			status VARCHAR(50) NOT NULL DEFAULT 'MENU_UPLOADED',
-			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			FOREIGN KEY (restaurant_id) REFERENCES users(id)
-		)
-	`
-	if _, err := db.Exec(ctx, menuUploadsSQL); err != nil {
-		return err
-	}
-
-	// -------------------------------
-	// ADMIN APPROVAL COLUMNS (FINAL PHASE)
-	// -------------------------------
-	approvalColumnsSQL := `
-		ALTER TABLE menu_uploads
-		ADD COLUMN IF NOT EXISTS approved_at TIMESTAMP NULL;
-
-		ALTER TABLE menu_uploads
-		ADD COLUMN IF NOT EXISTS approved_by UUID NULL;
-
-		ALTER TABLE menu_uploads
-		ADD COLUMN IF NOT EXISTS rejection_reason TEXT NULL;
-	`
-	if _, err := db.Exec(ctx, approvalColumnsSQL); err != nil {
-		return err
-	}
-
-	log.Println("✅ Schema initialized successfully")
-	return nil
-}
@@ -11,17 +11,20 @@ import (
 type Service struct {
 	repo              *Repository
 	restaurantReader  core.RestaurantReader
+	access            core.RestaurantAccess
 	competitionRepo   *competition.Repository
 }
 
 func NewService(
 	repo *Repository,
 	restaurantReader core.RestaurantReader,
+	access core.RestaurantAccess,
 	competitionRepo *competition.Repository,
 ) *Service {
 	return &Service{
 		repo:             repo,
 		restaurantReader: restaurantReader,
+		access:           access,
 		competitionRepo:  competitionRepo,
 	}
 }
@@ -31,8 +34,8 @@ func (s *Service) GetDealSuggestion(
 	userID string,
 ) (*DealSuggestion, error) {
 
-	// 🔒 Ownership check
-	ok, err := s.restaurantReader.IsOwner(ctx, restaurantID, userID)
+	// 🔒 Membership check
+	ok, err := s.access.CanAccess(ctx, restaurantID, userID, core.ActionViewRestaurant)
 	if err != nil || !ok {
 		return nil, errors.New("unauthorized")
 	}
@@ -152,8 +155,8 @@ func (s *Service) CreateDeal(
 	deal *Deal,
 ) error {
 
-	// 🔒 Ownership check
-	ok, err := s.restaurantReader.IsOwner(ctx, deal.RestaurantID, userID)
+	// 🔒 Membership check
+	ok, err := s.access.CanAccess(ctx, deal.RestaurantID, userID, core.ActionManageDeals)
 	if err != nil || !ok {
 		return errors.New("unauthorized")
 	}
@@ -189,7 +192,7 @@ func (s *Service) DeleteDeal(
 		return err
 	}
 
-	ok, err := s.restaurantReader.IsOwner(ctx, deal.RestaurantID, userID)
+	ok, err := s.access.CanAccess(ctx, deal.RestaurantID, userID, core.ActionManageDeals)
 	if err != nil || !ok {
 		return errors.New("unauthorized")
 	}
@@ -204,7 +207,7 @@ func (s *Service) GetRestaurantDeals(
 	userID string,
 ) ([]*Deal, error) {
 
-	ok, err := s.restaurantReader.IsOwner(ctx, restaurantID, userID)
+	ok, err := s.access.CanAccess(ctx, restaurantID, userID, core.ActionViewRestaurant)
 	if err != nil || !ok {
 		return nil, errors.New("unauthorized")
 	}
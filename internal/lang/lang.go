@@ -0,0 +1,123 @@
+// Package lang holds the menu languages we OCR and the helpers to turn
+// Indian-script text into something searchable.
+package lang
+
+import (
+	"errors"
+	"strings"
+)
+
+var ErrUnsupportedLanguage = errors.New("unsupported language, use tesseract codes like hin+eng")
+
+// English is always the fallback; most Indian menus mix it in.
+const English = "eng"
+
+const maxHintLanguages = 4
+
+// languageNames lists the Tesseract language codes we install.
+var languageNames = map[string]string{
+	"eng": "English",
+	"hin": "Hindi",
+	"mar": "Marathi",
+	"tam": "Tamil",
+	"tel": "Telugu",
+	"kan": "Kannada",
+	"mal": "Malayalam",
+	"ben": "Bengali",
+	"guj": "Gujarati",
+	"pan": "Punjabi",
+	"ori": "Odia",
+	"urd": "Urdu",
+}
+
+// scriptLanguages maps a Tesseract OSD script name to the language we OCR
+// it with. Devanagari menus are far more often Hindi than Marathi; a
+// restaurant can set mar+eng explicitly.
+var scriptLanguages = map[string]string{
+	"Latin":      "eng",
+	"Devanagari": "hin",
+	"Tamil":      "tam",
+	"Telugu":     "tel",
+	"Kannada":    "kan",
+	"Malayalam":  "mal",
+	"Bengali":    "ben",
+	"Gujarati":   "guj",
+	"Gurmukhi":   "pan",
+	"Oriya":      "ori",
+	"Arabic":     "urd",
+}
+
+// ParseHint normalises a hint such as "HIN + eng" to "hin+eng". An empty
+// hint returns "".
+func ParseHint(hint string) (string, error) {
+	hint = strings.TrimSpace(hint)
+	if hint == "" {
+		return "", nil
+	}
+
+	var codes []string
+	seen := map[string]bool{}
+	for _, part := range strings.Split(hint, "+") {
+		code := strings.ToLower(strings.TrimSpace(part))
+		if _, ok := languageNames[code]; !ok {
+			return "", ErrUnsupportedLanguage
+		}
+		if !seen[code] {
+			seen[code] = true
+			codes = append(codes, code)
+		}
+	}
+
+	if len(codes) > maxHintLanguages {
+		return "", ErrUnsupportedLanguage
+	}
+	return strings.Join(codes, "+"), nil
+}
+
+// ForScript returns the languages to OCR a detected script with, always
+// including English. Unknown scripts fall back to English.
+func ForScript(script string) string {
+	code, ok := scriptLanguages[script]
+	if !ok || code == English {
+		return English
+	}
+	return code + "+" + English
+}
+
+// ForText picks languages for text that is already machine-readable (a
+// PDF text layer) from the Indian script it uses most.
+func ForText(text string) string {
+	counts := map[string]int{}
+	for _, r := range text {
+		for _, b := range indicBlocks {
+			if r >= b.base && r < b.base+0x80 {
+				counts[b.script]++
+				break
+			}
+		}
+	}
+
+	best, bestCount := "Latin", 0
+	for script, n := range counts {
+		if n > bestCount || (n == bestCount && script < best) {
+			best, bestCount = script, n
+		}
+	}
+	return ForScript(best)
+}
+
+// Names returns the display names of a "+"-joined language list.
+func Names(languages string) []string {
+	var names []string
+	for _, code := range strings.Split(languages, "+") {
+		if name, ok := languageNames[code]; ok {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// IsEnglishOnly reports whether languages is empty or just English.
+func IsEnglishOnly(languages string) bool {
+	return languages == "" || languages == English
+}
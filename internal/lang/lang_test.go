@@ -0,0 +1,89 @@
+package lang
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseHint(t *testing.T) {
+	cases := map[string]string{
+		"":             "",
+		"  ":           "",
+		"eng":          "eng",
+		"HIN + eng":    "hin+eng",
+		"mar+eng+mar":  "mar+eng",
+		"tam+hin+eng ": "tam+hin+eng",
+	}
+	for in, want := range cases {
+		got, err := ParseHint(in)
+		if err != nil {
+			t.Fatalf("ParseHint(%q): unexpected error: %v", in, err)
+		}
+		if got != want {
+			t.Errorf("ParseHint(%q) = %q, want %q", in, got, want)
+		}
+	}
+
+	for _, in := range []string{"hindi", "hin+", "fra+eng", "hin;eng", "hin+mar+tam+tel+eng"} {
+		if _, err := ParseHint(in); !errors.Is(err, ErrUnsupportedLanguage) {
+			t.Errorf("ParseHint(%q): expected ErrUnsupportedLanguage, got %v", in, err)
+		}
+	}
+}
+
+func TestForScript(t *testing.T) {
+	cases := map[string]string{
+		"Devanagari": "hin+eng",
+		"Tamil":      "tam+eng",
+		"Latin":      "eng",
+		"Han":        "eng",
+		"":           "eng",
+	}
+	for script, want := range cases {
+		if got := ForScript(script); got != want {
+			t.Errorf("ForScript(%q) = %q, want %q", script, got, want)
+		}
+	}
+}
+
+func TestForText(t *testing.T) {
+	if got := ForText("Paneer Tikka पनीर टिक्का ₹280"); got != "hin+eng" {
+		t.Errorf("expected hin+eng, got %q", got)
+	}
+	if got := ForText("Masala Dosa 120"); got != "eng" {
+		t.Errorf("expected eng, got %q", got)
+	}
+}
+
+func TestNames(t *testing.T) {
+	names := Names("hin+eng")
+	if len(names) != 2 || names[0] != "Hindi" || names[1] != "English" {
+		t.Fatalf("unexpected names %v", names)
+	}
+}
+
+func TestTransliterate(t *testing.T) {
+	cases := map[string]string{
+		"पनीर टिक्का":  "paneer tikka",
+		"बिरयानी":      "biryani",
+		"गुलाब जामुन":  "gulab jamun",
+		"मसाला डोसा":   "masala dosa",
+		"ಮಸಾಲ ದೋಸೆ":    "masala dose",
+		"चाय ₹२०":      "chay ₹20",
+		"Paneer Tikka": "paneer tikka",
+	}
+	for in, want := range cases {
+		if got := Transliterate(in); got != want {
+			t.Errorf("Transliterate(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestHasIndic(t *testing.T) {
+	if !HasIndic("Paneer पनीर") {
+		t.Error("expected Devanagari to be detected")
+	}
+	if HasIndic("Paneer Tikka ₹280") {
+		t.Error("did not expect Latin text to be detected")
+	}
+}
@@ -0,0 +1,210 @@
+package lang
+
+import (
+	"strings"
+	"unicode"
+)
+
+// The Brahmic Unicode blocks share one layout (inherited from ISCII): the
+// same offset is the same letter in every script. That lets a single table
+// romanise all of them.
+var indicBlocks = []struct {
+	base   rune
+	script string // Tesseract OSD name
+	// schwaDeletion is true for scripts whose inherent "a" is usually
+	// silent at the end of a word and between some consonants.
+	schwaDeletion bool
+}{
+	{0x0900, "Devanagari", true},
+	{0x0980, "Bengali", true},
+	{0x0A00, "Gurmukhi", true},
+	{0x0A80, "Gujarati", true},
+	{0x0B00, "Oriya", false},
+	{0x0B80, "Tamil", false},
+	{0x0C00, "Telugu", false},
+	{0x0C80, "Kannada", false},
+	{0x0D00, "Malayalam", false},
+}
+
+const (
+	offCandrabindu = 0x01
+	offAnusvara    = 0x02
+	offVisarga     = 0x03
+	offNukta       = 0x3C
+	offVirama      = 0x4D
+)
+
+var consonants = map[rune]string{
+	0x15: "k", 0x16: "kh", 0x17: "g", 0x18: "gh", 0x19: "n",
+	0x1A: "ch", 0x1B: "chh", 0x1C: "j", 0x1D: "jh", 0x1E: "n",
+	0x1F: "t", 0x20: "th", 0x21: "d", 0x22: "dh", 0x23: "n",
+	0x24: "t", 0x25: "th", 0x26: "d", 0x27: "dh", 0x28: "n", 0x29: "n",
+	0x2A: "p", 0x2B: "ph", 0x2C: "b", 0x2D: "bh", 0x2E: "m",
+	0x2F: "y", 0x30: "r", 0x31: "r", 0x32: "l", 0x33: "l", 0x34: "zh",
+	0x35: "v", 0x36: "sh", 0x37: "sh", 0x38: "s", 0x39: "h",
+	// Precomposed nukta letters
+	0x58: "q", 0x59: "kh", 0x5A: "gh", 0x5B: "z", 0x5C: "r", 0x5D: "rh", 0x5E: "f", 0x5F: "y",
+}
+
+// nuktaForms are the sounds a nukta turns a consonant into.
+var nuktaForms = map[rune]string{
+	0x15: "q", 0x16: "kh", 0x17: "gh", 0x1C: "z", 0x21: "r", 0x22: "rh", 0x2B: "f",
+}
+
+// Malayalam chillu letters: consonants with no vowel.
+var chillus = map[rune]string{
+	0x7A: "n", 0x7B: "n", 0x7C: "r", 0x7D: "l", 0x7E: "l", 0x7F: "k",
+}
+
+// Independent vowels and vowel signs. Long vowels are kept doubled here
+// and simplified when rendering.
+var vowels = map[rune]string{
+	0x05: "a", 0x06: "aa", 0x07: "i", 0x08: "ii", 0x09: "u", 0x0A: "uu",
+	0x0B: "ri", 0x0C: "li", 0x0D: "e", 0x0E: "e", 0x0F: "e", 0x10: "ai",
+	0x11: "o", 0x12: "o", 0x13: "o", 0x14: "au",
+}
+
+var vowelSigns = map[rune]string{
+	0x3E: "aa", 0x3F: "i", 0x40: "ii", 0x41: "u", 0x42: "uu", 0x43: "ri", 0x44: "ri",
+	0x45: "e", 0x46: "e", 0x47: "e", 0x48: "ai", 0x49: "o", 0x4A: "o", 0x4B: "o", 0x4C: "au",
+}
+
+type syllable struct {
+	off       rune // consonant offset, 0 for a bare vowel
+	consonant string
+	vowel     string
+	inherent  bool // vowel is the implicit "a"
+	suffix    string
+}
+
+// HasIndic reports whether s contains any character from an Indian script.
+func HasIndic(s string) bool {
+	for _, r := range s {
+		if _, _, ok := indicOffset(r); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Transliterate romanises Indian-script text into lowercase ASCII that
+// matches how diners usually type dish names ("पनीर टिक्का" → "paneer
+// tikka"). It is for search, not a scholarly transliteration. Text in
+// other scripts is passed through, lowercased.
+func Transliterate(s string) string {
+	var out strings.Builder
+	var word []syllable
+	schwa := false
+
+	flush := func() {
+		out.WriteString(renderWord(word, schwa))
+		word = word[:0]
+	}
+
+	for _, r := range s {
+		off, del, ok := indicOffset(r)
+		if !ok {
+			flush()
+			if r == 0x200C || r == 0x200D { // zero-width (non-)joiner
+				continue
+			}
+			out.WriteRune(unicode.ToLower(r))
+			continue
+		}
+		schwa = del
+
+		switch {
+		case consonants[off] != "":
+			word = append(word, syllable{off: off, consonant: consonants[off], vowel: "a", inherent: true})
+		case chillus[off] != "":
+			word = append(word, syllable{off: off, consonant: chillus[off]})
+		case vowels[off] != "":
+			word = append(word, syllable{vowel: vowels[off]})
+		case vowelSigns[off] != "" && len(word) > 0:
+			last := &word[len(word)-1]
+			last.vowel, last.inherent = vowelSigns[off], false
+		case off == offVirama && len(word) > 0:
+			last := &word[len(word)-1]
+			last.vowel, last.inherent = "", false
+		case off == offNukta && len(word) > 0:
+			last := &word[len(word)-1]
+			if form, ok := nuktaForms[last.off]; ok {
+				last.consonant = form
+			}
+		case off == offCandrabindu || off == offAnusvara || off == offVisarga:
+			suffix := "n"
+			if off == offVisarga {
+				suffix = "h"
+			}
+			if len(word) > 0 {
+				word[len(word)-1].suffix += suffix
+			} else {
+				out.WriteString(suffix)
+			}
+		case off >= 0x66 && off <= 0x6F:
+			flush()
+			out.WriteRune('0' + (off - 0x66))
+		case off == 0x64 || off == 0x65: // danda
+			flush()
+			out.WriteRune('.')
+		}
+	}
+	flush()
+
+	return out.String()
+}
+
+func indicOffset(r rune) (rune, bool, bool) {
+	for _, b := range indicBlocks {
+		if r >= b.base && r < b.base+0x80 {
+			return r - b.base, b.schwaDeletion, true
+		}
+	}
+	return 0, false, false
+}
+
+func renderWord(word []syllable, schwaDeletion bool) string {
+	n := len(word)
+	if n == 0 {
+		return ""
+	}
+
+	if schwaDeletion && n > 1 {
+		// Word-final: "पनीर" is "paneer", not "paneera".
+		if last := &word[n-1]; last.inherent && last.suffix == "" {
+			last.vowel, last.inherent = "", false
+		}
+		// Medial, in a vowel–C(a)–CV context: "बिरयानी" is "biryani".
+		for i := 1; i < n-1; i++ {
+			s := &word[i]
+			if s.inherent && s.suffix == "" &&
+				word[i-1].vowel != "" &&
+				word[i+1].consonant != "" && word[i+1].vowel != "" {
+				s.vowel, s.inherent = "", false
+			}
+		}
+	}
+
+	var b strings.Builder
+	for i, s := range word {
+		b.WriteString(s.consonant)
+		switch s.vowel {
+		case "aa":
+			b.WriteString("a")
+		case "ii":
+			// Medial long i is usually spelt "ee" (paneer, keema) and a
+			// final one "i" (makhani, biryani).
+			if i < n-1 {
+				b.WriteString("ee")
+			} else {
+				b.WriteString("i")
+			}
+		case "uu":
+			b.WriteString("u")
+		default:
+			b.WriteString(s.vowel)
+		}
+		b.WriteString(s.suffix)
+	}
+	return b.String()
+}
@@ -1,7 +1,83 @@
 package llm
 
-import "context"
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+)
 
+// Client is a model provider. See ParseMenu for turning OCR text into a
+// menu with one.
 type Client interface {
-	ParseOCR(ctx context.Context, ocrText string) (string, error)
+	// Generate sends prompt and returns the reply, asking the provider to
+	// keep it to JSON matching schema.
+	Generate(ctx context.Context, prompt string, schema *Schema) (*Reply, error)
+}
+
+// Reply is a model's answer and what producing it took.
+type Reply struct {
+	Text         string
+	Provider     string
+	Model        string // as reported by the provider, else as requested
+	InputTokens  int
+	OutputTokens int
+	Truncated    bool // stopped at MaxOutputTokens, so Text is cut short
+}
+
+// APIError is a non-200 response from the model provider.
+type APIError struct {
+	Provider   string
+	StatusCode int
+	Body       string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s api error (%d): %s", e.Provider, e.StatusCode, e.Body)
+}
+
+// Temporary reports whether the same request may succeed later: rate
+// limits and server-side failures.
+func (e *APIError) Temporary() bool {
+	return e.StatusCode == http.StatusTooManyRequests ||
+		e.StatusCode == http.StatusRequestTimeout ||
+		e.StatusCode >= 500
+}
+
+// MaxInputChars caps the OCR text sent to any provider in one call. Longer
+// menus are split into chunks by the pipeline before they get here.
+const MaxInputChars = 12000
+
+// MaxOutputTokens caps a reply. The JSON for a menu item runs to about a
+// token per character of the OCR line it came from.
+const MaxOutputTokens = 8192
+
+// MaxChunkChars is the most OCR text to send in one call for the reply to
+// fit in MaxOutputTokens, with a quarter left as margin.
+const MaxChunkChars = MaxOutputTokens * 3 / 4
+
+func truncateOCRText(text string) string {
+	if len(text) <= MaxInputChars {
+		return text
+	}
+	// Indian scripts are multi-byte; don't cut a character in half.
+	cut := MaxInputChars
+	for cut > 0 && !utf8.RuneStart(text[cut]) {
+		cut--
+	}
+	return text[:cut]
+}
+
+// stripFence drops the markdown fence local models often wrap JSON in
+// despite the prompt.
+func stripFence(text string) string {
+	output := strings.TrimSpace(text)
+	if strings.HasPrefix(output, "```") {
+		output = strings.TrimPrefix(output, "```json")
+		output = strings.TrimPrefix(output, "```")
+		output = strings.TrimSuffix(output, "```")
+		output = strings.TrimSpace(output)
+	}
+	return output
 }
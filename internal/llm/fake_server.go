@@ -0,0 +1,218 @@
+package llm
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// FakeServer is a stand-in model provider for offline tests and local
+// runs. It speaks both Gemini's generateContent and the OpenAI
+// chat-completions protocol and answers every prompt with Reply.
+type FakeServer struct {
+	*httptest.Server
+
+	reply       func(prompt string) string
+	failWith    atomic.Int64
+	outputLimit atomic.Int64
+	calls       atomic.Int64
+	last        atomic.Value // []byte
+}
+
+// NewFakeServer starts a server answering with reply; nil uses
+// FakeMenuReply. Close it when done.
+func NewFakeServer(reply func(prompt string) string) *FakeServer {
+	if reply == nil {
+		reply = FakeMenuReply
+	}
+	f := &FakeServer{reply: reply}
+	f.Server = httptest.NewServer(http.HandlerFunc(f.handle))
+	return f
+}
+
+// FailWith makes every later call fail with status; 0 restores replies.
+func (f *FakeServer) FailWith(status int) {
+	f.failWith.Store(int64(status))
+}
+
+// LimitOutput cuts replies longer than tokens short and reports them as
+// stopped at the output limit, as a provider does at its max tokens; 0
+// removes the limit.
+func (f *FakeServer) LimitOutput(tokens int) {
+	f.outputLimit.Store(int64(tokens))
+}
+
+// Calls counts the requests served so far.
+func (f *FakeServer) Calls() int {
+	return int(f.calls.Load())
+}
+
+// LastRequest decodes the body of the latest request, to check what a
+// client sent; nil before any.
+func (f *FakeServer) LastRequest() map[string]any {
+	body, _ := f.last.Load().([]byte)
+	var req map[string]any
+	_ = json.Unmarshal(body, &req)
+	return req
+}
+
+func (f *FakeServer) handle(w http.ResponseWriter, r *http.Request) {
+	f.calls.Add(1)
+
+	if status := int(f.failWith.Load()); status != 0 {
+		http.Error(w, `{"error":{"message":"fake failure"}}`, status)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	f.last.Store(body)
+
+	var req struct {
+		// OpenAI
+		Model    string `json:"model"`
+		Messages []struct {
+			Content string `json:"content"`
+		} `json:"messages"`
+		// Gemini
+		Contents []struct {
+			Parts []struct {
+				Text string `json:"text"`
+			} `json:"parts"`
+		} `json:"contents"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	switch {
+	case strings.HasSuffix(r.URL.Path, "/chat/completions") && len(req.Messages) > 0:
+		prompt := req.Messages[len(req.Messages)-1].Content
+		text, cut := f.limit(f.reply(prompt))
+		finish := "stop"
+		if cut {
+			finish = "length"
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"model": req.Model,
+			"choices": []map[string]any{
+				{
+					"message":       map[string]string{"role": "assistant", "content": text},
+					"finish_reason": finish,
+				},
+			},
+			"usage": map[string]int{
+				"prompt_tokens":     fakeTokens(prompt),
+				"completion_tokens": fakeTokens(text),
+			},
+		})
+	case strings.HasSuffix(r.URL.Path, ":generateContent") && len(req.Contents) > 0 && len(req.Contents[0].Parts) > 0:
+		prompt := req.Contents[0].Parts[0].Text
+		text, cut := f.limit(f.reply(prompt))
+		finish := "STOP"
+		if cut {
+			finish = "MAX_TOKENS"
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"candidates": []map[string]any{
+				{
+					"content":      map[string]any{"parts": []map[string]string{{"text": text}}},
+					"finishReason": finish,
+				},
+			},
+			"usageMetadata": map[string]int{
+				"promptTokenCount":     fakeTokens(prompt),
+				"candidatesTokenCount": fakeTokens(text),
+			},
+		})
+	default:
+		http.Error(w, "unsupported request", http.StatusNotFound)
+	}
+}
+
+// limit cuts text to the output limit, reporting whether it did.
+func (f *FakeServer) limit(text string) (string, bool) {
+	tokens := int(f.outputLimit.Load())
+	if tokens == 0 || fakeTokens(text) <= tokens {
+		return text, false
+	}
+	return text[:tokens*4], true
+}
+
+// fakeTokens approximates a token count as one per four bytes.
+func fakeTokens(text string) int {
+	return (len(text) + 3) / 4
+}
+
+// OCRText returns the OCR text embedded in a BuildOCRParsePrompt prompt.
+func OCRText(prompt string) string {
+	const marker = "OCR TEXT STARTS BELOW:\n"
+	if i := strings.LastIndex(prompt, marker); i >= 0 {
+		return prompt[i+len(marker):]
+	}
+	return prompt
+}
+
+var (
+	fakeItemLine = regexp.MustCompile(`^(.*?\pL.*?)[\s.:₹-]+(\d+(?:\.\d+)?)$`)
+	fakeTaxLine  = regexp.MustCompile(`(?i)(gst|tax)\D*(\d+(?:\.\d+)?)\s*%`)
+)
+
+// fakeCategories guesses an item's category from its name.
+var fakeCategories = []struct {
+	category string
+	words    []string
+}{
+	{"drink", []string{"drink", "lassi", "chai", "tea", "coffee", "juice", "soda", "water"}},
+	{"dessert", []string{"jamun", "kulfi", "ice cream", "halwa", "kheer", "rasgulla", "dessert"}},
+	{"starter", []string{"tikka", "soup", "pakora", "samosa", "kebab", "starter", "chaat"}},
+}
+
+// FakeMenuReply answers like a well-behaved model, reading "Name 220"
+// lines of the prompt's OCR text as items and "GST 5%" as tax.
+func FakeMenuReply(prompt string) string {
+	result := ParsedOCRResult{Items: []ParsedItem{}}
+
+	for _, line := range strings.Split(OCRText(prompt), "\n") {
+		line = strings.TrimSpace(line)
+		if m := fakeTaxLine.FindStringSubmatch(line); m != nil {
+			result.TaxPercent, _ = strconv.ParseFloat(m[2], 64)
+			continue
+		}
+
+		m := fakeItemLine.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		price, _ := strconv.ParseFloat(m[2], 64)
+		name := strings.TrimSpace(m[1])
+
+		category := "main_course"
+		lower := strings.ToLower(name)
+	guess:
+		for _, c := range fakeCategories {
+			for _, w := range c.words {
+				if strings.Contains(lower, w) {
+					category = c.category
+					break guess
+				}
+			}
+		}
+
+		result.Items = append(result.Items, ParsedItem{Name: name, Category: category, Price: price})
+	}
+
+	out, _ := json.Marshal(result)
+	return string(out)
+}
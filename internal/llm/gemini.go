@@ -13,40 +13,39 @@ import (
 	"time"
 )
 
+const defaultGeminiBaseURL = "https://generativelanguage.googleapis.com/v1beta"
+
 type GeminiClient struct {
-	apiKey string
-	model  string
+	baseURL string
+	apiKey  string
+	model   string
 }
 
+// NewGeminiClient reads GEMINI_API_KEY, GEMINI_MODEL and, for proxies and
+// tests, GEMINI_BASE_URL.
 func NewGeminiClient() *GeminiClient {
+	baseURL := os.Getenv("GEMINI_BASE_URL")
+	if baseURL == "" {
+		baseURL = defaultGeminiBaseURL
+	}
 	return &GeminiClient{
-		apiKey: os.Getenv("GEMINI_API_KEY"),
-		model:  os.Getenv("GEMINI_MODEL"),
+		baseURL: strings.TrimRight(baseURL, "/"),
+		apiKey:  os.Getenv("GEMINI_API_KEY"),
+		model:   os.Getenv("GEMINI_MODEL"),
 	}
 }
 
-// ParseOCR sends OCR raw text to Gemini and guarantees JSON-only output
-func (g *GeminiClient) ParseOCR(ctx context.Context, ocrText string) (string, error) {
+// Generate sends prompt to Gemini with JSON output constrained to schema.
+func (g *GeminiClient) Generate(ctx context.Context, prompt string, schema *Schema) (*Reply, error) {
 	if g.apiKey == "" {
-		return "", errors.New("missing GEMINI_API_KEY")
+		return nil, errors.New("missing GEMINI_API_KEY")
 	}
 	if g.model == "" {
-		return "", errors.New("missing GEMINI_MODEL")
-	}
-	if strings.TrimSpace(ocrText) == "" {
-		return "", errors.New("empty OCR text")
+		return nil, errors.New("missing GEMINI_MODEL")
 	}
-
-	// 🔒 Soft limit OCR text (important for PDFs)
-	const maxChars = 12000
-	if len(ocrText) > maxChars {
-		ocrText = ocrText[:maxChars]
-	}
-
-	prompt := BuildOCRParsePrompt(ocrText)
-
 	url := fmt.Sprintf(
-		"https://generativelanguage.googleapis.com/v1beta/models/%s:generateContent?key=%s",
+		"%s/models/%s:generateContent?key=%s",
+		g.baseURL,
 		g.model,
 		g.apiKey,
 	)
@@ -60,14 +59,16 @@ func (g *GeminiClient) ParseOCR(ctx context.Context, ocrText string) (string, er
 			},
 		},
 		"generationConfig": map[string]any{
-			"temperature":     0,
-			"maxOutputTokens": 2048,
+			"temperature":      0,
+			"maxOutputTokens":  MaxOutputTokens,
+			"responseMimeType": "application/json",
+			"responseSchema":   geminiSchema(schema),
 		},
 	}
 
 	body, err := json.Marshal(payload)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	req, err := http.NewRequestWithContext(
@@ -77,7 +78,7 @@ func (g *GeminiClient) ParseOCR(ctx context.Context, ocrText string) (string, er
 		bytes.NewBuffer(body),
 	)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	req.Header.Set("Content-Type", "application/json")
@@ -85,20 +86,20 @@ func (g *GeminiClient) ParseOCR(ctx context.Context, ocrText string) (string, er
 	client := &http.Client{Timeout: 60 * time.Second}
 	resp, err := client.Do(req)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	defer resp.Body.Close()
 
 	raw, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	// 🔥 DEV LOG (keep for now)
 	fmt.Println("GEMINI RAW RESPONSE:", string(raw))
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("gemini api error: %s", string(raw))
+		return nil, &APIError{Provider: "gemini", StatusCode: resp.StatusCode, Body: string(raw)}
 	}
 
 	var result struct {
@@ -108,26 +109,34 @@ func (g *GeminiClient) ParseOCR(ctx context.Context, ocrText string) (string, er
 					Text string `json:"text"`
 				} `json:"parts"`
 			} `json:"content"`
+			FinishReason string `json:"finishReason"`
 		} `json:"candidates"`
+		UsageMetadata struct {
+			PromptTokenCount     int `json:"promptTokenCount"`
+			CandidatesTokenCount int `json:"candidatesTokenCount"`
+		} `json:"usageMetadata"`
+		ModelVersion string `json:"modelVersion"`
 	}
 
 	if err := json.Unmarshal(raw, &result); err != nil {
-		return "", err
+		return nil, err
 	}
 
 	if len(result.Candidates) == 0 ||
 		len(result.Candidates[0].Content.Parts) == 0 {
-		return "", errors.New("empty gemini response")
+		return nil, errors.New("empty gemini response")
 	}
 
-	output := strings.TrimSpace(
-		result.Candidates[0].Content.Parts[0].Text,
-	)
-
-	// 🔒 Final JSON validation
-	if !json.Valid([]byte(output)) {
-		return "", errors.New("gemini returned non-json output")
+	reply := &Reply{
+		Text:         stripFence(result.Candidates[0].Content.Parts[0].Text),
+		Provider:     "gemini",
+		Model:        result.ModelVersion,
+		InputTokens:  result.UsageMetadata.PromptTokenCount,
+		OutputTokens: result.UsageMetadata.CandidatesTokenCount,
+		Truncated:    result.Candidates[0].FinishReason == "MAX_TOKENS",
 	}
-
-	return output, nil
+	if reply.Model == "" {
+		reply.Model = g.model
+	}
+	return reply, nil
 }
@@ -1,5 +1,12 @@
 package llm
 
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+	"time"
+)
+
 type ParsedItem struct {
 	Name     string  `json:"name"`
 	Category string  `json:"category"` // starter | main_course | drink | dessert
@@ -9,4 +16,58 @@ type ParsedItem struct {
 type ParsedOCRResult struct {
 	Items      []ParsedItem `json:"items"`
 	TaxPercent float64      `json:"tax_percent"`
+
+	// Quarantined holds items the model returned that broke MenuSchema.
+	Quarantined []QuarantinedItem `json:"quarantined,omitempty"`
+
+	Provenance *Provenance `json:"provenance,omitempty"`
+}
+
+// Provenance records how a parse was produced.
+type Provenance struct {
+	PromptVersion string `json:"prompt_version"`
+	Provider      string `json:"provider"`
+	Model         string `json:"model"`
+	Calls         int    `json:"calls"` // repairs included
+	InputTokens   int    `json:"input_tokens"`
+	OutputTokens  int    `json:"output_tokens"`
+	LatencyMS     int64  `json:"latency_ms"` // summed over calls
+}
+
+// Add folds in another parse of the same menu, such as its next chunk.
+func (p *Provenance) Add(other Provenance) {
+	p.PromptVersion = addDistinct(p.PromptVersion, other.PromptVersion)
+	p.Provider = addDistinct(p.Provider, other.Provider)
+	p.Model = addDistinct(p.Model, other.Model)
+	p.Calls += other.Calls
+	p.InputTokens += other.InputTokens
+	p.OutputTokens += other.OutputTokens
+	p.LatencyMS += other.LatencyMS
+}
+
+// addDistinct appends v to a comma separated list unless it is there.
+func addDistinct(list, v string) string {
+	if v == "" || slices.Contains(strings.Split(list, ", "), v) {
+		return list
+	}
+	if list == "" {
+		return v
+	}
+	return list + ", " + v
+}
+
+func (p *Provenance) record(reply *Reply, took time.Duration) {
+	p.Provider = addDistinct(p.Provider, reply.Provider)
+	p.Model = addDistinct(p.Model, reply.Model)
+	p.Calls++
+	p.InputTokens += reply.InputTokens
+	p.OutputTokens += reply.OutputTokens
+	p.LatencyMS += took.Milliseconds()
+}
+
+// QuarantinedItem is an item set aside for the reviewer rather than
+// failing the whole menu.
+type QuarantinedItem struct {
+	Raw      json.RawMessage `json:"raw"`
+	Problems []string        `json:"problems"`
 }
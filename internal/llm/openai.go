@@ -0,0 +1,156 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	defaultOpenAIBaseURL = "https://api.openai.com/v1"
+
+	// Ollama's OpenAI-compatible endpoint. vLLM and llama.cpp's server
+	// speak the same protocol, usually on :8000 and :8080.
+	defaultLocalBaseURL = "http://localhost:11434/v1"
+)
+
+// OpenAIClient talks to any OpenAI-compatible chat-completions API: OpenAI
+// itself, or a local model served by Ollama, vLLM or llama.cpp.
+type OpenAIClient struct {
+	name    string // provider name for errors and logs
+	baseURL string
+	apiKey  string // optional for local servers
+	model   string
+}
+
+func NewOpenAIClient(baseURL, apiKey, model string) *OpenAIClient {
+	return &OpenAIClient{
+		name:    "openai",
+		baseURL: strings.TrimRight(baseURL, "/"),
+		apiKey:  apiKey,
+		model:   model,
+	}
+}
+
+// NewOpenAIClientFromEnv reads OPENAI_BASE_URL (default OpenAI's API),
+// OPENAI_API_KEY and OPENAI_MODEL.
+func NewOpenAIClientFromEnv() *OpenAIClient {
+	return openAIClientFromEnv("openai", "OPENAI", defaultOpenAIBaseURL)
+}
+
+// NewLocalClientFromEnv reads LOCAL_LLM_BASE_URL (default Ollama on
+// localhost), LOCAL_LLM_API_KEY and LOCAL_LLM_MODEL.
+func NewLocalClientFromEnv() *OpenAIClient {
+	return openAIClientFromEnv("local", "LOCAL_LLM", defaultLocalBaseURL)
+}
+
+func openAIClientFromEnv(name, prefix, defaultBaseURL string) *OpenAIClient {
+	baseURL := os.Getenv(prefix + "_BASE_URL")
+	if baseURL == "" {
+		baseURL = defaultBaseURL
+	}
+	c := NewOpenAIClient(baseURL, os.Getenv(prefix+"_API_KEY"), os.Getenv(prefix+"_MODEL"))
+	c.name = name
+	return c
+}
+
+// Generate sends prompt as a single user message, asking for a reply in
+// strict JSON schema mode.
+func (o *OpenAIClient) Generate(ctx context.Context, prompt string, schema *Schema) (*Reply, error) {
+	if o.model == "" {
+		return nil, errors.New("missing model for " + o.name + " llm provider")
+	}
+	payload := map[string]any{
+		"model": o.model,
+		"messages": []map[string]string{
+			{"role": "user", "content": prompt},
+		},
+		"temperature": 0,
+		"max_tokens":  MaxOutputTokens,
+		"response_format": map[string]any{
+			"type": "json_schema",
+			"json_schema": map[string]any{
+				"name":   "menu",
+				"strict": true,
+				"schema": openAISchema(schema),
+			},
+		},
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		o.baseURL+"/chat/completions",
+		bytes.NewBuffer(body),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if o.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+o.apiKey)
+	}
+
+	client := &http.Client{Timeout: 120 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &APIError{Provider: o.name, StatusCode: resp.StatusCode, Body: string(raw)}
+	}
+
+	var result struct {
+		Choices []struct {
+			Message struct {
+				Content string `json:"content"`
+			} `json:"message"`
+			FinishReason string `json:"finish_reason"`
+		} `json:"choices"`
+		Usage struct {
+			PromptTokens     int `json:"prompt_tokens"`
+			CompletionTokens int `json:"completion_tokens"`
+		} `json:"usage"`
+		Model string `json:"model"`
+	}
+
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return nil, err
+	}
+
+	if len(result.Choices) == 0 {
+		return nil, errors.New("empty " + o.name + " response")
+	}
+
+	reply := &Reply{
+		Text:         stripFence(result.Choices[0].Message.Content),
+		Provider:     o.name,
+		Model:        result.Model,
+		InputTokens:  result.Usage.PromptTokens,
+		OutputTokens: result.Usage.CompletionTokens,
+		Truncated:    result.Choices[0].FinishReason == "length",
+	}
+	if reply.Model == "" {
+		reply.Model = o.model
+	}
+	return reply, nil
+}
@@ -0,0 +1,153 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestOpenAIClientParsesChatCompletion(t *testing.T) {
+	var prompt string
+	fake := NewFakeServer(func(p string) string {
+		prompt = p
+		return "```json\n{\"items\": [], \"tax_percent\": 0}\n```"
+	})
+	defer fake.Close()
+
+	client := NewOpenAIClient(fake.URL, "", "llama3")
+	out, err := client.Generate(context.Background(), BuildOCRParsePrompt("Paneer Tikka 220", []string{"Hindi"}), MenuSchema)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if out.Text != `{"items": [], "tax_percent": 0}` {
+		t.Fatalf("expected the fence stripped, got %q", out.Text)
+	}
+	if out.Provider != "openai" || out.Model != "llama3" || out.InputTokens == 0 || out.OutputTokens == 0 {
+		t.Fatalf("expected model and usage reported, got %+v", out)
+	}
+	if OCRText(prompt) != "Paneer Tikka 220" || !strings.Contains(prompt, "Hindi") {
+		t.Fatalf("unexpected prompt: %q", prompt)
+	}
+
+	format, _ := fake.LastRequest()["response_format"].(map[string]any)
+	if format["type"] != "json_schema" {
+		t.Fatalf("expected a json_schema response format, got %v", format)
+	}
+}
+
+func TestOpenAIClientReportsAPIErrors(t *testing.T) {
+	fake := NewFakeServer(nil)
+	defer fake.Close()
+	fake.FailWith(http.StatusTooManyRequests)
+
+	client := NewOpenAIClient(fake.URL, "key", "gpt-4o-mini")
+	_, err := ParseMenu(context.Background(), client, DefaultPromptVersion, "Dal 150", nil)
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusTooManyRequests || !apiErr.Temporary() {
+		t.Fatalf("expected a temporary APIError, got %v", err)
+	}
+}
+
+func TestGeminiClientAgainstFakeServer(t *testing.T) {
+	fake := NewFakeServer(nil)
+	defer fake.Close()
+
+	client := &GeminiClient{baseURL: fake.URL, apiKey: "key", model: "gemini-test"}
+	parsed, err := ParseMenu(context.Background(), client, DefaultPromptVersion, "Butter Chicken 320\nGST 5%", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(parsed.Items) != 1 || parsed.Items[0].Price != 320 || parsed.TaxPercent != 5 {
+		t.Fatalf("unexpected result: %+v", parsed)
+	}
+
+	config, _ := fake.LastRequest()["generationConfig"].(map[string]any)
+	schema, _ := config["responseSchema"].(map[string]any)
+	if config["responseMimeType"] != "application/json" || schema["type"] != "OBJECT" {
+		t.Fatalf("expected a JSON response schema, got %v", config)
+	}
+}
+
+func TestParseMenuReportsCutOffReplies(t *testing.T) {
+	fake := NewFakeServer(nil)
+	defer fake.Close()
+	fake.LimitOutput(10)
+
+	clients := []Client{
+		NewOpenAIClient(fake.URL, "", "fake"),
+		&GeminiClient{baseURL: fake.URL, apiKey: "key", model: "gemini-test"},
+	}
+	for _, client := range clients {
+		calls := fake.Calls()
+		_, err := ParseMenu(context.Background(), client, DefaultPromptVersion, "Butter Chicken 320\nDal Makhani 280", nil)
+		if !errors.Is(err, ErrTruncated) {
+			t.Fatalf("%T: expected ErrTruncated, got %v", client, err)
+		}
+		if got := fake.Calls() - calls; got != 1 {
+			t.Fatalf("%T: expected no repair of a cut-off reply, got %d calls", client, got)
+		}
+		var truncated *TruncatedError
+		if !errors.As(err, &truncated) || truncated.Provenance.Calls != 1 || truncated.Provenance.OutputTokens == 0 {
+			t.Fatalf("%T: expected the cut-off call's provenance, got %v", client, err)
+		}
+	}
+}
+
+func TestFallbackClientTriesProvidersInOrder(t *testing.T) {
+	down := NewFakeServer(nil)
+	defer down.Close()
+	down.FailWith(http.StatusServiceUnavailable)
+
+	up := NewFakeServer(nil)
+	defer up.Close()
+
+	client := NewFallbackClient(
+		Provider{Name: "gemini", Client: &GeminiClient{baseURL: down.URL, apiKey: "key", model: "m"}},
+		Provider{Name: "local", Client: NewOpenAIClient(up.URL, "", "m")},
+	)
+
+	if _, err := ParseMenu(context.Background(), client, DefaultPromptVersion, "Lassi 90", nil); err != nil {
+		t.Fatalf("expected fallback to succeed, got %v", err)
+	}
+	if down.Calls() != 1 || up.Calls() != 1 {
+		t.Fatalf("expected one call each, got %d and %d", down.Calls(), up.Calls())
+	}
+
+	up.FailWith(http.StatusBadRequest)
+	_, err := ParseMenu(context.Background(), client, DefaultPromptVersion, "Lassi 90", nil)
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || !apiErr.Temporary() {
+		t.Fatalf("expected the joined errors to keep the 503, got %v", err)
+	}
+}
+
+func TestNewClientFromEnv(t *testing.T) {
+	t.Setenv("LLM_PROVIDERS", "local, openai")
+	t.Setenv("LOCAL_LLM_MODEL", "llama3")
+	t.Setenv("OPENAI_API_KEY", "")
+	t.Setenv("OPENAI_BASE_URL", "")
+
+	if _, err := NewClientFromEnv(); err == nil {
+		t.Fatal("expected an error for openai without credentials")
+	}
+
+	t.Setenv("OPENAI_API_KEY", "key")
+	t.Setenv("OPENAI_MODEL", "gpt-4o-mini")
+	client, err := NewClientFromEnv()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := client.(*FallbackClient); !ok {
+		t.Fatalf("expected a FallbackClient, got %T", client)
+	}
+
+	t.Setenv("LLM_PROVIDERS", "claude")
+	if _, err := NewClientFromEnv(); err == nil {
+		t.Fatal("expected an error for an unknown provider")
+	}
+}
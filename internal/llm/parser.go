@@ -1,40 +1,151 @@
 package llm
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"log"
 	"strings"
+	"time"
 )
 
-func ParseLLMResponse(raw string) (*ParsedOCRResult, error) {
-	raw = strings.TrimSpace(raw)
+// MalformedError is a reply that broke MenuSchema as a whole, even after
+// the model was asked to repair it.
+type MalformedError struct {
+	Problems []Problem
+}
 
-	// Hard safety: must be pure JSON object
-	if !strings.HasPrefix(raw, "{") || !strings.HasSuffix(raw, "}") {
-		return nil, errors.New("LLM output is not pure JSON")
+func (e *MalformedError) Error() string {
+	msgs := make([]string, len(e.Problems))
+	for i, p := range e.Problems {
+		msgs[i] = p.String()
 	}
+	return "malformed llm reply: " + strings.Join(msgs, "; ")
+}
+
+// ErrTruncated is a reply cut off at MaxOutputTokens: the text holds more
+// items than fit in one reply and must be sent in smaller pieces.
+var ErrTruncated = errors.New("llm reply cut off at the output token limit")
+
+// TruncatedError is ErrTruncated from ParseMenu. Provenance covers the
+// calls made, the cut-off one included, so a caller that retries in
+// smaller pieces can still account for them.
+type TruncatedError struct {
+	Provenance *Provenance
+}
+
+func (e *TruncatedError) Error() string {
+	return ErrTruncated.Error()
+}
+
+func (e *TruncatedError) Unwrap() error {
+	return ErrTruncated
+}
 
-	var parsed ParsedOCRResult
-	if err := json.Unmarshal([]byte(raw), &parsed); err != nil {
+// ParseMenu has client extract the menu items and tax in OCR text using
+// the prompt registered as version. languages are the display names of the
+// menu's languages, e.g. ["Hindi", "English"]; nil for English-only menus.
+//
+// Items that break MenuSchema are quarantined instead of failing the
+// menu. A reply that is unusable as a whole gets one repair prompt
+// listing its problems before ParseMenu gives up with a MalformedError.
+// The result's Provenance covers both calls. A reply cut off at the
+// output limit is not repaired; ParseMenu returns a *TruncatedError.
+func ParseMenu(ctx context.Context, client Client, version string, ocrText string, languages []string) (*ParsedOCRResult, error) {
+	template, err := LookupPrompt(version)
+	if err != nil {
 		return nil, err
 	}
+	if strings.TrimSpace(ocrText) == "" {
+		return nil, errors.New("empty OCR text")
+	}
+
+	provenance := &Provenance{PromptVersion: version}
+	generate := func(prompt string) (*Reply, error) {
+		start := time.Now()
+		reply, err := client.Generate(ctx, prompt, MenuSchema)
+		if err != nil {
+			return nil, err
+		}
+		provenance.record(reply, time.Since(start))
+		if reply.Truncated {
+			return nil, &TruncatedError{Provenance: provenance}
+		}
+		return reply, nil
+	}
 
-	// Structural validation only
-	if parsed.Items == nil {
-		return nil, errors.New("missing items field in parsed JSON")
+	prompt := template(truncateOCRText(ocrText), languages)
+	reply, err := generate(prompt)
+	if err != nil {
+		return nil, err
 	}
 
-	// Validate items only if present
-	for _, item := range parsed.Items {
-		if item.Name == "" {
-			return nil, errors.New("invalid item: empty name")
+	result, problems := DecodeMenu(reply.Text)
+	if len(problems) == 0 {
+		result.Provenance = provenance
+		return result, nil
+	}
+
+	log.Printf("[LLM] Malformed reply, asking for a repair: %v", problems)
+	reply, err = generate(BuildRepairPrompt(prompt, reply.Text, problems))
+	if err != nil {
+		return nil, err
+	}
+
+	result, problems = DecodeMenu(reply.Text)
+	if len(problems) > 0 {
+		return nil, &MalformedError{Problems: problems}
+	}
+	result.Provenance = provenance
+	return result, nil
+}
+
+// DecodeMenu checks a reply against MenuSchema. Items that break it are
+// quarantined in the result; problems lists what makes the reply as a
+// whole unusable, in which case the result is nil.
+func DecodeMenu(raw string) (*ParsedOCRResult, []Problem) {
+	var doc any
+	if err := json.Unmarshal([]byte(raw), &doc); err != nil {
+		return nil, []Problem{{Message: "not valid JSON: " + err.Error()}}
+	}
+
+	var problems []Problem
+	itemProblems := map[int][]string{}
+	for _, p := range MenuSchema.Validate(doc) {
+		var i int
+		if _, err := fmt.Sscanf(p.Path, "items[%d]", &i); err == nil {
+			itemProblems[i] = append(itemProblems[i], p.String())
+			continue
 		}
-		if item.Price <= 0 {
-			return nil, errors.New("invalid item: price must be > 0")
+		problems = append(problems, p)
+	}
+	if len(problems) > 0 {
+		return nil, problems
+	}
+
+	// Validated above, so the assertions hold.
+	obj := doc.(map[string]any)
+	result := &ParsedOCRResult{
+		Items:      []ParsedItem{},
+		TaxPercent: obj["tax_percent"].(float64),
+	}
+
+	for i, el := range obj["items"].([]any) {
+		if msgs, bad := itemProblems[i]; bad {
+			raw, _ := json.Marshal(el)
+			result.Quarantined = append(result.Quarantined, QuarantinedItem{Raw: raw, Problems: msgs})
+			continue
 		}
+
+		item := el.(map[string]any)
+		result.Items = append(result.Items, ParsedItem{
+			Name:     strings.TrimSpace(item["name"].(string)),
+			Category: item["category"].(string),
+			Price:    item["price"].(float64),
+		})
 	}
 
-	// ✅ IMPORTANT:
-	// Empty items is VALID (common for PDFs)
-	return &parsed, nil
+	// Empty items is valid (common for PDFs).
+	return result, nil
 }
@@ -0,0 +1,147 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestDecodeMenuQuarantinesInvalidItems(t *testing.T) {
+	parsed, problems := DecodeMenu(`{
+		"items": [
+			{"name": "Paneer Tikka", "category": "starter", "price": 220},
+			{"name": " ", "category": "main_course", "price": 280},
+			{"name": "Masala Chai", "category": "beverage", "price": 40},
+			{"name": "Gulab Jamun", "category": "dessert", "price": "90"},
+			{"name": "Dal Makhani", "category": "main_course", "price": 0}
+		],
+		"tax_percent": 5
+	}`)
+	if len(problems) > 0 {
+		t.Fatalf("unexpected problems: %v", problems)
+	}
+
+	if len(parsed.Items) != 1 || parsed.Items[0].Name != "Paneer Tikka" || parsed.TaxPercent != 5 {
+		t.Fatalf("unexpected result: %+v", parsed)
+	}
+	if len(parsed.Quarantined) != 4 {
+		t.Fatalf("expected 4 quarantined items, got %+v", parsed.Quarantined)
+	}
+	if got := parsed.Quarantined[1].Problems[0]; got != `items[2].category: "beverage" is not one of starter, main_course, drink, dessert` {
+		t.Fatalf("unexpected problem: %q", got)
+	}
+	if !strings.Contains(string(parsed.Quarantined[2].Raw), `"price":"90"`) {
+		t.Fatalf("expected the raw item kept, got %s", parsed.Quarantined[2].Raw)
+	}
+}
+
+func TestDecodeMenuRejectsMalformedDocuments(t *testing.T) {
+	cases := map[string]string{
+		"not json":      `Here is the menu: {"items": []}`,
+		"missing items": `{"tax_percent": 5}`,
+		"items null":    `{"items": null, "tax_percent": 0}`,
+		"tax as string": `{"items": [], "tax_percent": "5%"}`,
+	}
+	for name, raw := range cases {
+		parsed, problems := DecodeMenu(raw)
+		if parsed != nil || len(problems) == 0 {
+			t.Errorf("%s: expected problems, got %+v", name, parsed)
+		}
+	}
+
+	if _, problems := DecodeMenu(`{"items": [], "tax_percent": 0}`); len(problems) > 0 {
+		t.Fatalf("expected an empty menu to be valid, got %v", problems)
+	}
+}
+
+func TestParseMenuRepairsMalformedReplyOnce(t *testing.T) {
+	var calls atomic.Int32
+	var repairPrompt string
+	fake := NewFakeServer(func(prompt string) string {
+		if calls.Add(1) == 1 {
+			return `{"items": [{"name": "Lassi", "category": "drink", "price": 90}]}`
+		}
+		repairPrompt = prompt
+		return FakeMenuReply(prompt)
+	})
+	defer fake.Close()
+
+	client := NewOpenAIClient(fake.URL, "", "m")
+	parsed, err := ParseMenu(context.Background(), client, DefaultPromptVersion, "Lassi 90\nGST 5%", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(parsed.Items) != 1 || parsed.TaxPercent != 5 {
+		t.Fatalf("unexpected result: %+v", parsed)
+	}
+	if p := parsed.Provenance; p.Calls != 2 || p.PromptVersion != "v1" || p.Model != "m" || p.InputTokens == 0 {
+		t.Fatalf("expected both calls in the provenance, got %+v", p)
+	}
+	if !strings.Contains(repairPrompt, "- tax_percent: missing") || OCRText(repairPrompt) != "Lassi 90\nGST 5%" {
+		t.Fatalf("expected the problems and OCR text in the repair prompt, got %q", repairPrompt)
+	}
+
+	broken := NewFakeServer(func(string) string { return "Sorry, I cannot help with that." })
+	defer broken.Close()
+
+	_, err = ParseMenu(context.Background(), NewOpenAIClient(broken.URL, "", "m"), DefaultPromptVersion, "Lassi 90", nil)
+	var malformed *MalformedError
+	if !errors.As(err, &malformed) {
+		t.Fatalf("expected a MalformedError, got %v", err)
+	}
+	if broken.Calls() != 2 {
+		t.Fatalf("expected one repair attempt, got %d calls", broken.Calls())
+	}
+}
+
+func TestOpenAISchemaIsStrict(t *testing.T) {
+	schema := openAISchema(MenuSchema)
+	if schema["additionalProperties"] != false {
+		t.Fatal("expected additional properties disallowed")
+	}
+	item := schema["properties"].(map[string]any)["items"].(map[string]any)["items"].(map[string]any)
+	required := item["required"].([]string)
+	if strings.Join(required, ",") != "category,name,price" {
+		t.Fatalf("expected every item property required, got %v", required)
+	}
+}
+
+func TestPromptRegistry(t *testing.T) {
+	if _, err := LookupPrompt("v0"); err == nil {
+		t.Fatal("expected an error for an unknown version")
+	}
+
+	for _, version := range PromptVersions() {
+		template, err := LookupPrompt(version)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", version, err)
+		}
+		if got := OCRText(template("Lassi 90", nil)); got != "Lassi 90" {
+			t.Errorf("%s: expected the prompt to end with the OCR text, got %q", version, got)
+		}
+	}
+
+	t.Setenv("LLM_PROMPT_VERSION", "v2")
+	if v, err := PromptVersionFromEnv(); err != nil || v != "v2" {
+		t.Fatalf("expected v2, got %q, %v", v, err)
+	}
+	t.Setenv("LLM_PROMPT_VERSION", "latest")
+	if _, err := PromptVersionFromEnv(); err == nil {
+		t.Fatal("expected an error for an unregistered version")
+	}
+}
+
+func TestProvenanceAdd(t *testing.T) {
+	p := Provenance{PromptVersion: "v1", Provider: "gemini", Model: "gemini-2.0-flash", Calls: 1, InputTokens: 100, LatencyMS: 900}
+	p.Add(Provenance{PromptVersion: "v1", Provider: "local", Model: "llama3", Calls: 2, InputTokens: 50, LatencyMS: 300})
+	p.Add(Provenance{PromptVersion: "v1", Provider: "gemini", Model: "gemini-2.0-flash", Calls: 1})
+
+	if p.Provider != "gemini, local" || p.Model != "gemini-2.0-flash, llama3" || p.PromptVersion != "v1" {
+		t.Fatalf("expected distinct providers and models listed, got %+v", p)
+	}
+	if p.Calls != 4 || p.InputTokens != 150 || p.LatencyMS != 1200 {
+		t.Fatalf("expected usage summed, got %+v", p)
+	}
+}
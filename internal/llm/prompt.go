@@ -1,6 +1,87 @@
 package llm
 
-func BuildOCRParsePrompt(ocrText string) string {
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// PromptTemplate builds an extraction prompt for OCR text. languages
+// names the menu's languages when it is not English-only. Prompts must end
+// with the OCR text, after "OCR TEXT STARTS BELOW:".
+type PromptTemplate func(ocrText string, languages []string) string
+
+// DefaultPromptVersion is used unless LLM_PROMPT_VERSION picks another.
+const DefaultPromptVersion = "v1"
+
+var prompts = map[string]PromptTemplate{}
+
+// RegisterPrompt adds a prompt under version. Versions are recorded with
+// every parse, so a registered template must never change; register a new
+// version instead.
+func RegisterPrompt(version string, template PromptTemplate) {
+	if _, ok := prompts[version]; ok {
+		panic("llm: prompt version " + version + " registered twice")
+	}
+	prompts[version] = template
+}
+
+func init() {
+	RegisterPrompt("v1", BuildOCRParsePrompt)
+	RegisterPrompt("v2", buildOCRParsePromptV2)
+}
+
+// LookupPrompt returns the prompt registered as version.
+func LookupPrompt(version string) (PromptTemplate, error) {
+	template, ok := prompts[version]
+	if !ok {
+		return nil, fmt.Errorf("unknown prompt version %q (have %s)",
+			version, strings.Join(PromptVersions(), ", "))
+	}
+	return template, nil
+}
+
+// PromptVersions lists the registered prompt versions.
+func PromptVersions() []string {
+	versions := make([]string, 0, len(prompts))
+	for v := range prompts {
+		versions = append(versions, v)
+	}
+	sort.Strings(versions)
+	return versions
+}
+
+// PromptVersionFromEnv reads LLM_PROMPT_VERSION (default
+// DefaultPromptVersion) and checks it is registered.
+func PromptVersionFromEnv() (string, error) {
+	version := strings.TrimSpace(os.Getenv("LLM_PROMPT_VERSION"))
+	if version == "" {
+		version = DefaultPromptVersion
+	}
+	if _, err := LookupPrompt(version); err != nil {
+		return "", err
+	}
+	return version, nil
+}
+
+// BuildOCRParsePrompt is prompt v1.
+func BuildOCRParsePrompt(ocrText string, languages []string) string {
+	return ocrParsePrompt(ocrText, languages, "")
+}
+
+// buildOCRParsePromptV2 is v1 plus rules for items sold in several sizes,
+// which v1 tends to collapse into one item or skip.
+func buildOCRParsePromptV2(ocrText string, languages []string) string {
+	return ocrParsePrompt(ocrText, languages, `
+ITEMS WITH SEVERAL PRICES:
+- An item may list prices for sizes or portions, e.g. "Half / Full", "Small / Large", "Glass / Bottle".
+- Output one item per price, with the size appended to the name, e.g. "Dal Makhani (Half)" and "Dal Makhani (Full)".
+- Add-ons and extras (e.g. "Extra Cheese +30") are NOT menu items; skip them.
+`)
+}
+
+func ocrParsePrompt(ocrText string, languages []string, extraRules string) string {
 	return `
 You are a restaurant menu data extraction engine.
 
@@ -14,7 +95,7 @@ IMPORTANT INSTRUCTIONS:
 - Prices may appear on the same line or the next line.
 - Do NOT guess or hallucinate items.
 - If you are unsure about an item, skip it.
-
+` + extraRules + sourceLanguageSection(languages) + `
 CATEGORIES:
 - starter
 - main_course
@@ -51,3 +132,41 @@ REQUIRED JSON FORMAT:
 OCR TEXT STARTS BELOW:
 ` + ocrText
 }
+
+func sourceLanguageSection(languages []string) string {
+	if len(languages) == 0 {
+		return ""
+	}
+	return `
+SOURCE LANGUAGE:
+- The menu is printed in ` + strings.Join(languages, " and ") + `, possibly mixed on one line.
+- Copy item names exactly as printed, in their original script. Do NOT translate or transliterate them.
+- If an item is printed in two scripts, use the name in the first script it appears in.
+- Prices may use Indian-script digits (e.g. २८० is 280); always output prices as plain numbers.
+- Words such as "GST", "जीएसटी" or "कर" next to a percentage are tax.
+`
+}
+
+// BuildRepairPrompt asks the model to fix its reply to prompt, listing
+// what was wrong with it. It ends with prompt, OCR text included.
+func BuildRepairPrompt(prompt string, reply string, problems []Problem) string {
+	var b strings.Builder
+	b.WriteString(`
+Your previous reply to the task below could not be used.
+
+PROBLEMS:
+`)
+	for _, p := range problems {
+		b.WriteString("- " + p.String() + "\n")
+	}
+	b.WriteString(`
+YOUR PREVIOUS REPLY:
+` + truncateOCRText(reply) + `
+
+Reply again with ONLY the corrected JSON, following every rule of the task.
+
+TASK:
+`)
+	b.WriteString(prompt)
+	return b.String()
+}
@@ -0,0 +1,108 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+)
+
+// Provider is a named Client, as selected in LLM_PROVIDERS.
+type Provider struct {
+	Name   string
+	Client Client
+}
+
+// NewClientFromEnv builds the providers listed in LLM_PROVIDERS, a comma
+// separated fallback order such as "gemini,local" (default "gemini").
+// Known providers are gemini, openai, local (any OpenAI-compatible
+// server, Ollama by default) and rules, the model-free ParseRules, which
+// belongs last as a fallback. One provider is returned as is; several are
+// wrapped in a FallbackClient.
+func NewClientFromEnv() (Client, error) {
+	names := os.Getenv("LLM_PROVIDERS")
+	if strings.TrimSpace(names) == "" {
+		names = "gemini"
+	}
+
+	var providers []Provider
+	for _, name := range strings.Split(names, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+
+		client, err := providerFromEnv(name)
+		if err != nil {
+			return nil, err
+		}
+		providers = append(providers, Provider{Name: name, Client: client})
+	}
+
+	if len(providers) == 1 {
+		return providers[0].Client, nil
+	}
+	return NewFallbackClient(providers...), nil
+}
+
+func providerFromEnv(name string) (Client, error) {
+	switch name {
+	case "gemini":
+		if os.Getenv("GEMINI_API_KEY") == "" || os.Getenv("GEMINI_MODEL") == "" {
+			return nil, errors.New("llm provider gemini needs GEMINI_API_KEY and GEMINI_MODEL")
+		}
+		return NewGeminiClient(), nil
+	case "openai":
+		if os.Getenv("OPENAI_API_KEY") == "" && os.Getenv("OPENAI_BASE_URL") == "" {
+			return nil, errors.New("llm provider openai needs OPENAI_API_KEY or OPENAI_BASE_URL")
+		}
+		if os.Getenv("OPENAI_MODEL") == "" {
+			return nil, errors.New("llm provider openai needs OPENAI_MODEL")
+		}
+		return NewOpenAIClientFromEnv(), nil
+	case "local":
+		if os.Getenv("LOCAL_LLM_MODEL") == "" {
+			return nil, errors.New("llm provider local needs LOCAL_LLM_MODEL")
+		}
+		return NewLocalClientFromEnv(), nil
+	case "rules":
+		return NewRulesClient(), nil
+	default:
+		return nil, fmt.Errorf("unknown llm provider %q", name)
+	}
+}
+
+// FallbackClient tries each provider in order until one succeeds.
+type FallbackClient struct {
+	providers []Provider
+}
+
+func NewFallbackClient(providers ...Provider) *FallbackClient {
+	return &FallbackClient{providers: providers}
+}
+
+// Generate returns the first successful reply. If every provider fails
+// the errors are joined, so callers can still tell a rate limit from a bad
+// request.
+func (f *FallbackClient) Generate(ctx context.Context, prompt string, schema *Schema) (*Reply, error) {
+	var errs []error
+	for _, p := range f.providers {
+		out, err := p.Client.Generate(ctx, prompt, schema)
+		if err == nil {
+			return out, nil
+		}
+		if ctx.Err() != nil {
+			return nil, err
+		}
+
+		log.Printf("[LLM] Provider %s failed, trying the next: %v", p.Name, err)
+		errs = append(errs, fmt.Errorf("%s: %w", p.Name, err))
+	}
+
+	if len(errs) == 0 {
+		return nil, errors.New("no llm providers configured")
+	}
+	return nil, errors.Join(errs...)
+}
@@ -0,0 +1,232 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// RulesModel names the rule-based parser in provenance.
+const RulesModel = "rules-v1"
+
+// RulesClient parses menus with ParseRules instead of a model. As a
+// provider ("rules" in LLM_PROVIDERS) it keeps menus moving to review when
+// every model provider is down or rate limited.
+type RulesClient struct{}
+
+func NewRulesClient() *RulesClient {
+	return &RulesClient{}
+}
+
+// Generate parses the OCR text at the end of prompt. Repair prompts end
+// with the original prompt, so they get the same answer.
+func (RulesClient) Generate(ctx context.Context, prompt string, schema *Schema) (*Reply, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if schema != MenuSchema {
+		return nil, errors.New("rules provider only parses menus")
+	}
+
+	out, err := json.Marshal(ParseRules(OCRText(prompt)))
+	if err != nil {
+		return nil, err
+	}
+	return &Reply{Text: string(out), Provider: "rules", Model: RulesModel}, nil
+}
+
+var (
+	// "Paneer Tikka ....... ₹ 220/-", "Dal Makhani 180 / 280"
+	rulesItemLine  = regexp.MustCompile(`(?i)^(.*?\pL.*?)[\s.·:_|*\-–—]*(?:₹|rs\.?|inr)?\s*(\d{1,5}(?:\.\d{1,2})?)\s*(?:/-)?(?:\s*/\s*(?:₹|rs\.?)?\s*\d{1,5}(?:\.\d{1,2})?\s*(?:/-)?)*$`)
+	rulesPriceLine = regexp.MustCompile(`(?i)^(?:₹|rs\.?|inr)?\s*(\d{1,5}(?:\.\d{1,2})?)\s*(?:/-)?(?:\s*/\s*(?:₹|rs\.?)?\s*\d{1,5}(?:\.\d{1,2})?\s*(?:/-)?)*$`)
+	// "GST 5%", "CGST @ 2.5% + SGST @ 2.5%", "जीएसटी 5%"
+	rulesTax       = regexp.MustCompile(`(?i)(cgst|sgst|igst|gst|vat|tax|जीएसटी|कर)[^\d%\n]{0,20}?(\d{1,2}(?:\.\d{1,2})?)\s*%`)
+	rulesLongDigit = regexp.MustCompile(`\d{6,}`) // phone numbers, PIN codes
+	rulesTime      = regexp.MustCompile(`\d{1,2}[:.]\d{2}\s*(?i:am|pm)`)
+	rulesPage      = regexp.MustCompile(`(?i)^page\s*\d+`)
+	rulesLetter    = regexp.MustCompile(`\pL`)
+	rulesBullet    = regexp.MustCompile(`^(?:\d{1,3}[.)]\s+|[•*·-]\s*)`)
+)
+
+// rulesHeadings maps words in section headings to categories.
+var rulesHeadings = []struct {
+	category string
+	words    []string
+}{
+	{"drink", []string{"beverage", "drink", "mocktail", "shake", "juice", "lassi", "tea", "coffee", "cooler", "पेय"}},
+	{"dessert", []string{"dessert", "sweet", "mithai", "ice cream", "मिठाई"}},
+	{"starter", []string{"starter", "appetiser", "appetizer", "soup", "snack", "chaat", "small plate", "स्टार्टर"}},
+	{"main_course", []string{"main", "curries", "curry", "biryani", "rice", "bread", "roti", "thali", "sabzi", "dal", "noodle", "pizza", "मुख्य"}},
+}
+
+// rulesItemWords guesses the category of items listed before any heading.
+var rulesItemWords = []struct {
+	category string
+	words    []string
+}{
+	{"drink", []string{"lassi", "chai", "tea", "coffee", "juice", "soda", "water", "shake", "mojito", "cola", "buttermilk", "chaas"}},
+	{"dessert", []string{"jamun", "kulfi", "ice cream", "halwa", "kheer", "rasgulla", "rasmalai", "brownie", "gulab"}},
+	{"starter", []string{"tikka", "soup", "pakora", "samosa", "kebab", "chaat", "fries", "manchurian", "spring roll"}},
+}
+
+// ParseRules extracts a menu from OCR text without a model. It reads
+// "name ... price" lines, names whose price is alone on the next line,
+// section headings (Starters, Mains, Beverages, Desserts) for categories
+// and GST lines for the tax. Lines it cannot read are skipped, so it
+// finds fewer items than a model but never invents one.
+func ParseRules(ocrText string) *ParsedOCRResult {
+	result := &ParsedOCRResult{Items: []ParsedItem{}}
+
+	// A line without a price may be a heading or an item priced on the
+	// next line. It is taken as a heading if it reads like one, and
+	// turned back into an item if a lone price follows.
+	category, headingWas := "", ""
+	pendingName, pendingHeading := "", false
+	var gst, cgst, sgst float64
+
+	for _, line := range strings.Split(asciiDigits(ocrText), "\n") {
+		line = strings.TrimSpace(rulesBullet.ReplaceAllString(strings.TrimSpace(line), ""))
+		if line == "" || rulesLongDigit.MatchString(line) || rulesTime.MatchString(line) || rulesPage.MatchString(line) {
+			pendingName, pendingHeading = "", false
+			continue
+		}
+
+		if taxes := rulesTax.FindAllStringSubmatch(line, -1); taxes != nil {
+			for _, t := range taxes {
+				rate, _ := strconv.ParseFloat(t[2], 64)
+				switch strings.ToLower(t[1]) {
+				case "cgst":
+					cgst = rate
+				case "sgst":
+					sgst = rate
+				default:
+					gst = rate
+				}
+			}
+			pendingName, pendingHeading = "", false
+			continue
+		}
+
+		if m := rulesPriceLine.FindStringSubmatch(line); m != nil {
+			if pendingHeading {
+				category = headingWas
+			}
+			if pendingName != "" {
+				result.addRulesItem(pendingName, m[1], category)
+			}
+			pendingName, pendingHeading = "", false
+			continue
+		}
+
+		if m := rulesItemLine.FindStringSubmatch(line); m != nil {
+			result.addRulesItem(m[1], m[2], category)
+			pendingName, pendingHeading = "", false
+			continue
+		}
+
+		pendingName, pendingHeading = "", false
+		if c, ok := headingCategory(line); ok {
+			headingWas, category = category, c
+			pendingName, pendingHeading = line, true
+		} else if rulesLetter.MatchString(line) {
+			pendingName = line
+		}
+	}
+
+	switch {
+	case gst > 0:
+		result.TaxPercent = gst
+	case cgst > 0 || sgst > 0:
+		result.TaxPercent = cgst + sgst
+	}
+	return result
+}
+
+func (r *ParsedOCRResult) addRulesItem(name, price, category string) {
+	name = strings.Trim(strings.TrimSpace(name), ".·:_|*-–— ")
+	value, err := strconv.ParseFloat(price, 64)
+	if name == "" || err != nil || value <= 0 {
+		return
+	}
+	if category == "" {
+		category = guessCategory(name)
+	}
+	r.Items = append(r.Items, ParsedItem{Name: name, Category: category, Price: value})
+}
+
+// headingCategory reads short lines without prices, like "STARTERS" or
+// "Hot Beverages", as section headings.
+func headingCategory(line string) (string, bool) {
+	if strings.ContainsAny(line, "0123456789") || len(strings.Fields(line)) > 4 {
+		return "", false
+	}
+	for _, h := range rulesHeadings {
+		if hasWord(line, h.words) {
+			return h.category, true
+		}
+	}
+	return "", false
+}
+
+func guessCategory(name string) string {
+	for _, c := range rulesItemWords {
+		if hasWord(name, c.words) {
+			return c.category
+		}
+	}
+	return "main_course"
+}
+
+// hasWord reports whether text has a word starting with one of words
+// ("beverage" matches "Beverages" but "tea" does not match "steak").
+// Phrases match anywhere.
+func hasWord(text string, words []string) bool {
+	lower := strings.ToLower(text)
+	fields := strings.FieldsFunc(lower, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsMark(r)
+	})
+	for _, w := range words {
+		if strings.Contains(w, " ") {
+			if strings.Contains(lower, w) {
+				return true
+			}
+			continue
+		}
+		for _, f := range fields {
+			if strings.HasPrefix(f, w) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// indicZeros are the code points of zero in the Indian scripts' digit
+// runs; each script's digits follow its zero.
+var indicZeros = []rune{
+	0x0966, // Devanagari
+	0x09E6, // Bengali
+	0x0A66, // Gurmukhi
+	0x0AE6, // Gujarati
+	0x0B66, // Odia
+	0x0BE6, // Tamil
+	0x0C66, // Telugu
+	0x0CE6, // Kannada
+	0x0D66, // Malayalam
+}
+
+// asciiDigits rewrites Indian-script digits (२८० is 280) as ASCII.
+func asciiDigits(s string) string {
+	return strings.Map(func(r rune) rune {
+		for _, zero := range indicZeros {
+			if r >= zero && r <= zero+9 {
+				return '0' + (r - zero)
+			}
+		}
+		return r
+	}, s)
+}
@@ -0,0 +1,100 @@
+package llm
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestParseRulesReadsMenuLayouts(t *testing.T) {
+	menu := `
+SHARMA DHABA
+Shop 4, MG Road  Ph: 9876543210
+Open 11:00 am - 11:00 pm
+
+STARTERS
+1. Paneer Tikka ........ ₹ 220/-
+Hara Bhara Kebab
+180
+Page 2
+
+Main Course
+Dal Tadka
+160
+Dal Makhani 180 / 280
+T-Bone Steak  Rs. 650
+
+पेय
+मसाला चाय ४०
+
+Desserts
+Gulab Jamun (2 pcs) - 90
+
+CGST @ 2.5%   SGST @ 2.5%
+`
+	parsed := ParseRules(menu)
+
+	want := []ParsedItem{
+		{Name: "Paneer Tikka", Category: "starter", Price: 220},
+		{Name: "Hara Bhara Kebab", Category: "starter", Price: 180},
+		{Name: "Dal Tadka", Category: "main_course", Price: 160},
+		{Name: "Dal Makhani", Category: "main_course", Price: 180},
+		{Name: "T-Bone Steak", Category: "main_course", Price: 650},
+		{Name: "मसाला चाय", Category: "drink", Price: 40},
+		{Name: "Gulab Jamun (2 pcs)", Category: "dessert", Price: 90},
+	}
+	if len(parsed.Items) != len(want) {
+		t.Fatalf("expected %d items, got %+v", len(want), parsed.Items)
+	}
+	for i, it := range want {
+		if parsed.Items[i] != it {
+			t.Errorf("item %d: expected %+v, got %+v", i, it, parsed.Items[i])
+		}
+	}
+	if parsed.TaxPercent != 5 {
+		t.Fatalf("expected CGST and SGST added up to 5, got %v", parsed.TaxPercent)
+	}
+}
+
+func TestParseRulesGuessesCategoriesWithoutHeadings(t *testing.T) {
+	parsed := ParseRules("Masala Chai 40\nVeg Pakora 120\nKadhai Paneer 260\nGST 5%")
+
+	categories := []string{"drink", "starter", "main_course"}
+	if len(parsed.Items) != 3 || parsed.TaxPercent != 5 {
+		t.Fatalf("unexpected result: %+v", parsed)
+	}
+	for i, c := range categories {
+		if parsed.Items[i].Category != c {
+			t.Errorf("%s: expected %s, got %s", parsed.Items[i].Name, c, parsed.Items[i].Category)
+		}
+	}
+}
+
+func TestRulesProviderAsFallback(t *testing.T) {
+	down := NewFakeServer(nil)
+	defer down.Close()
+	down.FailWith(http.StatusTooManyRequests)
+
+	client := NewFallbackClient(
+		Provider{Name: "gemini", Client: &GeminiClient{baseURL: down.URL, apiKey: "key", model: "m"}},
+		Provider{Name: "rules", Client: NewRulesClient()},
+	)
+
+	parsed, err := ParseMenu(context.Background(), client, DefaultPromptVersion, "Butter Chicken 320\nGST 5%", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(parsed.Items) != 1 || parsed.TaxPercent != 5 {
+		t.Fatalf("unexpected result: %+v", parsed)
+	}
+	if p := parsed.Provenance; p.Provider != "rules" || p.Model != RulesModel {
+		t.Fatalf("expected the rules provider recorded, got %+v", p)
+	}
+
+	t.Setenv("LLM_PROVIDERS", "rules")
+	if c, err := NewClientFromEnv(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	} else if _, ok := c.(*RulesClient); !ok {
+		t.Fatalf("expected a RulesClient, got %T", c)
+	}
+}
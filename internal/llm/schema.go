@@ -0,0 +1,207 @@
+package llm
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Schema is the subset of JSON Schema replies are held to. It is sent to
+// providers that support structured output and checked again on every
+// reply, since not all providers enforce it.
+type Schema struct {
+	Type       string             `json:"type"`
+	Properties map[string]*Schema `json:"properties,omitempty"`
+	Required   []string           `json:"required,omitempty"`
+	Items      *Schema            `json:"items,omitempty"`
+	Enum       []string           `json:"enum,omitempty"`
+
+	// Checked locally only; providers' structured output modes reject
+	// or ignore them.
+	NonEmpty bool `json:"-"` // strings: not blank
+	Positive bool `json:"-"` // numbers: greater than zero
+}
+
+// Categories an item may have.
+var Categories = []string{"starter", "main_course", "drink", "dessert"}
+
+// MenuSchema describes a ParsedOCRResult.
+var MenuSchema = &Schema{
+	Type: "object",
+	Properties: map[string]*Schema{
+		"items": {
+			Type: "array",
+			Items: &Schema{
+				Type: "object",
+				Properties: map[string]*Schema{
+					"name":     {Type: "string", NonEmpty: true},
+					"category": {Type: "string", Enum: Categories},
+					"price":    {Type: "number", Positive: true},
+				},
+				Required: []string{"name", "category", "price"},
+			},
+		},
+		"tax_percent": {Type: "number"},
+	},
+	Required: []string{"items", "tax_percent"},
+}
+
+// Problem is one way a reply breaks its schema.
+type Problem struct {
+	Path    string // e.g. items[3].price; empty for the document itself
+	Message string
+}
+
+func (p Problem) String() string {
+	if p.Path == "" {
+		return p.Message
+	}
+	return p.Path + ": " + p.Message
+}
+
+// Validate checks v, as decoded by encoding/json, against s.
+func (s *Schema) Validate(v any) []Problem {
+	var problems []Problem
+	s.validate(v, "", &problems)
+	return problems
+}
+
+func (s *Schema) validate(v any, path string, problems *[]Problem) {
+	fail := func(format string, args ...any) {
+		*problems = append(*problems, Problem{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	switch s.Type {
+	case "object":
+		obj, ok := v.(map[string]any)
+		if !ok {
+			fail("expected an object, got %s", jsonType(v))
+			return
+		}
+		for _, name := range s.Required {
+			if _, ok := obj[name]; !ok {
+				*problems = append(*problems, Problem{Path: join(path, name), Message: "missing"})
+			}
+		}
+		names := make([]string, 0, len(s.Properties))
+		for name := range s.Properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if field, ok := obj[name]; ok {
+				s.Properties[name].validate(field, join(path, name), problems)
+			}
+		}
+
+	case "array":
+		arr, ok := v.([]any)
+		if !ok {
+			fail("expected an array, got %s", jsonType(v))
+			return
+		}
+		if s.Items != nil {
+			for i, el := range arr {
+				s.Items.validate(el, fmt.Sprintf("%s[%d]", path, i), problems)
+			}
+		}
+
+	case "string":
+		str, ok := v.(string)
+		if !ok {
+			fail("expected a string, got %s", jsonType(v))
+			return
+		}
+		if s.NonEmpty && strings.TrimSpace(str) == "" {
+			fail("must not be empty")
+		}
+		if len(s.Enum) > 0 && !slices.Contains(s.Enum, str) {
+			fail("%q is not one of %s", str, strings.Join(s.Enum, ", "))
+		}
+
+	case "number":
+		n, ok := v.(float64)
+		if !ok {
+			fail("expected a number, got %s", jsonType(v))
+			return
+		}
+		if s.Positive && n <= 0 {
+			fail("must be greater than 0")
+		}
+	}
+}
+
+func join(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func jsonType(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case map[string]any:
+		return "an object"
+	case []any:
+		return "an array"
+	case string:
+		return "a string"
+	case float64:
+		return "a number"
+	case bool:
+		return "a boolean"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
+
+// geminiSchema converts s to Gemini's responseSchema dialect, which
+// spells types in upper case.
+func geminiSchema(s *Schema) map[string]any {
+	out := map[string]any{"type": strings.ToUpper(s.Type)}
+	if len(s.Properties) > 0 {
+		props := map[string]any{}
+		for name, p := range s.Properties {
+			props[name] = geminiSchema(p)
+		}
+		out["properties"] = props
+	}
+	if len(s.Required) > 0 {
+		out["required"] = s.Required
+	}
+	if s.Items != nil {
+		out["items"] = geminiSchema(s.Items)
+	}
+	if len(s.Enum) > 0 {
+		out["enum"] = s.Enum
+	}
+	return out
+}
+
+// openAISchema converts s to the strict JSON Schema OpenAI-compatible
+// servers accept: every property required, no others allowed.
+func openAISchema(s *Schema) map[string]any {
+	out := map[string]any{"type": s.Type}
+	if s.Type == "object" {
+		props := map[string]any{}
+		names := make([]string, 0, len(s.Properties))
+		for name, p := range s.Properties {
+			props[name] = openAISchema(p)
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		out["properties"] = props
+		out["required"] = names
+		out["additionalProperties"] = false
+	}
+	if s.Items != nil {
+		out["items"] = openAISchema(s.Items)
+	}
+	if len(s.Enum) > 0 {
+		out["enum"] = s.Enum
+	}
+	return out
+}
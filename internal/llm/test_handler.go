@@ -20,13 +20,8 @@ Gulab Jamun 120
 GST 10%
 `
 
-	// ✅ Call ParseOCR directly (prompt handled internally)
-	rawJSON, err := client.ParseOCR(context.Background(), ocrText)
-	if err != nil {
-		return nil, err
-	}
-
-	parsed, err := ParseLLMResponse(rawJSON)
+	// ✅ Prompt, schema checks and repair handled by ParseMenu
+	parsed, err := ParseMenu(context.Background(), client, DefaultPromptVersion, ocrText, nil)
 	if err != nil {
 		return nil, err
 	}
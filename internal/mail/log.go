@@ -0,0 +1,48 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+)
+
+// LogMailer is for local development: it logs who each message went to
+// and, when dir is set, writes it to a file so links can be copied out.
+// Bodies carry reset and verification tokens, so they are never logged.
+type LogMailer struct {
+	dir string
+}
+
+func NewLogMailer(dir string) *LogMailer {
+	return &LogMailer{dir: dir}
+}
+
+var unsafeFilenameChars = regexp.MustCompile(`[^a-zA-Z0-9@._-]`)
+
+func (m *LogMailer) Send(ctx context.Context, msg Message) error {
+	log.Printf("[MAIL] to=%s subject=%q", msg.To, msg.Subject)
+
+	if m.dir == "" {
+		return nil
+	}
+
+	if err := os.MkdirAll(m.dir, 0o755); err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf(
+		"%s_%s.eml",
+		time.Now().UTC().Format("20060102T150405.000000000"),
+		unsafeFilenameChars.ReplaceAllString(msg.To, "_"),
+	)
+
+	content := "To: " + msg.To + "\n" +
+		"Subject: " + msg.Subject + "\n\n" +
+		msg.Body
+
+	return os.WriteFile(filepath.Join(m.dir, name), []byte(content), 0o644)
+}
@@ -0,0 +1,38 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"os"
+)
+
+// Message is a plain-text email.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// NewFromEnv picks a mailer from MAILER, which must be "smtp" or "log".
+// There is no default: a missing or misspelled MAILER would otherwise
+// drop every reset and verification mail into the log. The log mailer is
+// refused when APP_ENV is production.
+func NewFromEnv() (Mailer, error) {
+	switch m := os.Getenv("MAILER"); m {
+	case "smtp":
+		return NewSMTPMailerFromEnv()
+	case "log":
+		if os.Getenv("APP_ENV") == "production" {
+			return nil, fmt.Errorf("MAILER=log is not allowed in production")
+		}
+		return NewLogMailer(os.Getenv("MAIL_OUTBOX_DIR")), nil
+	case "":
+		return nil, fmt.Errorf("MAILER is not set (want smtp or log)")
+	default:
+		return nil, fmt.Errorf("unknown MAILER %q (want smtp or log)", m)
+	}
+}
@@ -0,0 +1,72 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+	"os"
+	"strings"
+)
+
+type SMTPMailer struct {
+	host     string
+	port     string
+	username string
+	password string
+	from     string
+}
+
+func NewSMTPMailerFromEnv() (*SMTPMailer, error) {
+	m := &SMTPMailer{
+		host:     os.Getenv("SMTP_HOST"),
+		port:     os.Getenv("SMTP_PORT"),
+		username: os.Getenv("SMTP_USERNAME"),
+		password: os.Getenv("SMTP_PASSWORD"),
+		from:     os.Getenv("MAIL_FROM"),
+	}
+
+	if m.host == "" {
+		return nil, fmt.Errorf("SMTP_HOST is not set")
+	}
+	if m.from == "" {
+		return nil, fmt.Errorf("MAIL_FROM is not set")
+	}
+	if m.port == "" {
+		m.port = "587"
+	}
+
+	return m, nil
+}
+
+func (m *SMTPMailer) Send(ctx context.Context, msg Message) error {
+	if strings.ContainsAny(msg.To, "\r\n") || strings.ContainsAny(msg.Subject, "\r\n") {
+		return fmt.Errorf("invalid header value")
+	}
+
+	var auth smtp.Auth
+	if m.username != "" {
+		auth = smtp.PlainAuth("", m.username, m.password, m.host)
+	}
+
+	body := "From: " + m.from + "\r\n" +
+		"To: " + msg.To + "\r\n" +
+		"Subject: " + msg.Subject + "\r\n" +
+		"MIME-Version: 1.0\r\n" +
+		"Content-Type: text/plain; charset=\"utf-8\"\r\n" +
+		"\r\n" +
+		msg.Body
+
+	// net/smtp has no context support; honour cancellation before dialing.
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return smtp.SendMail(
+		net.JoinHostPort(m.host, m.port),
+		auth,
+		m.from,
+		[]string{msg.To},
+		[]byte(body),
+	)
+}
@@ -4,19 +4,43 @@ import (
 	"fmt"
 	"net/http"
 
+	"bhojanalya/internal/core"
+	"bhojanalya/internal/lang"
+
 	"github.com/gin-gonic/gin"
 )
 
 type Handler struct {
 	service *Service
+	access  core.RestaurantAccess
 }
 
 type AdminHandler struct {
 	service *Service
 }
 
-func NewHandler(service *Service) *Handler {
-	return &Handler{service: service}
+func NewHandler(service *Service, access core.RestaurantAccess) *Handler {
+	return &Handler{service: service, access: access}
+}
+
+// authorize writes a 403 and returns false unless the user may perform
+// action on the restaurant.
+func (h *Handler) authorize(c *gin.Context, restaurantID int, action core.RestaurantAction) bool {
+	ok, err := h.access.CanAccess(
+		c.Request.Context(),
+		restaurantID,
+		c.GetString("userID"),
+		action,
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check permissions"})
+		return false
+	}
+	if !ok {
+		c.JSON(http.StatusForbidden, gin.H{"error": "unauthorized"})
+		return false
+	}
+	return true
 }
 
 func NewAdminHandler(service *Service) *AdminHandler {
@@ -43,6 +67,10 @@ func (h *Handler) Upload(c *gin.Context) {
 		return
 	}
 
+	if !h.authorize(c, restaurantID, core.ActionManageMenu) {
+		return
+	}
+
 	file, header, err := c.Request.FormFile("menu_file")
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "menu_file is required"})
@@ -55,11 +83,19 @@ func (h *Handler) Upload(c *gin.Context) {
 		return
 	}
 
+	// Optional, e.g. "hin+eng"; overrides the restaurant's menu language.
+	languageHint, err := lang.ParseHint(c.PostForm("language"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	objectKey, err := h.service.UploadMenu(
 		c.Request.Context(),
 		restaurantID,
 		file,
 		header.Filename,
+		languageHint,
 	)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
@@ -90,6 +126,10 @@ func (h *Handler) GetMenuStatus(c *gin.Context) {
 		return
 	}
 
+	if !h.authorize(c, restaurantID, core.ActionViewRestaurant) {
+		return
+	}
+
 	status, err := h.service.GetMenuStatus(
 		c.Request.Context(),
 		restaurantID,
@@ -118,6 +158,10 @@ func (h *Handler) RetryMenu(c *gin.Context) {
 		return
 	}
 
+	if !h.authorize(c, restaurantID, core.ActionManageMenu) {
+		return
+	}
+
 	if err := h.service.RetryFailedMenu(
 		c.Request.Context(),
 		restaurantID,
@@ -150,6 +194,32 @@ func (h *AdminHandler) PendingMenus(c *gin.Context) {
 	c.JSON(http.StatusOK, menus)
 }
 
+// --------------------------------------------------
+// Admin: processing history of an upload
+// --------------------------------------------------
+func (h *AdminHandler) Timeline(c *gin.Context) {
+	var menuID int
+	if _, err := fmt.Sscanf(c.Param("id"), "%d", &menuID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "invalid menu id",
+		})
+		return
+	}
+
+	events, err := h.service.Timeline(c.Request.Context(), menuID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"menu_id": menuID,
+		"events":  events,
+	})
+}
+
 // --------------------------------------------------
 // Admin: approve menu
 // --------------------------------------------------
@@ -3,7 +3,10 @@ package menu
 // Item is the normalized, non-nullable menu item
 // used ONLY for pricing, deals, and insights
 type Item struct {
-	Name     string  `json:"name"`
-	Category string  `json:"category"`
-	Price    float64 `json:"price"`
+	Name string `json:"name"`
+	// SearchName is Name romanised to lowercase ASCII so "पनीर टिक्का"
+	// is found by searching "paneer tikka".
+	SearchName string  `json:"search_name,omitempty"`
+	Category   string  `json:"category"`
+	Price      float64 `json:"price"`
 }
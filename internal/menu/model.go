@@ -1,10 +1,60 @@
 package menu
 
+import (
+	"encoding/json"
+	"time"
+)
+
 // ParsedMenu is the validated, normalized menu
 // used by pricing, deals, and competitive insights
 type ParsedMenu struct {
 	Items      []Item  `json:"items"`
 	TaxPercent float64 `json:"tax_percent"`
+
+	// Items the parser could not trust, for the reviewer only; pricing
+	// never sees them
+	Quarantined []QuarantinedItem `json:"quarantined_items,omitempty"`
+
+	// How the parse was produced; nil for menus parsed before it was kept
+	Provenance *Provenance `json:"provenance,omitempty"`
+
+	// The LLM's parse compared with the rule-based parser's
+	CrossCheck *CrossCheck `json:"cross_check,omitempty"`
+}
+
+// CrossCheck compares the items and tax the LLM found with what the
+// rule-based parser found in the same text. Items are compared by name.
+type CrossCheck struct {
+	LLMItems       int      `json:"llm_items"`
+	RuleItems      int      `json:"rule_items"`
+	Matched        int      `json:"matched"`
+	MissedByLLM    []string `json:"missed_by_llm"`
+	NotFoundByRule []string `json:"not_found_by_rules"`
+	PriceMismatch  []string `json:"price_mismatches"`
+	LLMTax         float64  `json:"llm_tax_percent"`
+	RuleTax        float64  `json:"rule_tax_percent"`
+
+	// Flagged when they disagree enough for a reviewer to look closely
+	Flagged bool     `json:"flagged"`
+	Reasons []string `json:"reasons,omitempty"`
+}
+
+// Provenance is the prompt, model and LLM usage behind a parsed menu.
+type Provenance struct {
+	PromptVersion string `json:"prompt_version"`
+	Provider      string `json:"provider"`
+	Model         string `json:"model"`
+	Calls         int    `json:"calls"`
+	InputTokens   int    `json:"input_tokens"`
+	OutputTokens  int    `json:"output_tokens"`
+	LatencyMS     int64  `json:"latency_ms"`
+}
+
+// QuarantinedItem is an item as the LLM returned it, with what was
+// wrong with it.
+type QuarantinedItem struct {
+	Raw      json.RawMessage `json:"raw"`
+	Problems []string        `json:"problems"`
 }
 
 // MenuUpload represents a parsed menu waiting for admin approval
@@ -23,4 +73,50 @@ type MenuUpload struct {
 	// Menu data
 	Filename   string                 `json:"filename"`
 	ParsedData map[string]interface{} `json:"parsed_data"`
+
+	// Storage keys of the upload and, when saved, the preprocessed image
+	// OCR actually read
+	ImageKey          string  `json:"image_key"`
+	ProcessedImageKey *string `json:"processed_image_key"`
+
+	// OCR quality, so reviewers can spot bad scans
+	OCRConfidence        *float64    `json:"ocr_confidence"`
+	LowConfidenceRegions []OCRRegion `json:"low_confidence_regions"`
+
+	// Chunks of a long menu the LLM could not parse; when non-zero the
+	// parsed data is partial
+	LLMChunksFailed int `json:"llm_chunks_failed"`
+
+	// Whether the rule-based cross-check disagreed with the LLM; details
+	// are in parsed_data.cross_check
+	CrossCheckFlagged bool `json:"cross_check_flagged"`
+}
+
+// OCRRegion is a line of the scan that OCR was unsure about.
+// Confidence is 0–100; the box is in image pixels.
+type OCRRegion struct {
+	Page       int     `json:"page"`
+	Text       string  `json:"text"`
+	Confidence float64 `json:"confidence"`
+	Left       int     `json:"left"`
+	Top        int     `json:"top"`
+	Width      int     `json:"width"`
+	Height     int     `json:"height"`
+}
+
+// UploadEvent is one status change in a menu upload's history.
+type UploadEvent struct {
+	MenuID     int    `json:"menu_id"`
+	FromStatus string `json:"from_status,omitempty"` // empty for the first upload
+	ToStatus   string `json:"to_status"`
+
+	// WorkerID is the pipeline worker that made the change; empty for
+	// changes made through the API.
+	WorkerID string `json:"worker_id,omitempty"`
+
+	// DurationMS is how long the upload spent in FromStatus.
+	DurationMS *int64 `json:"duration_ms"`
+
+	Error     string    `json:"error,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
 }
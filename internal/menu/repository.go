@@ -1,6 +1,14 @@
 package menu
 
-import "context"
+import (
+	"context"
+	"errors"
+)
+
+// ErrLeaseLost is returned when the worker writing a result no longer
+// holds the upload: its lease expired and the job was reaped, reclaimed
+// or failed.
+var ErrLeaseLost = errors.New("lease lost")
 
 // Repository defines all database operations for menus
 type Repository interface {
@@ -9,18 +17,22 @@ type Repository interface {
 	// Upload & Parsing (SAFE)
 	// -------------------------------
 
-	// Create OR replace menu upload for a restaurant
+	// Create OR replace menu upload for a restaurant.
+	// An empty languageHint falls back to the restaurant's menu language.
 	UpsertUpload(
 		ctx context.Context,
 		restaurantID int,
 		objectKey string,
 		filename string,
+		languageHint string,
 	) (menuID int, status string, err error)
 
-	// Atomically mark menu as PARSED and save JSON
+	// Atomically mark menu as PARSED and save JSON, if claimedBy
+	// still holds it in PARSING_LLM (ErrLeaseLost otherwise)
 	MarkParsed(
 		ctx context.Context,
 		restaurantID int,
+		claimedBy string,
 		doc map[string]interface{},
 	) error
 
@@ -49,6 +61,16 @@ type Repository interface {
 		restaurantID int,
 	) (city string, cuisine string, err error)
 
+	// -------------------------------
+	// Upload History
+	// -------------------------------
+
+	// Append a status change to menu_upload_events
+	RecordEvent(ctx context.Context, ev UploadEvent) error
+
+	// Every status change of one upload, oldest first
+	ListEvents(ctx context.Context, menuID int) ([]UploadEvent, error)
+
 	// -------------------------------
 	// Admin Approval
 	// -------------------------------
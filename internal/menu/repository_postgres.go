@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"log"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -63,6 +64,7 @@ func (r *PostgresRepository) UpsertUpload(
 	restaurantID int,
 	objectKey string,
 	filename string,
+	languageHint string,
 ) (int, string, error) {
 
 	var (
@@ -90,10 +92,24 @@ func (r *PostgresRepository) UpsertUpload(
 			    original_filename = $2,
 			    status = 'MENU_UPLOADED',
 			    parsed_data = NULL,
+			    ocr_layout = NULL,
+			    ocr_confidence = NULL,
+			    ocr_low_confidence = NULL,
+			    language_hint = NULLIF($4, ''),
+			    ocr_language = NULL,
+			    ocr_page_methods = NULL,
+			    processed_image_key = NULL,
+			    llm_chunks = NULL,
+			    attempts = 0,
+			    retries = 0,
+			    next_attempt_at = NULL,
 			    rejection_reason = NULL,
 			    updated_at = now()
 			WHERE restaurant_id = $3
-		`, objectKey, filename, restaurantID)
+		`, objectKey, filename, restaurantID, languageHint)
+		if err == nil {
+			r.logTransition(ctx, UploadEvent{MenuID: menuID, FromStatus: status, ToStatus: "MENU_UPLOADED"})
+		}
 
 		return menuID, "MENU_UPLOADED", err
 	}
@@ -108,13 +124,17 @@ func (r *PostgresRepository) UpsertUpload(
 			restaurant_id,
 			image_url,
 			original_filename,
+			language_hint,
 			status,
 			created_at,
 			updated_at
 		)
-		VALUES ($1, $2, $3, 'MENU_UPLOADED', now(), now())
+		VALUES ($1, $2, $3, NULLIF($4, ''), 'MENU_UPLOADED', now(), now())
 		RETURNING id
-	`, restaurantID, objectKey, filename).Scan(&menuID)
+	`, restaurantID, objectKey, filename, languageHint).Scan(&menuID)
+	if err == nil {
+		r.logTransition(ctx, UploadEvent{MenuID: menuID, ToStatus: "MENU_UPLOADED"})
+	}
 
 	return menuID, "MENU_UPLOADED", err
 }
@@ -125,6 +145,7 @@ func (r *PostgresRepository) UpsertUpload(
 func (r *PostgresRepository) MarkParsed(
 	ctx context.Context,
 	restaurantID int,
+	claimedBy string,
 	doc map[string]interface{},
 ) error {
 
@@ -139,23 +160,42 @@ func (r *PostgresRepository) MarkParsed(
 	}
 	defer tx.Rollback(ctx)
 
-	cmd, err := tx.Exec(ctx, `
-		UPDATE menu_uploads
+	var menuID int
+	var from string
+	err = tx.QueryRow(ctx, `
+		WITH prev AS (
+			SELECT id, status
+			FROM menu_uploads
+			WHERE restaurant_id = $2
+			  AND claimed_by = $3
+			  AND status = 'PARSING_LLM'
+			FOR UPDATE
+		)
+		UPDATE menu_uploads mu
 		SET parsed_data = $1,
 		    status = 'PARSED',
+		    error_message = NULL,
+		    claimed_by = NULL,
+		    lease_expires_at = NULL,
 		    updated_at = now()
-		WHERE restaurant_id = $2
-	`, data, restaurantID)
+		FROM prev
+		WHERE mu.id = prev.id
+		RETURNING mu.id, prev.status
+	`, data, restaurantID, claimedBy).Scan(&menuID, &from)
 
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrLeaseLost
+	}
 	if err != nil {
 		return err
 	}
 
-	if cmd.RowsAffected() == 0 {
-		return errors.New("no menu row updated")
+	if err := tx.Commit(ctx); err != nil {
+		return err
 	}
 
-	return tx.Commit(ctx)
+	r.logTransition(ctx, UploadEvent{MenuID: menuID, FromStatus: from, ToStatus: "PARSED"})
+	return nil
 }
 
 // --------------------------------------------------
@@ -167,15 +207,33 @@ func (r *PostgresRepository) MarkFailed(
 	reason string,
 ) error {
 
-	_, err := r.db.Exec(ctx, `
-		UPDATE menu_uploads
+	var menuID int
+	var from string
+	err := r.db.QueryRow(ctx, `
+		WITH prev AS (
+			SELECT id, status
+			FROM menu_uploads
+			WHERE restaurant_id = $2
+			FOR UPDATE
+		)
+		UPDATE menu_uploads mu
 		SET status = 'FAILED',
 		    rejection_reason = $1,
 		    updated_at = now()
-		WHERE restaurant_id = $2
-	`, reason, restaurantID)
+		FROM prev
+		WHERE mu.id = prev.id
+		RETURNING mu.id, prev.status
+	`, reason, restaurantID).Scan(&menuID, &from)
 
-	return err
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	r.logTransition(ctx, UploadEvent{MenuID: menuID, FromStatus: from, ToStatus: "FAILED", Error: reason})
+	return nil
 }
 
 // --------------------------------------------------
@@ -186,24 +244,29 @@ func (r *PostgresRepository) RetryFailedMenu(
 	restaurantID int,
 ) error {
 
-	cmd, err := r.db.Exec(ctx, `
+	var menuID int
+	err := r.db.QueryRow(ctx, `
 		UPDATE menu_uploads
 		SET status = 'MENU_UPLOADED',
 		    parsed_data = NULL,
 		    rejection_reason = NULL,
+		    attempts = 0,
+		    retries = 0,
+		    next_attempt_at = NULL,
 		    updated_at = now()
 		WHERE restaurant_id = $1
 		  AND status = 'FAILED'
-	`, restaurantID)
+		RETURNING id
+	`, restaurantID).Scan(&menuID)
 
+	if errors.Is(err, pgx.ErrNoRows) {
+		return errors.New("menu not in FAILED state or not found")
+	}
 	if err != nil {
 		return err
 	}
 
-	if cmd.RowsAffected() == 0 {
-		return errors.New("menu not in FAILED state or not found")
-	}
-
+	r.logTransition(ctx, UploadEvent{MenuID: menuID, FromStatus: "FAILED", ToStatus: "MENU_UPLOADED"})
 	return nil
 }
 
@@ -246,7 +309,17 @@ func (r *PostgresRepository) ListPending(
 			r.opens_at,
 			r.closes_at,
 			mu.original_filename,
-			mu.parsed_data
+			mu.image_url,
+			mu.processed_image_key,
+			mu.parsed_data,
+			mu.ocr_confidence,
+			COALESCE(mu.ocr_low_confidence, '[]'::jsonb),
+			(
+				SELECT count(*)
+				FROM jsonb_array_elements(COALESCE(mu.llm_chunks, '[]'::jsonb)) c
+				WHERE NOT (c->>'ok')::boolean
+			),
+			COALESCE((mu.parsed_data->'cross_check'->>'flagged')::boolean, false)
 		FROM menu_uploads mu
 		JOIN restaurants r
 		  ON r.id = mu.restaurant_id
@@ -272,7 +345,13 @@ func (r *PostgresRepository) ListPending(
 			&m.OpensAt,
 			&m.ClosesAt,
 			&m.Filename,
+			&m.ImageKey,
+			&m.ProcessedImageKey,
 			&m.ParsedData,
+			&m.OCRConfidence,
+			&m.LowConfidenceRegions,
+			&m.LLMChunksFailed,
+			&m.CrossCheckFlagged,
 		); err != nil {
 			return nil, err
 		}
@@ -356,16 +435,34 @@ func (r *PostgresRepository) Reject(
 	reason string,
 ) error {
 
-	_, err := r.db.Exec(ctx, `
-		UPDATE menu_uploads
+	var menuID int
+	var from string
+	err := r.db.QueryRow(ctx, `
+		WITH prev AS (
+			SELECT id, status
+			FROM menu_uploads
+			WHERE restaurant_id = $1
+			FOR UPDATE
+		)
+		UPDATE menu_uploads mu
 		SET status = 'REJECTED',
 		    approved_by = $2,
 		    rejection_reason = $3,
 		    updated_at = now()
-		WHERE restaurant_id = $1
-	`, restaurantID, adminID, reason)
+		FROM prev
+		WHERE mu.id = prev.id
+		RETURNING mu.id, prev.status
+	`, restaurantID, adminID, reason).Scan(&menuID, &from)
 
-	return err
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	r.logTransition(ctx, UploadEvent{MenuID: menuID, FromStatus: from, ToStatus: "REJECTED", Error: reason})
+	return nil
 }
 
 func (r *PostgresRepository) ApproveByRestaurant(
@@ -421,3 +518,85 @@ func (r *PostgresRepository) ApproveByRestaurant(
 
 	return tx.Commit(ctx)
 }
+
+// --------------------------------------------------
+// UPLOAD HISTORY (menu_upload_events)
+// --------------------------------------------------
+
+// RecordEvent appends ev to the upload's history. DurationMS is derived
+// from the previous event, so callers leave it nil.
+func (r *PostgresRepository) RecordEvent(
+	ctx context.Context,
+	ev UploadEvent,
+) error {
+
+	_, err := r.db.Exec(ctx, `
+		INSERT INTO menu_upload_events (
+			menu_upload_id,
+			from_status,
+			to_status,
+			worker_id,
+			duration_ms,
+			error
+		)
+		SELECT $1, NULLIF($2, ''), $3, NULLIF($4, ''),
+		       (
+		           SELECT (extract(epoch FROM now() - max(created_at)) * 1000)::bigint
+		           FROM menu_upload_events
+		           WHERE menu_upload_id = $1
+		       ),
+		       NULLIF($5, '')
+	`, ev.MenuID, ev.FromStatus, ev.ToStatus, ev.WorkerID, ev.Error)
+
+	return err
+}
+
+func (r *PostgresRepository) ListEvents(
+	ctx context.Context,
+	menuID int,
+) ([]UploadEvent, error) {
+
+	rows, err := r.db.Query(ctx, `
+		SELECT menu_upload_id,
+		       COALESCE(from_status, ''),
+		       to_status,
+		       COALESCE(worker_id, ''),
+		       duration_ms,
+		       COALESCE(error, ''),
+		       created_at
+		FROM menu_upload_events
+		WHERE menu_upload_id = $1
+		ORDER BY created_at, id
+	`, menuID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []UploadEvent{}
+	for rows.Next() {
+		var ev UploadEvent
+		if err := rows.Scan(
+			&ev.MenuID,
+			&ev.FromStatus,
+			&ev.ToStatus,
+			&ev.WorkerID,
+			&ev.DurationMS,
+			&ev.Error,
+			&ev.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		events = append(events, ev)
+	}
+
+	return events, rows.Err()
+}
+
+// logTransition records a status change this repository just made. The
+// change itself has already happened, so a failure is only logged.
+func (r *PostgresRepository) logTransition(ctx context.Context, ev UploadEvent) {
+	if err := r.RecordEvent(ctx, ev); err != nil {
+		log.Printf("[MENU][%d] Could not record %s event: %v", ev.MenuID, ev.ToStatus, err)
+	}
+}
@@ -27,11 +27,13 @@ func NewService(repo Repository, storage Storage) *Service {
 // --------------------------------------------------
 // Upload Menu (ONE MENU PER RESTAURANT)
 // --------------------------------------------------
+// languageHint is a normalised Tesseract list ("hin+eng") or "".
 func (s *Service) UploadMenu(
 	ctx context.Context,
 	restaurantID int,
 	file multipart.File,
 	filename string,
+	languageHint string,
 ) (string, error) {
 
 	ext := strings.ToLower(filepath.Ext(filename))
@@ -55,6 +57,7 @@ func (s *Service) UploadMenu(
 		restaurantID,
 		key,
 		filename,
+		languageHint,
 	)
 	if err != nil {
 		return "", err
@@ -70,9 +73,12 @@ func (s *Service) UploadMenu(
 // --------------------------------------------------
 // Persist Parsed Menu (ATOMIC)
 // --------------------------------------------------
+// claimedBy is the pipeline worker holding the upload; the write is
+// refused with ErrLeaseLost once another worker or the reaper has it.
 func (s *Service) SaveParsedResult(
 	ctx context.Context,
 	restaurantID int,
+	claimedBy string,
 	menu *ParsedMenu,
 	cost *CostForTwo,
 ) error {
@@ -85,10 +91,19 @@ func (s *Service) SaveParsedResult(
 		"items":        menu.Items,
 		"tax_percent":  menu.TaxPercent,
 		"cost_for_two": cost,
-		"version":      "v1",
+	}
+	if len(menu.Quarantined) > 0 {
+		doc["quarantined_items"] = menu.Quarantined
+	}
+	if menu.Provenance != nil {
+		doc["provenance"] = menu.Provenance
+		doc["version"] = menu.Provenance.PromptVersion
+	}
+	if menu.CrossCheck != nil {
+		doc["cross_check"] = menu.CrossCheck
 	}
 
-	return s.repo.MarkParsed(ctx, restaurantID, doc)
+	return s.repo.MarkParsed(ctx, restaurantID, claimedBy, doc)
 }
 
 // --------------------------------------------------
@@ -152,6 +167,26 @@ func (s *Service) GetMenuContext(
 	return s.repo.GetMenuContext(ctx, restaurantID)
 }
 
+// --------------------------------------------------
+// Upload History
+// --------------------------------------------------
+
+// RecordEvent appends a pipeline status change to the upload's history.
+func (s *Service) RecordEvent(
+	ctx context.Context,
+	ev UploadEvent,
+) error {
+	return s.repo.RecordEvent(ctx, ev)
+}
+
+// Timeline returns every status change of an upload, oldest first.
+func (s *Service) Timeline(
+	ctx context.Context,
+	menuID int,
+) ([]UploadEvent, error) {
+	return s.repo.ListEvents(ctx, menuID)
+}
+
 // --------------------------------------------------
 // ADMIN APPROVAL — FINAL PHASE
 // --------------------------------------------------
@@ -1,51 +1,161 @@
 package middleware
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"strings"
 	"log"
 	"bhojanalya/internal/auth"
+	"bhojanalya/internal/core"
 
 	"github.com/gin-gonic/gin"
 )
 
-func AuthMiddleware() gin.HandlerFunc {
+// RevocationChecker reports whether an access token (jti) or its session was revoked.
+type RevocationChecker interface {
+	IsRevoked(ctx context.Context, jti string, sessionID string) (bool, error)
+}
+
+// AuthMiddleware validates the bearer token. When revocations is nil the
+// revocation list is not consulted. Tokens that still wait for a second
+// factor are rejected.
+func AuthMiddleware(revocations RevocationChecker) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		authHeader := c.GetHeader("Authorization")
+		claims, ok := authenticate(c, revocations)
+		if !ok {
+			return
+		}
 
-		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "missing authorization header"})
+		if claims.MFAPending {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "two-factor verification required"})
 			c.Abort()
 			return
 		}
 
-		parts := strings.Split(authHeader, " ")
-		if len(parts) != 2 || parts[0] != "Bearer" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid authorization format, use 'Bearer <token>'"})
-			c.Abort()
+		log.Printf(
+			"[AUTH DEBUG] userID=%v (type=%T), email=%s, role=%s",
+			claims.UserID,
+			claims.UserID,
+			claims.Email,
+			claims.Role,
+	)
+
+
+		// Attach user info to request context
+		c.Set("userID", claims.UserID)
+		c.Set("userEmail", claims.Email)
+		c.Set("userRole", claims.Role)
+		c.Set("tokenClaims", claims)
+		c.Next()
+	}
+}
+
+// APIKeyAuthenticator resolves an X-API-Key header value.
+type APIKeyAuthenticator interface {
+	Authenticate(ctx context.Context, key string) (*auth.APIKeyPrincipal, error)
+}
+
+// AuthOrAPIKeyMiddleware accepts an X-API-Key header and otherwise falls
+// back to AuthMiddleware. Only mount it on routes meant for integrations;
+// account and session routes stay bearer-only.
+func AuthOrAPIKeyMiddleware(revocations RevocationChecker, keys APIKeyAuthenticator) gin.HandlerFunc {
+	bearer := AuthMiddleware(revocations)
+
+	return func(c *gin.Context) {
+		key := c.GetHeader("X-API-Key")
+		if key == "" {
+			bearer(c)
 			return
 		}
 
-		userID, email, role, err := auth.ValidateToken(parts[1])
+		principal, err := keys.Authenticate(c.Request.Context(), key)
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid token: " + err.Error()})
+			if errors.Is(err, auth.ErrInvalidAPIKey) {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid api key"})
+			} else {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to verify api key"})
+			}
 			c.Abort()
 			return
 		}
 
-		log.Printf(
-			"[AUTH DEBUG] userID=%v (type=%T), email=%s, role=%s",
-			userID,
-			userID,
-			email,
-			role,
-	)
+		c.Set("userID", principal.UserID)
+		c.Set("userEmail", principal.Email)
+		c.Set("userRole", principal.Role)
+		c.Set("apiKeyID", principal.KeyID)
+		c.Set("apiKeyScopes", principal.Scopes)
 
+		if principal.RestaurantID != nil {
+			c.Request = c.Request.WithContext(
+				core.WithBoundRestaurant(c.Request.Context(), *principal.RestaurantID),
+			)
+		}
 
-		// Attach user info to request context
-		c.Set("userID", userID)
-		c.Set("userEmail", email)
-		c.Set("userRole", role)
 		c.Next()
 	}
 }
+
+// MFAPendingMiddleware only accepts the short-lived token login returns
+// when a second factor is still required.
+func MFAPendingMiddleware(revocations RevocationChecker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, ok := authenticate(c, revocations)
+		if !ok {
+			return
+		}
+
+		if !claims.MFAPending {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "mfa token required"})
+			c.Abort()
+			return
+		}
+
+		c.Set("userID", claims.UserID)
+		c.Set("userEmail", claims.Email)
+		c.Set("tokenClaims", claims)
+		c.Next()
+	}
+}
+
+// authenticate parses the bearer token and checks revocation. It writes the
+// error response and aborts when the token is not acceptable.
+func authenticate(c *gin.Context, revocations RevocationChecker) (*auth.TokenClaims, bool) {
+	authHeader := c.GetHeader("Authorization")
+
+	if authHeader == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "missing authorization header"})
+		c.Abort()
+		return nil, false
+	}
+
+	parts := strings.Split(authHeader, " ")
+	if len(parts) != 2 || parts[0] != "Bearer" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid authorization format, use 'Bearer <token>'"})
+		c.Abort()
+		return nil, false
+	}
+
+	claims, err := auth.ParseToken(parts[1])
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid token: " + err.Error()})
+		c.Abort()
+		return nil, false
+	}
+
+	if revocations != nil {
+		revoked, err := revocations.IsRevoked(c.Request.Context(), claims.JTI, claims.SessionID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to verify token"})
+			c.Abort()
+			return nil, false
+		}
+		if revoked {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "token revoked"})
+			c.Abort()
+			return nil, false
+		}
+	}
+
+	return claims, true
+}
@@ -1,11 +1,14 @@
 package middleware
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"bhojanalya/internal/auth"
+	"bhojanalya/internal/core"
 
 	"github.com/gin-gonic/gin"
 )
@@ -13,7 +16,7 @@ import (
 // TestAuthMiddleware_MissingAuthHeader tests the middleware with missing Authorization header
 func TestAuthMiddleware_MissingAuthHeader(t *testing.T) {
 	router := gin.New()
-	router.Use(AuthMiddleware())
+	router.Use(AuthMiddleware(nil))
 	router.GET("/test", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"message": "success"})
 	})
@@ -30,7 +33,7 @@ func TestAuthMiddleware_MissingAuthHeader(t *testing.T) {
 // TestAuthMiddleware_InvalidAuthFormat tests the middleware with invalid Bearer format
 func TestAuthMiddleware_InvalidAuthFormat(t *testing.T) {
 	router := gin.New()
-	router.Use(AuthMiddleware())
+	router.Use(AuthMiddleware(nil))
 	router.GET("/test", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"message": "success"})
 	})
@@ -48,7 +51,7 @@ func TestAuthMiddleware_InvalidAuthFormat(t *testing.T) {
 // TestAuthMiddleware_InvalidToken tests the middleware with an invalid token
 func TestAuthMiddleware_InvalidToken(t *testing.T) {
 	router := gin.New()
-	router.Use(AuthMiddleware())
+	router.Use(AuthMiddleware(nil))
 	router.GET("/test", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"message": "success"})
 	})
@@ -75,7 +78,7 @@ func TestAuthMiddleware_ValidToken(t *testing.T) {
 	}
 
 	router := gin.New()
-	router.Use(AuthMiddleware())
+	router.Use(AuthMiddleware(nil))
 	router.GET("/test", func(c *gin.Context) {
 		userID, _ := c.Get("userID")
 		userEmail, _ := c.Get("userEmail")
@@ -94,3 +97,255 @@ func TestAuthMiddleware_ValidToken(t *testing.T) {
 		t.Errorf("expected status %d, got %d", http.StatusOK, w.Code)
 	}
 }
+
+type revokeAll struct{}
+
+func (revokeAll) IsRevoked(ctx context.Context, jti string, sessionID string) (bool, error) {
+	return true, nil
+}
+
+// TestAuthMiddleware_RevokedToken tests that a revoked token is rejected
+func TestAuthMiddleware_RevokedToken(t *testing.T) {
+	t.Setenv("JWT_SECRET", "test-secret-key-for-testing-only")
+
+	token, err := auth.GenerateToken("test-user-id", "test@example.com", "restaurant")
+	if err != nil {
+		t.Fatalf("failed to generate test token: %v", err)
+	}
+
+	router := gin.New()
+	router.Use(AuthMiddleware(revokeAll{}))
+	router.GET("/test", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"message": "success"})
+	})
+
+	req := httptest.NewRequest("GET", "/test", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("expected status %d, got %d", http.StatusUnauthorized, w.Code)
+	}
+}
+
+// TestAuthMiddleware_MFAPendingToken tests that a token from the password
+// step alone cannot reach protected routes
+func TestAuthMiddleware_MFAPendingToken(t *testing.T) {
+	t.Setenv("JWT_SECRET", "test-secret-key-for-testing-only")
+
+	token, _, err := auth.GenerateMFAPendingToken(&auth.User{
+		ID:    "test-user-id",
+		Email: "admin@example.com",
+		Role:  string(auth.RoleAdmin),
+	})
+	if err != nil {
+		t.Fatalf("failed to generate test token: %v", err)
+	}
+
+	router := gin.New()
+	router.GET("/protected", AuthMiddleware(nil), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"message": "success"})
+	})
+	router.POST("/mfa/verify", MFAPendingMiddleware(nil), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"message": "success"})
+	})
+
+	req := httptest.NewRequest("GET", "/protected", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("expected status %d on protected route, got %d", http.StatusUnauthorized, w.Code)
+	}
+
+	req = httptest.NewRequest("POST", "/mfa/verify", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("expected status %d on mfa route, got %d", http.StatusOK, w.Code)
+	}
+
+	// A full access token is not accepted on the mfa step
+	full, _ := auth.GenerateToken("test-user-id", "admin@example.com", string(auth.RoleAdmin))
+	req = httptest.NewRequest("POST", "/mfa/verify", nil)
+	req.Header.Set("Authorization", "Bearer "+full)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("expected status %d for full token on mfa route, got %d", http.StatusUnauthorized, w.Code)
+	}
+}
+
+type fixedVerification bool
+
+func (v fixedVerification) IsEmailVerified(ctx context.Context, userID string) (bool, error) {
+	return bool(v), nil
+}
+
+// TestRequireVerifiedEmail tests that unverified users are blocked
+func TestRequireVerifiedEmail(t *testing.T) {
+	for verified, want := range map[bool]int{true: http.StatusOK, false: http.StatusForbidden} {
+		router := gin.New()
+		router.Use(func(c *gin.Context) { c.Set("userID", "test-user-id") })
+		router.Use(RequireVerifiedEmail(fixedVerification(verified)))
+		router.GET("/test", func(c *gin.Context) {
+			c.JSON(http.StatusOK, gin.H{"message": "success"})
+		})
+
+		req := httptest.NewRequest("GET", "/test", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != want {
+			t.Errorf("verified=%v: expected status %d, got %d", verified, want, w.Code)
+		}
+	}
+}
+
+type rolePermissions map[string][]auth.Permission
+
+func (r rolePermissions) HasPermission(ctx context.Context, role string, perm auth.Permission) (bool, error) {
+	for _, p := range r[role] {
+		if p == perm {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// TestRequirePermission tests that every listed permission is required
+func TestRequirePermission(t *testing.T) {
+	checker := rolePermissions{
+		"REVIEWER": {auth.PermMenusReview},
+		"ADMIN":    {auth.PermRestaurantsApprove, auth.PermDealsApprove},
+	}
+
+	cases := []struct {
+		role string
+		want int
+	}{
+		{"ADMIN", http.StatusOK},
+		{"REVIEWER", http.StatusForbidden},
+		{"", http.StatusForbidden},
+	}
+
+	for _, tc := range cases {
+		router := gin.New()
+		router.Use(func(c *gin.Context) { c.Set("userRole", tc.role) })
+		router.POST("/approve",
+			RequirePermission(checker, auth.PermRestaurantsApprove, auth.PermDealsApprove),
+			func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"message": "success"}) },
+		)
+
+		req := httptest.NewRequest("POST", "/approve", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != tc.want {
+			t.Errorf("role=%q: expected status %d, got %d", tc.role, tc.want, w.Code)
+		}
+	}
+}
+
+// TestRequirePolicy_UnlistedRoute tests that routes missing from the policy are refused
+func TestRequirePolicy_UnlistedRoute(t *testing.T) {
+	checker := rolePermissions{"ADMIN": {auth.PermMenusReview}}
+	policy := map[string][]auth.Permission{
+		"GET /admin/menus/pending": {auth.PermMenusReview},
+	}
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) { c.Set("userRole", "ADMIN") })
+	router.Use(RequirePolicy(checker, policy))
+	ok := func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"message": "success"}) }
+	router.GET("/admin/menus/pending", ok)
+	router.GET("/admin/secret", ok)
+
+	for path, want := range map[string]int{
+		"/admin/menus/pending": http.StatusOK,
+		"/admin/secret":        http.StatusForbidden,
+	} {
+		req := httptest.NewRequest("GET", path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != want {
+			t.Errorf("%s: expected status %d, got %d", path, want, w.Code)
+		}
+	}
+}
+
+type staticAPIKeys map[string]*auth.APIKeyPrincipal
+
+func (k staticAPIKeys) Authenticate(ctx context.Context, key string) (*auth.APIKeyPrincipal, error) {
+	if p, ok := k[key]; ok {
+		return p, nil
+	}
+	return nil, auth.ErrInvalidAPIKey
+}
+
+// TestAuthOrAPIKeyMiddleware tests key authentication, scopes and restaurant binding
+func TestAuthOrAPIKeyMiddleware(t *testing.T) {
+	restaurantID := 7
+	keys := staticAPIKeys{
+		"bk_deals": {
+			UserID:       "owner-1",
+			Role:         "RESTAURANT",
+			Scopes:       []auth.Permission{auth.PermRestaurantsManage},
+			RestaurantID: &restaurantID,
+		},
+		"bk_readonly": {
+			UserID: "analyst-1",
+			Role:   "ANALYST",
+			Scopes: []auth.Permission{},
+		},
+	}
+	checker := rolePermissions{
+		"RESTAURANT": {auth.PermRestaurantsManage},
+		"ANALYST":    {auth.PermRestaurantsRead},
+	}
+
+	router := gin.New()
+	router.Use(AuthOrAPIKeyMiddleware(nil, keys))
+	router.GET("/deals", RequirePermission(checker, auth.PermRestaurantsManage), func(c *gin.Context) {
+		bound, _ := core.BoundRestaurant(c.Request.Context())
+		c.JSON(http.StatusOK, gin.H{"user": c.GetString("userID"), "restaurant": bound})
+	})
+	router.GET("/reports", RequirePermission(checker, auth.PermRestaurantsRead), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"message": "success"})
+	})
+
+	cases := []struct {
+		path string
+		key  string
+		want int
+	}{
+		{"/deals", "bk_deals", http.StatusOK},
+		{"/deals", "bk_unknown", http.StatusUnauthorized},
+		{"/deals", "", http.StatusUnauthorized},
+		// The role allows it but the key was not given the scope.
+		{"/reports", "bk_readonly", http.StatusForbidden},
+		{"/reports", "bk_deals", http.StatusForbidden},
+	}
+
+	for _, tc := range cases {
+		req := httptest.NewRequest("GET", tc.path, nil)
+		if tc.key != "" {
+			req.Header.Set("X-API-Key", tc.key)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != tc.want {
+			t.Errorf("%s with %q: expected status %d, got %d", tc.path, tc.key, tc.want, w.Code)
+		}
+		if tc.want == http.StatusOK && !strings.Contains(w.Body.String(), `"restaurant":7`) {
+			t.Errorf("%s: expected request bound to restaurant 7, got %s", tc.path, w.Body.String())
+		}
+	}
+}
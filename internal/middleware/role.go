@@ -1,22 +1,60 @@
 package middleware
 
-import "github.com/gin-gonic/gin"
+import (
+	"context"
+	"slices"
 
-func RequireRole(allowedRoles ...string) gin.HandlerFunc {
+	"bhojanalya/internal/auth"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PermissionChecker resolves a platform role to its permissions.
+type PermissionChecker interface {
+	HasPermission(ctx context.Context, role string, perm auth.Permission) (bool, error)
+}
+
+// RequirePermission must run after AuthMiddleware. The caller's role must
+// grant every permission listed and, for API key requests, so must the
+// key's scopes.
+func RequirePermission(checker PermissionChecker, perms ...auth.Permission) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		role, exists := c.Get("userRole")
-		if !exists {
+		role := c.GetString("userRole")
+		if role == "" {
 			c.AbortWithStatusJSON(403, gin.H{"error": "role missing"})
 			return
 		}
 
-		for _, allowed := range allowedRoles {
-			if role == allowed {
-				c.Next()
+		for _, perm := range perms {
+			ok, err := checker.HasPermission(c.Request.Context(), role, perm)
+			if err != nil {
+				c.AbortWithStatusJSON(500, gin.H{"error": "failed to check permissions"})
+				return
+			}
+			if !ok {
+				c.AbortWithStatusJSON(403, gin.H{"error": "forbidden"})
+				return
+			}
+			if scopes, isKey := c.Get("apiKeyScopes"); isKey && !slices.Contains(scopes.([]auth.Permission), perm) {
+				c.AbortWithStatusJSON(403, gin.H{"error": "api key scope does not allow this"})
 				return
 			}
 		}
 
-		c.AbortWithStatusJSON(403, gin.H{"error": "forbidden"})
+		c.Next()
+	}
+}
+
+// RequirePolicy looks up the matched route ("METHOD /full/path") in policy
+// and applies RequirePermission. Routes missing from the policy are
+// refused, so a new endpoint cannot be exposed by accident.
+func RequirePolicy(checker PermissionChecker, policy map[string][]auth.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		perms, ok := policy[c.Request.Method+" "+c.FullPath()]
+		if !ok {
+			c.AbortWithStatusJSON(403, gin.H{"error": "forbidden"})
+			return
+		}
+		RequirePermission(checker, perms...)(c)
 	}
 }
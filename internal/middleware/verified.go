@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+)
+
+// EmailVerificationChecker reports whether a user has confirmed their email.
+type EmailVerificationChecker interface {
+	IsEmailVerified(ctx context.Context, userID string) (bool, error)
+}
+
+// RequireVerifiedEmail must run after AuthMiddleware.
+func RequireVerifiedEmail(checker EmailVerificationChecker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.GetString("userID")
+		if userID == "" {
+			c.AbortWithStatusJSON(401, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		verified, err := checker.IsEmailVerified(c.Request.Context(), userID)
+		if err != nil {
+			c.AbortWithStatusJSON(500, gin.H{"error": "failed to check email verification"})
+			return
+		}
+
+		if !verified {
+			c.AbortWithStatusJSON(403, gin.H{"error": "email not verified"})
+			return
+		}
+
+		c.Next()
+	}
+}
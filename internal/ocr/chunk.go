@@ -0,0 +1,236 @@
+package ocr
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"bhojanalya/internal/llm"
+)
+
+// pageBreak separates pages in PDF OCR text.
+const pageBreak = "---PAGE BREAK---"
+
+// ChunkConfig sizes the pieces long OCR text is parsed in.
+type ChunkConfig struct {
+	// MaxChars caps one chunk; it never exceeds llm.MaxChunkChars, so
+	// the reply fits in the output limit.
+	MaxChars int
+
+	// Overlap is how many lines of a chunk are repeated at the start of
+	// the next, so an item cut by the boundary is whole in one of them.
+	Overlap int
+
+	// Concurrency caps chunks of one menu in flight at once. Every call
+	// still goes through the pipeline's LLM rate limit.
+	Concurrency int
+}
+
+// ChunkConfigFromEnv reads LLM_CHUNK_CHARS (default 6000, at most
+// llm.MaxChunkChars), LLM_CHUNK_OVERLAP_LINES (3) and
+// LLM_CHUNK_CONCURRENCY (3).
+func ChunkConfigFromEnv() ChunkConfig {
+	return ChunkConfig{
+		MaxChars:    min(max(envInt("LLM_CHUNK_CHARS", 6000), 500), llm.MaxChunkChars),
+		Overlap:     envInt("LLM_CHUNK_OVERLAP_LINES", 3),
+		Concurrency: max(envInt("LLM_CHUNK_CONCURRENCY", 3), 1),
+	}
+}
+
+// ChunkResult is the outcome of parsing one chunk, as stored in
+// menu_uploads.llm_chunks.
+type ChunkResult struct {
+	Index int    `json:"index"`
+	Hash  string `json:"hash"` // of the chunk text, to reuse results on retry
+	Chars int    `json:"chars"`
+	OK    bool   `json:"ok"`
+
+	Items       []llm.ParsedItem      `json:"items,omitempty"`
+	Quarantined []llm.QuarantinedItem `json:"quarantined,omitempty"`
+	TaxPercent  float64               `json:"tax_percent,omitempty"`
+	Provenance  *llm.Provenance       `json:"provenance,omitempty"`
+	Error       string                `json:"error,omitempty"`
+}
+
+func chunkHash(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:8])
+}
+
+// splitChunks packs pages into chunks of at most maxChars. Pages are kept
+// whole where they fit; longer ones are split at blank lines, then at line
+// ends. Each chunk after the first starts with the last overlap lines of
+// the one before.
+func splitChunks(pages []string, maxChars, overlap int) []string {
+	var units []string
+	for _, page := range pages {
+		page = strings.TrimSpace(page)
+		if page != "" {
+			units = append(units, splitUnit(page, maxChars)...)
+		}
+	}
+
+	var chunks []string
+	var cur strings.Builder
+	for _, u := range units {
+		if cur.Len() > 0 && cur.Len()+2+len(u) > maxChars {
+			prev := cur.String()
+			chunks = append(chunks, prev)
+			cur.Reset()
+
+			if tail := lastLines(prev, overlap); tail != "" && len(tail)+2+len(u) <= maxChars {
+				cur.WriteString(tail)
+			}
+		}
+		if cur.Len() > 0 {
+			cur.WriteString("\n\n")
+		}
+		cur.WriteString(u)
+	}
+	if cur.Len() > 0 {
+		chunks = append(chunks, cur.String())
+	}
+	return chunks
+}
+
+// splitUnit breaks text longer than maxChars at blank lines, then line
+// ends, and as a last resort mid-line on a character boundary.
+func splitUnit(text string, maxChars int) []string {
+	if len(text) <= maxChars {
+		return []string{text}
+	}
+
+	for _, sep := range []string{"\n\n", "\n"} {
+		parts := strings.Split(text, sep)
+		if len(parts) == 1 {
+			continue
+		}
+
+		var out []string
+		var cur strings.Builder
+		for _, p := range parts {
+			if cur.Len() > 0 && cur.Len()+len(sep)+len(p) > maxChars {
+				out = append(out, splitUnit(cur.String(), maxChars)...)
+				cur.Reset()
+			}
+			if cur.Len() > 0 {
+				cur.WriteString(sep)
+			}
+			cur.WriteString(p)
+		}
+		if cur.Len() > 0 {
+			out = append(out, splitUnit(cur.String(), maxChars)...)
+		}
+		return out
+	}
+
+	cut := maxChars
+	for cut > 0 && !utf8.RuneStart(text[cut]) {
+		cut--
+	}
+	return append([]string{text[:cut]}, splitUnit(text[cut:], maxChars)...)
+}
+
+// minHalveChars is the shortest chunk halveChunk splits; a reply for less
+// text that still overflows the output limit is not a menu.
+const minHalveChars = 200
+
+// halveChunk splits a chunk whose reply was cut off at the output limit
+// in two at the line end nearest its middle. It reports false for a chunk
+// too short or without line ends to split.
+func halveChunk(text string) (string, string, bool) {
+	if len(text) < minHalveChars {
+		return "", "", false
+	}
+	mid := len(text) / 2
+	before := strings.LastIndex(text[:mid], "\n")
+	after := strings.Index(text[mid:], "\n")
+	cut := before
+	if after >= 0 && (before < 0 || after < mid-before) {
+		cut = mid + after
+	}
+	if cut < 0 {
+		return "", "", false
+	}
+	return strings.TrimSpace(text[:cut]), strings.TrimSpace(text[cut+1:]), true
+}
+
+func lastLines(text string, n int) string {
+	if n <= 0 {
+		return ""
+	}
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// mergeChunks combines the items of every successful chunk in order. An
+// item seen twice with the same name and price, as happens in the overlap
+// between chunks, is kept once; quarantined items are all kept. It
+// reports false if no chunk succeeded.
+func mergeChunks(results []ChunkResult) (*llm.ParsedOCRResult, bool) {
+	merged := &llm.ParsedOCRResult{Items: []llm.ParsedItem{}}
+	seen := map[string]bool{}
+	var taxes []float64
+	ok := false
+
+	for _, r := range results {
+		if !r.OK {
+			continue
+		}
+		ok = true
+
+		for _, it := range r.Items {
+			key := strings.Join(strings.Fields(strings.ToLower(it.Name)), " ") + "|" + strconv.FormatFloat(it.Price, 'f', 2, 64)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			merged.Items = append(merged.Items, it)
+		}
+		merged.Quarantined = append(merged.Quarantined, r.Quarantined...)
+		if r.Provenance != nil {
+			if merged.Provenance == nil {
+				merged.Provenance = &llm.Provenance{}
+			}
+			merged.Provenance.Add(*r.Provenance)
+		}
+		if r.TaxPercent > 0 {
+			taxes = append(taxes, r.TaxPercent)
+		}
+	}
+
+	merged.TaxPercent = reconcileTax(taxes)
+	return merged, ok
+}
+
+// reconcileTax picks the tax percentage most chunks agree on. Chunks
+// without a tax line say nothing and are not counted; a tie goes to the
+// higher rate, so cost for two is not understated.
+func reconcileTax(taxes []float64) float64 {
+	counts := map[float64]int{}
+	for _, t := range taxes {
+		counts[t]++
+	}
+
+	rates := make([]float64, 0, len(counts))
+	for t := range counts {
+		rates = append(rates, t)
+	}
+	sort.Slice(rates, func(i, j int) bool {
+		if counts[rates[i]] != counts[rates[j]] {
+			return counts[rates[i]] > counts[rates[j]]
+		}
+		return rates[i] > rates[j]
+	})
+
+	if len(rates) == 0 {
+		return 0
+	}
+	return rates[0]
+}
@@ -0,0 +1,202 @@
+package ocr
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"bhojanalya/internal/llm"
+)
+
+func TestSplitChunksKeepsPagesWholeAndOverlaps(t *testing.T) {
+	page := func(n int) string {
+		var lines []string
+		for i := 1; i <= 10; i++ {
+			lines = append(lines, fmt.Sprintf("Dish %d-%d 100", n, i))
+		}
+		return strings.Join(lines, "\n")
+	}
+	pages := []string{page(1), page(2), page(3), "  "}
+
+	chunks := splitChunks(pages, len(page(1))+100, 2)
+	if len(chunks) != 3 {
+		t.Fatalf("expected a chunk per page, got %d: %q", len(chunks), chunks)
+	}
+	for i, c := range chunks {
+		if len(c) > len(page(1))+100 {
+			t.Fatalf("chunk %d is %d chars, over the limit", i, len(c))
+		}
+		if !strings.HasSuffix(c, fmt.Sprintf("Dish %d-10 100", i+1)) {
+			t.Fatalf("chunk %d does not end with its page: %q", i, c)
+		}
+	}
+	if !strings.HasPrefix(chunks[1], "Dish 1-9 100\nDish 1-10 100\n\nDish 2-1 100") {
+		t.Fatalf("expected the last two lines of page 1 repeated, got %q", chunks[1][:40])
+	}
+}
+
+func TestSplitChunksBreaksLongPages(t *testing.T) {
+	var lines []string
+	for i := 0; i < 200; i++ {
+		lines = append(lines, fmt.Sprintf("पनीर टिक्का %d ₹२८०", i))
+	}
+	text := strings.Join(lines, "\n")
+	unbroken := strings.Repeat("मसाला", 500)
+
+	chunks := splitChunks([]string{text, unbroken}, 1000, 0)
+	var joined strings.Builder
+	for i, c := range chunks {
+		if len(c) > 1000 {
+			t.Fatalf("chunk %d is %d bytes, over the limit", i, len(c))
+		}
+		if !utf8.ValidString(c) {
+			t.Fatalf("chunk %d cut a character in half", i)
+		}
+		joined.WriteString(c)
+	}
+	for _, l := range lines {
+		if !strings.Contains(joined.String(), l) {
+			t.Fatalf("line %q lost or split", l)
+		}
+	}
+	if strings.Count(joined.String(), "मसाला") != 500 {
+		t.Fatal("unbroken text lost in splitting")
+	}
+}
+
+func TestMergeChunksDeduplicatesAndReconcilesTax(t *testing.T) {
+	results := []ChunkResult{
+		{Index: 0, OK: true, TaxPercent: 5, Items: []llm.ParsedItem{
+			{Name: "Paneer Tikka", Category: "starter", Price: 220},
+			{Name: "Dal Makhani", Category: "main_course", Price: 280},
+		}},
+		{Index: 1, Error: "timeout"},
+		{Index: 2, OK: true, TaxPercent: 18, Items: []llm.ParsedItem{
+			{Name: "dal  makhani", Category: "main_course", Price: 280},
+			{Name: "Dal Makhani", Category: "main_course", Price: 320}, // full portion
+		}},
+		{Index: 3, OK: true, TaxPercent: 5, Items: []llm.ParsedItem{
+			{Name: "Gulab Jamun", Category: "dessert", Price: 90},
+		}, Quarantined: []llm.QuarantinedItem{
+			{Raw: []byte(`{"name":"Kulfi","price":0}`), Problems: []string{"items[1].price: must be greater than 0"}},
+		}},
+	}
+
+	merged, ok := mergeChunks(results)
+	if !ok {
+		t.Fatal("expected a merged result")
+	}
+	if len(merged.Items) != 4 {
+		t.Fatalf("expected 4 items, got %+v", merged.Items)
+	}
+	if merged.Items[1].Name != "Dal Makhani" || merged.Items[2].Price != 320 {
+		t.Fatalf("expected first occurrences kept in order, got %+v", merged.Items)
+	}
+	if merged.TaxPercent != 5 {
+		t.Fatalf("expected the tax most chunks agree on, got %v", merged.TaxPercent)
+	}
+	if len(merged.Quarantined) != 1 {
+		t.Fatalf("expected the quarantined item kept, got %+v", merged.Quarantined)
+	}
+
+	if reconcileTax([]float64{5, 18}) != 18 {
+		t.Fatal("expected a tie to go to the higher rate")
+	}
+	if _, ok := mergeChunks([]ChunkResult{{Error: "boom"}}); ok {
+		t.Fatal("expected no result when every chunk failed")
+	}
+}
+
+func TestParseChunksReusesChunksThatParsed(t *testing.T) {
+	fake := llm.NewFakeServer(nil)
+	defer fake.Close()
+
+	s := &Service{
+		llmClient:       llm.NewOpenAIClient(fake.URL, "", "fake"),
+		llmRate:         newRateLimiter(0),
+		pdfPreprocessor: NewPDFTextPreprocessor(),
+		chunking:        ChunkConfig{MaxChars: 500, Overlap: 1, Concurrency: 3},
+	}
+
+	var pages []string
+	for p := 1; p <= 4; p++ {
+		var lines []string
+		for i := 1; i <= 20; i++ {
+			lines = append(lines, fmt.Sprintf("Thali %d %d", p*100+i, 100+i))
+		}
+		pages = append(pages, strings.Join(lines, "\n"))
+	}
+	rawText := strings.Join(pages, "\n"+pageBreak+"\n")
+
+	ctx := context.Background()
+	fake.FailWith(http.StatusServiceUnavailable)
+	results, err := s.parseChunks(ctx, rawText, "eng", llm.DefaultPromptVersion, nil)
+	if err == nil || !isTransient(err) {
+		t.Fatalf("expected a transient error, got %v", err)
+	}
+	if _, ok := mergeChunks(results); ok {
+		t.Fatal("expected every chunk to fail")
+	}
+
+	// Pretend the first chunk parsed last time.
+	fake.FailWith(0)
+	first, err := s.parseChunks(ctx, rawText, "eng", llm.DefaultPromptVersion, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	previous := append([]ChunkResult{first[0]}, results[1:]...)
+
+	calls := fake.Calls()
+	again, err := s.parseChunks(ctx, rawText, "eng", llm.DefaultPromptVersion, previous)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := fake.Calls() - calls; got != len(results)-1 {
+		t.Fatalf("expected %d chunks sent again, got %d", len(results)-1, got)
+	}
+
+	merged, _ := mergeChunks(again)
+	if len(merged.Items) != 80 {
+		t.Fatalf("expected every item across pages, got %d", len(merged.Items))
+	}
+}
+
+func TestParseChunkSplitsWhenReplyIsCutOff(t *testing.T) {
+	fake := llm.NewFakeServer(nil)
+	defer fake.Close()
+	fake.LimitOutput(400)
+
+	s := &Service{
+		llmClient: llm.NewOpenAIClient(fake.URL, "", "fake"),
+		llmRate:   newRateLimiter(0),
+	}
+
+	var lines []string
+	for i := 1; i <= 60; i++ {
+		lines = append(lines, fmt.Sprintf("Thali %d %d", i, 100+i))
+	}
+	parsed, err := s.parseChunk(context.Background(), strings.Join(lines, "\n"), llm.DefaultPromptVersion, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(parsed.Items) != 60 {
+		t.Fatalf("expected every item from the halves, got %d", len(parsed.Items))
+	}
+	if fake.Calls() < 3 {
+		t.Fatalf("expected the chunk sent again in halves, got %d calls", fake.Calls())
+	}
+	if parsed.Provenance == nil || parsed.Provenance.Calls != fake.Calls() {
+		t.Fatalf("expected provenance to count all %d calls, got %+v", fake.Calls(), parsed.Provenance)
+	}
+
+	if _, _, ok := halveChunk("Thali 1 101"); ok {
+		t.Fatal("expected a short chunk not to be split")
+	}
+
+	if _, err := s.parseChunk(context.Background(), "Thali 1 101", llm.DefaultPromptVersion, nil); err != nil {
+		t.Fatalf("unexpected error for a short chunk: %v", err)
+	}
+}
@@ -0,0 +1,108 @@
+package ocr
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"bhojanalya/internal/llm"
+	"bhojanalya/internal/menu"
+)
+
+// A cross-check is flagged when the rule-based parser found at least
+// minRuleItems and either side has more than these fractions of its items
+// unmatched, when a price differs, or when the tax differs. The rules
+// miss far more than the LLM does, so items only the LLM found count for
+// less than items it missed.
+const (
+	minRuleItems       = 3
+	maxMissedByLLM     = 0.25
+	maxNotFoundByRules = 0.5
+)
+
+// parenthetical drops "(Half)" and the like, which prompt v2 appends to
+// names and the rules do not.
+var parenthetical = regexp.MustCompile(`\s*\([^)]*\)`)
+
+func crossCheckKey(name string) string {
+	name = parenthetical.ReplaceAllString(strings.ToLower(name), "")
+	return strings.Join(strings.Fields(name), " ")
+}
+
+// crossCheck compares the LLM's parse with the rules' parse of the same
+// text.
+func crossCheck(parsed, rules *llm.ParsedOCRResult) *menu.CrossCheck {
+	check := &menu.CrossCheck{
+		LLMItems:       len(parsed.Items),
+		RuleItems:      len(rules.Items),
+		MissedByLLM:    []string{},
+		NotFoundByRule: []string{},
+		PriceMismatch:  []string{},
+		LLMTax:         parsed.TaxPercent,
+		RuleTax:        rules.TaxPercent,
+	}
+
+	llmPrices, rulePrices := itemPrices(parsed.Items), itemPrices(rules.Items)
+	for _, name := range orderedNames(rules.Items) {
+		prices, ok := llmPrices[name]
+		if !ok {
+			check.MissedByLLM = append(check.MissedByLLM, name)
+			continue
+		}
+		check.Matched++
+		if !slices.ContainsFunc(prices, func(p float64) bool { return slices.Contains(rulePrices[name], p) }) {
+			check.PriceMismatch = append(check.PriceMismatch,
+				fmt.Sprintf("%s: llm %v, rules %v", name, prices, rulePrices[name]))
+		}
+	}
+	for _, name := range orderedNames(parsed.Items) {
+		if _, ok := rulePrices[name]; !ok {
+			check.NotFoundByRule = append(check.NotFoundByRule, name)
+		}
+	}
+
+	flag := func(format string, args ...any) {
+		check.Flagged = true
+		check.Reasons = append(check.Reasons, fmt.Sprintf(format, args...))
+	}
+
+	ruleNames, llmNames := len(rulePrices), len(llmPrices)
+	if check.RuleItems >= minRuleItems {
+		if n := len(check.MissedByLLM); float64(n) > maxMissedByLLM*float64(ruleNames) {
+			flag("llm missed %d of %d items the rules found", n, ruleNames)
+		}
+		if n := len(check.NotFoundByRule); float64(n) > maxNotFoundByRules*float64(llmNames) {
+			flag("rules did not find %d of %d llm items", n, llmNames)
+		}
+	}
+	if n := len(check.PriceMismatch); n > 0 {
+		flag("prices differ for %d items", n)
+	}
+	if rules.TaxPercent > 0 && parsed.TaxPercent != rules.TaxPercent {
+		flag("tax differs: llm %v%%, rules %v%%", parsed.TaxPercent, rules.TaxPercent)
+	}
+
+	return check
+}
+
+func itemPrices(items []llm.ParsedItem) map[string][]float64 {
+	prices := map[string][]float64{}
+	for _, it := range items {
+		k := crossCheckKey(it.Name)
+		prices[k] = append(prices[k], it.Price)
+	}
+	return prices
+}
+
+func orderedNames(items []llm.ParsedItem) []string {
+	seen := map[string]bool{}
+	var names []string
+	for _, it := range items {
+		if k := crossCheckKey(it.Name); !seen[k] {
+			seen[k] = true
+			names = append(names, k)
+		}
+	}
+	return names
+}
@@ -0,0 +1,45 @@
+package ocr
+
+import (
+	"testing"
+
+	"bhojanalya/internal/llm"
+)
+
+func TestCrossCheckAgreement(t *testing.T) {
+	text := "Paneer Tikka 220\nDal Makhani 180 / 280\nMasala Chai 40\nGulab Jamun 90\nGST 5%"
+	parsed := &llm.ParsedOCRResult{
+		Items: []llm.ParsedItem{
+			{Name: "Paneer Tikka", Category: "starter", Price: 220},
+			{Name: "Dal Makhani (Half)", Category: "main_course", Price: 180},
+			{Name: "Dal Makhani (Full)", Category: "main_course", Price: 280},
+			{Name: "Masala Chai", Category: "drink", Price: 40},
+			{Name: "Gulab Jamun", Category: "dessert", Price: 90},
+		},
+		TaxPercent: 5,
+	}
+
+	check := crossCheck(parsed, llm.ParseRules(text))
+	if check.Flagged || check.Matched != 4 {
+		t.Fatalf("expected agreement, got %+v", check)
+	}
+}
+
+func TestCrossCheckFlagsDisagreement(t *testing.T) {
+	text := "Paneer Tikka 220\nHara Bhara Kebab 180\nDal Makhani 280\nJeera Rice 150\nMasala Chai 40\nGST 18%"
+	parsed := &llm.ParsedOCRResult{
+		Items: []llm.ParsedItem{
+			{Name: "Paneer Tikka", Category: "starter", Price: 220},
+			{Name: "Dal Makhani", Category: "main_course", Price: 260},
+		},
+		TaxPercent: 5,
+	}
+
+	check := crossCheck(parsed, llm.ParseRules(text))
+	if !check.Flagged || len(check.Reasons) != 3 {
+		t.Fatalf("expected missed items, a price and the tax flagged, got %+v", check)
+	}
+	if len(check.MissedByLLM) != 3 || len(check.PriceMismatch) != 1 {
+		t.Fatalf("unexpected comparison: %+v", check)
+	}
+}
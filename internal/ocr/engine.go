@@ -0,0 +1,157 @@
+package ocr
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"bhojanalya/internal/menu"
+)
+
+// OCREngine turns one image into text with layout and confidence.
+// languages is a Tesseract list such as "hin+eng"; empty means the
+// engine's default.
+type OCREngine interface {
+	Recognize(ctx context.Context, imagePath string, languages string) (*Document, error)
+}
+
+// ScriptDetector is implemented by engines that can tell which writing
+// system an image is in, so uploads without a language hint still get
+// the right model.
+type ScriptDetector interface {
+	DetectScript(ctx context.Context, imagePath string) (string, error)
+}
+
+type BoundingBox struct {
+	Left   int `json:"left"`
+	Top    int `json:"top"`
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+// union returns the smallest box containing b and o.
+func (b BoundingBox) union(o BoundingBox) BoundingBox {
+	if b.Width == 0 && b.Height == 0 {
+		return o
+	}
+	left, top := min(b.Left, o.Left), min(b.Top, o.Top)
+	right := max(b.Left+b.Width, o.Left+o.Width)
+	bottom := max(b.Top+b.Height, o.Top+o.Height)
+	return BoundingBox{Left: left, Top: top, Width: right - left, Height: bottom - top}
+}
+
+type Word struct {
+	Text       string      `json:"text"`
+	Box        BoundingBox `json:"box"`
+	Confidence float64     `json:"confidence"`
+}
+
+type Line struct {
+	Text       string      `json:"text"`
+	Box        BoundingBox `json:"box"`
+	Confidence float64     `json:"confidence"`
+	Words      []Word      `json:"words"`
+}
+
+type Page struct {
+	Number int    `json:"number"`
+	Method string `json:"method,omitempty"` // PageMethodText, PageMethodOCR or PageMethodFailed
+	Lines  []Line `json:"lines"`
+	Error  string `json:"error,omitempty"` // why a failed page could not be read
+}
+
+// Document is the structured OCR result stored in menu_uploads.ocr_layout.
+// Confidences are 0–100 as reported by Tesseract.
+type Document struct {
+	Pages []Page `json:"pages"`
+}
+
+func (p Page) Text() string {
+	lines := make([]string, len(p.Lines))
+	for i, l := range p.Lines {
+		lines[i] = l.Text
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (d *Document) Text() string {
+	pages := make([]string, len(d.Pages))
+	for i, p := range d.Pages {
+		pages[i] = p.Text()
+	}
+	return strings.Join(pages, "\n\n")
+}
+
+// MeanConfidence is the average word confidence, or 0 for an empty document.
+func (d *Document) MeanConfidence() float64 {
+	var sum float64
+	var n int
+	for _, p := range d.Pages {
+		for _, l := range p.Lines {
+			for _, w := range l.Words {
+				sum += w.Confidence
+				n++
+			}
+		}
+	}
+	if n == 0 {
+		return 0
+	}
+	return sum / float64(n)
+}
+
+// LowConfidenceRegions lists lines whose confidence is below threshold so
+// a reviewer can compare them with the scan.
+func (d *Document) LowConfidenceRegions(threshold float64) []menu.OCRRegion {
+	regions := []menu.OCRRegion{}
+	for _, p := range d.Pages {
+		for _, l := range p.Lines {
+			if l.Confidence >= threshold {
+				continue
+			}
+			regions = append(regions, menu.OCRRegion{
+				Page:       p.Number,
+				Text:       l.Text,
+				Confidence: l.Confidence,
+				Left:       l.Box.Left,
+				Top:        l.Box.Top,
+				Width:      l.Box.Width,
+				Height:     l.Box.Height,
+			})
+		}
+	}
+	return regions
+}
+
+// PageMethods lists how each page's text was obtained.
+func (d *Document) PageMethods() []PageMethod {
+	methods := make([]PageMethod, len(d.Pages))
+	for i, p := range d.Pages {
+		methods[i] = PageMethod{Page: p.Number, Method: p.Method, Error: p.Error}
+	}
+	return methods
+}
+
+// setMethod records method on every page.
+func (d *Document) setMethod(method string) {
+	for i := range d.Pages {
+		d.Pages[i].Method = method
+	}
+}
+
+// addFailedPage records a page that could not be read and returns err
+// with the page number, keeping it for isTransient.
+func (d *Document) addFailedPage(number int, err error) error {
+	d.Pages = append(d.Pages, Page{Number: number, Method: PageMethodFailed, Lines: []Line{}, Error: err.Error()})
+	return fmt.Errorf("page %d: %w", number, err)
+}
+
+// ocrPage collects what the engine recognised on one page image into page
+// number of the PDF.
+func ocrPage(number int, recognised *Document) Page {
+	page := Page{Number: number, Method: PageMethodOCR}
+	for _, p := range recognised.Pages {
+		page.Lines = append(page.Lines, p.Lines...)
+	}
+	return page
+}
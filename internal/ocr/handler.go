@@ -0,0 +1,118 @@
+package ocr
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"bhojanalya/internal/llm"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	service *Service
+}
+
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// GET /admin/menus/pipeline
+func (h *Handler) Stats(c *gin.Context) {
+	stats, err := h.service.Stats()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
+// GET /admin/menus/dead-letter?limit=100
+func (h *Handler) DeadLetters(c *gin.Context) {
+	limit := 100
+	if v, err := strconv.Atoi(c.Query("limit")); err == nil && v > 0 && v <= 500 {
+		limit = v
+	}
+
+	letters, err := h.service.DeadLetters(limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"uploads": letters})
+}
+
+type RequeueRequest struct {
+	IDs []int `json:"ids"`
+}
+
+// POST /admin/menus/dead-letter/requeue
+func (h *Handler) Requeue(c *gin.Context) {
+	var req RequeueRequest
+	if err := c.ShouldBindJSON(&req); err != nil || len(req.IDs) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ids is required"})
+		return
+	}
+
+	requeued, err := h.service.Requeue(req.IDs)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"requeued": requeued})
+}
+
+// GET /admin/menus/dead-letter/:id/errors
+func (h *Handler) JobErrors(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid menu id"})
+		return
+	}
+
+	history, err := h.service.JobErrors(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"errors": history})
+}
+
+// maxReparseIDs caps one reparse request; every upload costs LLM calls
+// and the request waits for them.
+const maxReparseIDs = 10
+
+type ReparseRequest struct {
+	IDs           []int  `json:"ids"`
+	PromptVersion string `json:"prompt_version"`
+}
+
+// POST /admin/menus/reparse
+func (h *Handler) Reparse(c *gin.Context) {
+	var req ReparseRequest
+	if err := c.ShouldBindJSON(&req); err != nil || len(req.IDs) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ids is required"})
+		return
+	}
+	if len(req.IDs) > maxReparseIDs {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("at most %d ids per request", maxReparseIDs)})
+		return
+	}
+	if _, err := llm.LookupPrompt(req.PromptVersion); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	results, err := h.service.Reparse(c.Request.Context(), req.IDs, req.PromptVersion)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"results": results})
+}
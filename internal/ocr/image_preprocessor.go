@@ -0,0 +1,566 @@
+package ocr
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/jpeg"
+	"image/png"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Preprocessing steps, in the order they run.
+const (
+	StepOrient    = "orient"
+	StepGrayscale = "grayscale"
+	StepUpscale   = "upscale"
+	StepThreshold = "threshold"
+	StepDeskew    = "deskew"
+	StepCrop      = "crop"
+)
+
+var allSteps = []string{StepOrient, StepGrayscale, StepUpscale, StepThreshold, StepDeskew, StepCrop}
+
+// ImagePreprocessor cleans up phone photos of menus (rotation, glare, dim
+// light, skew, background around the page) before they reach Tesseract.
+// Every step after grayscale works on a grayscale copy, so grayscale is
+// implied when any of them is on.
+type ImagePreprocessor struct {
+	Steps map[string]bool
+
+	// Images narrower than MinWidth are upscaled, by at most MaxUpscale.
+	MinWidth   int
+	MaxUpscale float64
+
+	// Thresholding compares each pixel with the mean of a window
+	// ThresholdWindow × image width wide; it is ink when it is at least
+	// ThresholdBias (0–1) darker than that mean.
+	ThresholdWindow float64
+	ThresholdBias   float64
+
+	// MaxSkew is the largest rotation deskew looks for, in degrees.
+	MaxSkew float64
+
+	// SaveProcessed uploads the processed image next to the original so
+	// reviewers can compare them.
+	SaveProcessed bool
+}
+
+func NewImagePreprocessor() *ImagePreprocessor {
+	steps := map[string]bool{}
+	for _, s := range allSteps {
+		steps[s] = true
+	}
+	return &ImagePreprocessor{
+		Steps:           steps,
+		MinWidth:        1600,
+		MaxUpscale:      3,
+		ThresholdWindow: 1.0 / 16,
+		ThresholdBias:   0.15,
+		MaxSkew:         10,
+	}
+}
+
+// NewImagePreprocessorFromEnv reads OCR_PREPROCESS_STEPS (comma-separated
+// steps, or "none"), OCR_PREPROCESS_MIN_WIDTH and OCR_PREPROCESS_SAVE.
+func NewImagePreprocessorFromEnv() (*ImagePreprocessor, error) {
+	p := NewImagePreprocessor()
+
+	if v := strings.TrimSpace(os.Getenv("OCR_PREPROCESS_STEPS")); v != "" {
+		p.Steps = map[string]bool{}
+		if v != "none" {
+			for _, s := range strings.Split(v, ",") {
+				s = strings.TrimSpace(s)
+				if !isStep(s) {
+					return nil, fmt.Errorf("OCR_PREPROCESS_STEPS: unknown step %q (valid: %s)", s, strings.Join(allSteps, ","))
+				}
+				p.Steps[s] = true
+			}
+		}
+	}
+
+	if v := os.Getenv("OCR_PREPROCESS_MIN_WIDTH"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("OCR_PREPROCESS_MIN_WIDTH must be a non-negative integer")
+		}
+		p.MinWidth = n
+	}
+
+	p.SaveProcessed = os.Getenv("OCR_PREPROCESS_SAVE") == "true"
+	return p, nil
+}
+
+func isStep(s string) bool {
+	for _, step := range allSteps {
+		if s == step {
+			return true
+		}
+	}
+	return false
+}
+
+// Enabled reports whether any step is switched on.
+func (p *ImagePreprocessor) Enabled() bool {
+	for _, on := range p.Steps {
+		if on {
+			return true
+		}
+	}
+	return false
+}
+
+// Process runs the enabled steps on the image at srcPath and writes the
+// result to dstPath as PNG. It returns the steps that changed the image.
+func (p *ImagePreprocessor) Process(srcPath, dstPath string) ([]string, error) {
+	data, err := os.ReadFile(srcPath)
+	if err != nil {
+		return nil, err
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("decode image: %w", err)
+	}
+
+	applied, out := p.run(img, jpegOrientation(data))
+	if len(applied) == 0 {
+		return nil, nil
+	}
+
+	f, err := os.Create(dstPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if err := png.Encode(f, out); err != nil {
+		return nil, err
+	}
+	return applied, nil
+}
+
+func (p *ImagePreprocessor) run(img image.Image, orientation int) ([]string, image.Image) {
+	var applied []string
+
+	needsGray := p.Steps[StepGrayscale] || p.Steps[StepUpscale] ||
+		p.Steps[StepThreshold] || p.Steps[StepDeskew] || p.Steps[StepCrop]
+	if needsGray {
+		// Converting first makes the remaining steps much cheaper.
+		img = toGray(img)
+		applied = append(applied, StepGrayscale)
+	}
+
+	if p.Steps[StepOrient] && orientation > 1 {
+		img = orient(img, orientation)
+		applied = append(applied, StepOrient)
+	}
+
+	if !needsGray {
+		return applied, img
+	}
+	g := img.(*image.Gray)
+
+	if p.Steps[StepUpscale] && p.MinWidth > 0 && g.Rect.Dx() < p.MinWidth {
+		factor := min(float64(p.MinWidth)/float64(g.Rect.Dx()), p.MaxUpscale)
+		if factor > 1 {
+			g = upscale(g, factor)
+			applied = append(applied, StepUpscale)
+		}
+	}
+
+	if p.Steps[StepThreshold] {
+		g = adaptiveThreshold(g, p.ThresholdWindow, p.ThresholdBias)
+		applied = append(applied, StepThreshold)
+	}
+
+	if p.Steps[StepDeskew] {
+		if angle := detectSkew(g, p.MaxSkew); math.Abs(angle) >= 0.2 {
+			g = rotate(g, angle)
+			applied = append(applied, StepDeskew)
+		}
+	}
+
+	if p.Steps[StepCrop] {
+		if r := contentBounds(g); r != g.Rect {
+			g = crop(g, r)
+			applied = append(applied, StepCrop)
+		}
+	}
+
+	return applied, g
+}
+
+// --------------------------------------------------
+// EXIF orientation
+// --------------------------------------------------
+
+// jpegOrientation returns the EXIF orientation (1–8) of a JPEG, or 0 when
+// the data is not a JPEG or has none.
+func jpegOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 0
+	}
+
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xFF {
+			return 0
+		}
+		marker := data[i+1]
+		if marker == 0xDA || marker == 0xD9 { // image data starts, no EXIF
+			return 0
+		}
+		size := int(binary.BigEndian.Uint16(data[i+2:]))
+		if size < 2 || i+2+size > len(data) {
+			return 0
+		}
+		if seg := data[i+4 : i+2+size]; marker == 0xE1 && bytes.HasPrefix(seg, []byte("Exif\x00\x00")) {
+			return exifOrientation(seg[6:])
+		}
+		i += 2 + size
+	}
+	return 0
+}
+
+// exifOrientation reads tag 0x0112 from IFD0 of a TIFF-structured block.
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 0
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0
+	}
+
+	ifd := int64(order.Uint32(tiff[4:]))
+	if ifd+2 > int64(len(tiff)) {
+		return 0
+	}
+	entries := int64(order.Uint16(tiff[ifd:]))
+	for k := int64(0); k < entries; k++ {
+		e := ifd + 2 + k*12
+		if e+12 > int64(len(tiff)) {
+			return 0
+		}
+		if order.Uint16(tiff[e:]) == 0x0112 {
+			if o := int(order.Uint16(tiff[e+8:])); o >= 1 && o <= 8 {
+				return o
+			}
+			return 0
+		}
+	}
+	return 0
+}
+
+// orient undoes an EXIF orientation so the image is upright.
+func orient(src image.Image, orientation int) image.Image {
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+
+	var dst draw.Image
+	if _, ok := src.(*image.Gray); ok {
+		dst = image.NewGray(image.Rect(0, 0, dw, dh))
+	} else {
+		dst = image.NewRGBA(image.Rect(0, 0, dw, dh))
+	}
+
+	for y := 0; y < dh; y++ {
+		for x := 0; x < dw; x++ {
+			var sx, sy int
+			switch orientation {
+			case 2: // flip horizontally
+				sx, sy = w-1-x, y
+			case 3: // turn 180°
+				sx, sy = w-1-x, h-1-y
+			case 4: // flip vertically
+				sx, sy = x, h-1-y
+			case 5: // transpose
+				sx, sy = y, x
+			case 6: // turn 90° clockwise
+				sx, sy = y, h-1-x
+			case 7: // transverse
+				sx, sy = w-1-y, h-1-x
+			case 8: // turn 90° counter-clockwise
+				sx, sy = w-1-y, x
+			default:
+				sx, sy = x, y
+			}
+			dst.Set(x, y, src.At(b.Min.X+sx, b.Min.Y+sy))
+		}
+	}
+	return dst
+}
+
+// --------------------------------------------------
+// Grayscale and scaling
+// --------------------------------------------------
+
+// toGray converts img to an 8-bit grayscale image with its origin at 0,0.
+func toGray(img image.Image) *image.Gray {
+	b := img.Bounds()
+	g := image.NewGray(image.Rect(0, 0, b.Dx(), b.Dy()))
+
+	// JPEGs decode to YCbCr, whose Y plane already is the luminance.
+	if yc, ok := img.(*image.YCbCr); ok {
+		for y := 0; y < b.Dy(); y++ {
+			off := yc.YOffset(b.Min.X, b.Min.Y+y)
+			copy(g.Pix[y*g.Stride:y*g.Stride+b.Dx()], yc.Y[off:off+b.Dx()])
+		}
+		return g
+	}
+
+	for y := 0; y < b.Dy(); y++ {
+		for x := 0; x < b.Dx(); x++ {
+			g.Pix[y*g.Stride+x] = color.GrayModel.Convert(img.At(b.Min.X+x, b.Min.Y+y)).(color.Gray).Y
+		}
+	}
+	return g
+}
+
+// upscale resizes g by factor with bilinear interpolation.
+func upscale(g *image.Gray, factor float64) *image.Gray {
+	sw, sh := g.Rect.Dx(), g.Rect.Dy()
+	dw, dh := int(float64(sw)*factor), int(float64(sh)*factor)
+	out := image.NewGray(image.Rect(0, 0, dw, dh))
+
+	for y := 0; y < dh; y++ {
+		fy := math.Max((float64(y)+0.5)/factor-0.5, 0)
+		y0 := min(int(fy), sh-1)
+		y1 := min(y0+1, sh-1)
+		wy := fy - float64(y0)
+
+		for x := 0; x < dw; x++ {
+			fx := math.Max((float64(x)+0.5)/factor-0.5, 0)
+			x0 := min(int(fx), sw-1)
+			x1 := min(x0+1, sw-1)
+			wx := fx - float64(x0)
+
+			top := float64(g.Pix[y0*g.Stride+x0])*(1-wx) + float64(g.Pix[y0*g.Stride+x1])*wx
+			bottom := float64(g.Pix[y1*g.Stride+x0])*(1-wx) + float64(g.Pix[y1*g.Stride+x1])*wx
+			out.Pix[y*out.Stride+x] = uint8(top*(1-wy) + bottom*wy + 0.5)
+		}
+	}
+	return out
+}
+
+// --------------------------------------------------
+// Adaptive thresholding
+// --------------------------------------------------
+
+// adaptiveThreshold binarises g against the local mean (Bradley–Roth), so
+// shadows and glare don't swallow text the way a global cut-off would.
+func adaptiveThreshold(g *image.Gray, window, bias float64) *image.Gray {
+	w, h := g.Rect.Dx(), g.Rect.Dy()
+	half := max(int(float64(w)*window), 8) / 2
+
+	// integral[(y+1)*(w+1)+(x+1)] is the sum of pixels above and left of (x,y).
+	integral := make([]int64, (w+1)*(h+1))
+	for y := 0; y < h; y++ {
+		var row int64
+		for x := 0; x < w; x++ {
+			row += int64(g.Pix[y*g.Stride+x])
+			integral[(y+1)*(w+1)+x+1] = integral[y*(w+1)+x+1] + row
+		}
+	}
+
+	out := image.NewGray(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		y0, y1 := max(y-half, 0), min(y+half, h-1)
+		for x := 0; x < w; x++ {
+			x0, x1 := max(x-half, 0), min(x+half, w-1)
+			count := int64((x1 - x0 + 1) * (y1 - y0 + 1))
+			sum := integral[(y1+1)*(w+1)+x1+1] - integral[y0*(w+1)+x1+1] -
+				integral[(y1+1)*(w+1)+x0] + integral[y0*(w+1)+x0]
+
+			v := 255
+			if float64(int64(g.Pix[y*g.Stride+x])*count) < float64(sum)*(1-bias) {
+				v = 0
+			}
+			out.Pix[y*out.Stride+x] = uint8(v)
+		}
+	}
+	return out
+}
+
+// --------------------------------------------------
+// Deskew
+// --------------------------------------------------
+
+const inkLevel = 128
+
+// maxSkewSamples bounds the ink pixels scored per angle.
+const maxSkewSamples = 20000
+
+// detectSkew finds the angle (degrees, clockwise text slope positive) at
+// which projecting the ink onto the vertical axis gives the sharpest
+// profile, i.e. the angle the text lines run at.
+func detectSkew(g *image.Gray, maxAngle float64) float64 {
+	w, h := g.Rect.Dx(), g.Rect.Dy()
+
+	var ink int
+	for _, v := range g.Pix {
+		if v < inkLevel {
+			ink++
+		}
+	}
+	if ink == 0 || maxAngle <= 0 {
+		return 0
+	}
+	maxAngle = min(maxAngle, 45)
+
+	step := max(ink/maxSkewSamples, 1)
+	points := make([][2]float64, 0, ink/step+1)
+	n := 0
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if g.Pix[y*g.Stride+x] < inkLevel {
+				if n%step == 0 {
+					points = append(points, [2]float64{float64(x), float64(y)})
+				}
+				n++
+			}
+		}
+	}
+
+	bins := make([]int, 2*(w+h))
+	score := func(deg float64) float64 {
+		clear(bins)
+		sin, cos := math.Sincos(deg * math.Pi / 180)
+		for _, p := range points {
+			// Offset by w keeps the projection non-negative within ±45°.
+			bins[int(p[1]*cos-p[0]*sin)+w]++
+		}
+		var s float64
+		for _, c := range bins {
+			s += float64(c) * float64(c)
+		}
+		return s
+	}
+
+	search := func(from, to, by float64) float64 {
+		best, bestScore := 0.0, score(0)
+		for a := from; a <= to+1e-9; a += by {
+			if s := score(a); s > bestScore {
+				best, bestScore = a, s
+			}
+		}
+		return best
+	}
+
+	coarse := search(-maxAngle, maxAngle, 0.5)
+	return search(coarse-0.5, coarse+0.5, 0.1)
+}
+
+// rotate turns g by -deg around its centre so lines sloping at deg become
+// horizontal. Uncovered corners are filled white.
+func rotate(g *image.Gray, deg float64) *image.Gray {
+	w, h := g.Rect.Dx(), g.Rect.Dy()
+	out := image.NewGray(image.Rect(0, 0, w, h))
+
+	sin, cos := math.Sincos(deg * math.Pi / 180)
+	cx, cy := float64(w)/2, float64(h)/2
+
+	for y := 0; y < h; y++ {
+		dy := float64(y) - cy
+		for x := 0; x < w; x++ {
+			dx := float64(x) - cx
+			sx := int(math.Round(cx + dx*cos - dy*sin))
+			sy := int(math.Round(cy + dx*sin + dy*cos))
+
+			v := uint8(255)
+			if sx >= 0 && sx < w && sy >= 0 && sy < h {
+				v = g.Pix[sy*g.Stride+sx]
+			}
+			out.Pix[y*out.Stride+x] = v
+		}
+	}
+	return out
+}
+
+// --------------------------------------------------
+// Border cropping
+// --------------------------------------------------
+
+// contentBounds drops dark borders (table or background around the page)
+// and blank margins, keeping a small margin around the ink.
+func contentBounds(g *image.Gray) image.Rectangle {
+	r := g.Rect
+
+	rowInk := func(y, x0, x1 int) float64 {
+		var n int
+		for x := x0; x < x1; x++ {
+			if g.Pix[y*g.Stride+x] < inkLevel {
+				n++
+			}
+		}
+		return float64(n) / float64(max(x1-x0, 1))
+	}
+	colInk := func(x, y0, y1 int) float64 {
+		var n int
+		for y := y0; y < y1; y++ {
+			if g.Pix[y*g.Stride+x] < inkLevel {
+				n++
+			}
+		}
+		return float64(n) / float64(max(y1-y0, 1))
+	}
+
+	// Mostly-dark edge rows and columns are background, not menu.
+	const border = 0.5
+	for r.Dy() > 0 && rowInk(r.Min.Y, r.Min.X, r.Max.X) > border {
+		r.Min.Y++
+	}
+	for r.Dy() > 0 && rowInk(r.Max.Y-1, r.Min.X, r.Max.X) > border {
+		r.Max.Y--
+	}
+	for r.Dx() > 0 && colInk(r.Min.X, r.Min.Y, r.Max.Y) > border {
+		r.Min.X++
+	}
+	for r.Dx() > 0 && colInk(r.Max.X-1, r.Min.Y, r.Max.Y) > border {
+		r.Max.X--
+	}
+	if r.Empty() {
+		return g.Rect
+	}
+
+	ink := image.Rectangle{}
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			if g.Pix[y*g.Stride+x] < inkLevel {
+				ink = ink.Union(image.Rect(x, y, x+1, y+1))
+			}
+		}
+	}
+	if ink.Empty() {
+		return g.Rect
+	}
+
+	margin := max(min(g.Rect.Dx(), g.Rect.Dy())/50, 10)
+	return ink.Inset(-margin).Intersect(r)
+}
+
+// crop copies r out of g into a new image with its origin at 0,0.
+func crop(g *image.Gray, r image.Rectangle) *image.Gray {
+	out := image.NewGray(image.Rect(0, 0, r.Dx(), r.Dy()))
+	for y := 0; y < r.Dy(); y++ {
+		src := (r.Min.Y+y)*g.Stride + r.Min.X
+		copy(out.Pix[y*out.Stride:y*out.Stride+r.Dx()], g.Pix[src:src+r.Dx()])
+	}
+	return out
+}
@@ -0,0 +1,122 @@
+package ocr
+
+import (
+	"image"
+	"math"
+	"testing"
+)
+
+func TestJPEGOrientation(t *testing.T) {
+	exif := []byte("Exif\x00\x00" +
+		"MM\x00\x2a\x00\x00\x00\x08" + // big-endian TIFF, IFD0 at 8
+		"\x00\x01" + // one entry
+		"\x01\x12\x00\x03\x00\x00\x00\x01\x00\x06\x00\x00" + // orientation = 6
+		"\x00\x00\x00\x00")
+	data := []byte{0xFF, 0xD8, 0xFF, 0xE1, 0x00, byte(len(exif) + 2)}
+	data = append(data, exif...)
+	data = append(data, 0xFF, 0xDA, 0x00, 0x02)
+
+	if o := jpegOrientation(data); o != 6 {
+		t.Fatalf("expected orientation 6, got %d", o)
+	}
+	if o := jpegOrientation([]byte{0x89, 'P', 'N', 'G'}); o != 0 {
+		t.Fatalf("expected no orientation for PNG, got %d", o)
+	}
+}
+
+func TestOrientRotatesClockwise(t *testing.T) {
+	// 2 wide, 3 tall:
+	//   1 2
+	//   3 4
+	//   5 6
+	src := image.NewGray(image.Rect(0, 0, 2, 3))
+	copy(src.Pix, []uint8{1, 2, 3, 4, 5, 6})
+
+	out := orient(src, 6).(*image.Gray)
+	if out.Rect.Dx() != 3 || out.Rect.Dy() != 2 {
+		t.Fatalf("unexpected size %v", out.Rect)
+	}
+	want := []uint8{5, 3, 1, 6, 4, 2}
+	for i, v := range want {
+		if out.Pix[i] != v {
+			t.Fatalf("unexpected pixels %v, want %v", out.Pix, want)
+		}
+	}
+}
+
+func TestAdaptiveThresholdHandlesUnevenLight(t *testing.T) {
+	// Background fades from dim (60) to bright (230); text strokes are
+	// 40 darker than the paper around them.
+	g := image.NewGray(image.Rect(0, 0, 200, 100))
+	for y := 0; y < 100; y++ {
+		for x := 0; x < 200; x++ {
+			v := 60 + x*170/200
+			if x%20 < 3 && y > 30 && y < 70 {
+				v -= 40
+			}
+			g.Pix[y*g.Stride+x] = uint8(v)
+		}
+	}
+
+	out := adaptiveThreshold(g, 1.0/8, 0.15)
+	for _, x := range []int{1, 101, 181} {
+		if out.Pix[50*out.Stride+x] != 0 {
+			t.Errorf("stroke at x=%d not kept as ink", x)
+		}
+	}
+	for _, x := range []int{10, 110, 190} {
+		if out.Pix[50*out.Stride+x] != 255 {
+			t.Errorf("paper at x=%d not white", x)
+		}
+	}
+}
+
+func TestDetectSkewAndRotate(t *testing.T) {
+	const angle = 3.0
+	g := image.NewGray(image.Rect(0, 0, 400, 300))
+	for i := range g.Pix {
+		g.Pix[i] = 255
+	}
+	slope := math.Tan(angle * math.Pi / 180)
+	for line := 60; line < 260; line += 30 {
+		for x := 40; x < 360; x++ {
+			y := line + int(float64(x)*slope)
+			g.Pix[y*g.Stride+x] = 0
+			g.Pix[(y+1)*g.Stride+x] = 0
+		}
+	}
+
+	got := detectSkew(g, 10)
+	if math.Abs(got-angle) > 0.3 {
+		t.Fatalf("expected skew near %v, got %v", angle, got)
+	}
+
+	if after := detectSkew(rotate(g, got), 10); math.Abs(after) > 0.3 {
+		t.Fatalf("expected no skew after rotation, got %v", after)
+	}
+}
+
+func TestContentBoundsDropsDarkBorder(t *testing.T) {
+	g := image.NewGray(image.Rect(0, 0, 300, 300))
+	for y := 0; y < 300; y++ {
+		for x := 0; x < 300; x++ {
+			v := uint8(255)
+			if x < 20 || x >= 280 || y < 20 || y >= 280 { // table around the page
+				v = 0
+			}
+			if x >= 100 && x < 200 && y >= 120 && y < 140 { // text
+				v = 0
+			}
+			g.Pix[y*g.Stride+x] = v
+		}
+	}
+
+	r := contentBounds(g)
+	want := image.Rect(90, 110, 210, 150)
+	if r != want {
+		t.Fatalf("expected %v, got %v", want, r)
+	}
+	if c := crop(g, r); c.Rect.Dx() != 120 || c.Rect.Dy() != 40 {
+		t.Fatalf("unexpected crop size %v", c.Rect)
+	}
+}
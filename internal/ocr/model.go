@@ -1,6 +1,62 @@
 package ocr
 
+import "time"
+
 type OCRRecord struct {
 	ID      int
 	RawText string
 }
+
+// OCRJob is a claimed upload waiting for OCR.
+type OCRJob struct {
+	ID           int
+	ObjectKey    string
+	LanguageHint string // upload hint, else the restaurant's, else ""
+	Retries      int    // transient failures so far
+}
+
+// LLMJob is a claimed upload whose OCR text waits for parsing.
+type LLMJob struct {
+	ID        int
+	RawText   string
+	Languages string // what OCR ran with
+	Retries   int
+	Chunks    []ChunkResult // from the previous attempt, if any
+}
+
+// ParsedUpload is a parsed upload's OCR text and current parse.
+type ParsedUpload struct {
+	ID         int
+	RawText    string
+	Languages  string
+	ParsedData []byte // menu_uploads.parsed_data
+}
+
+// Transition is one upload moved by a bulk status change.
+type Transition struct {
+	ID        int
+	From      string
+	To        string
+	ClaimedBy string // worker whose lease expired, for reaped jobs
+}
+
+// DeadLetter is an upload that failed for good.
+type DeadLetter struct {
+	ID             int       `json:"id"`
+	RestaurantID   int       `json:"restaurant_id"`
+	RestaurantName string    `json:"restaurant_name"`
+	Status         string    `json:"status"`
+	Error          *string   `json:"error"`
+	Retries        int       `json:"retries"`
+	Attempts       int       `json:"attempts"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// JobError is one failed attempt from menu_upload_errors.
+type JobError struct {
+	Stage     string    `json:"stage"`
+	Error     string    `json:"error"`
+	Transient bool      `json:"transient"`
+	Attempt   int       `json:"attempt"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -33,9 +33,6 @@ func (p *PDFTextPreprocessor) CleanPDFText(rawText string) string {
 	// Step 4: Remove common PDF artifacts
 	text = p.removePDFArtifacts(text)
 	
-	// Step 5: Limit text length (safeguard for LLM context)
-	text = p.smartTruncate(text)
-	
 	log.Printf("PDF cleaning: Output length = %d chars (reduced by %d%%)", 
 		len(text), 100*(len(rawText)-len(text))/len(rawText))
 	
@@ -114,29 +111,6 @@ func (p *PDFTextPreprocessor) removePDFArtifacts(text string) string {
 	return text
 }
 
-func (p *PDFTextPreprocessor) smartTruncate(text string) string {
-	// Gemini context limit is ~30K tokens, roughly 20K characters for safety
-	const maxLength = 15000 // Conservative limit
-	
-	if len(text) <= maxLength {
-		return text
-	}
-	
-	log.Printf("PDF text too long (%d chars), truncating to %d chars", 
-		len(text), maxLength)
-	
-	// Try to truncate at a logical boundary (paragraph)
-	truncated := text[:maxLength]
-	
-	// Find last paragraph break
-	if idx := strings.LastIndex(truncated, "\n\n"); idx > maxLength/2 {
-		truncated = truncated[:idx]
-		log.Printf("Truncated at paragraph break (now %d chars)", len(truncated))
-	}
-	
-	return truncated
-}
-
 // IsLikelyPDFText checks if text appears to be from PDF OCR
 func (p *PDFTextPreprocessor) IsLikelyPDFText(text string) bool {
 	// Check for PDF-specific artifacts
@@ -0,0 +1,109 @@
+package ocr
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// How the text of a page was obtained.
+const (
+	PageMethodText   = "text" // the PDF's embedded text layer
+	PageMethodOCR    = "ocr"
+	PageMethodFailed = "failed" // could not be rasterised or OCRed
+)
+
+// PageMethod is one entry of menu_uploads.ocr_page_methods.
+type PageMethod struct {
+	Page   int    `json:"page"`
+	Method string `json:"method"`
+	Error  string `json:"error,omitempty"`
+}
+
+// minPDFTextCharsFromEnv reads OCR_PDF_MIN_TEXT_CHARS, default 40: the
+// letters and digits a page's text layer needs before OCR is skipped.
+func minPDFTextCharsFromEnv() int {
+	if v, err := strconv.Atoi(os.Getenv("OCR_PDF_MIN_TEXT_CHARS")); err == nil && v > 0 {
+		return v
+	}
+	return 40
+}
+
+// extractPDFText returns the text layer of every page, in page order,
+// using pdftotext -layout so columns of names and prices stay on one line.
+func extractPDFText(ctx context.Context, pdfPath string) ([]string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "pdftotext", "-layout", "-enc", "UTF-8", pdfPath, "-")
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("pdftotext failed: %w - output: %s", err, stderr.String())
+	}
+	return splitPDFPages(stdout.String()), nil
+}
+
+// splitPDFPages splits pdftotext output on the form feed it ends every
+// page with.
+func splitPDFPages(out string) []string {
+	pages := strings.Split(out, "\f")
+	if len(pages) > 1 && strings.TrimSpace(pages[len(pages)-1]) == "" {
+		pages = pages[:len(pages)-1]
+	}
+	return pages
+}
+
+// hasTextLayer reports whether a page's embedded text is worth using
+// instead of OCR: enough letters and digits, and not the "(cid:NN)"
+// placeholders pdftotext prints for fonts it cannot map to Unicode.
+func hasTextLayer(text string, minChars int) bool {
+	if strings.Count(text, "(cid:") > 3 {
+		return false
+	}
+
+	var useful, garbage int
+	for _, r := range text {
+		switch {
+		case r == unicode.ReplacementChar || unicode.Is(unicode.Co, r):
+			garbage++
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			useful++
+		}
+	}
+	return useful >= minChars && garbage*10 < useful
+}
+
+// textLayerPage turns a page of pdftotext output into a Page. The text is
+// exact, so every word gets full confidence; there are no boxes.
+func textLayerPage(number int, text string) Page {
+	page := Page{Number: number, Method: PageMethodText}
+	for _, raw := range strings.Split(text, "\n") {
+		fields := strings.Fields(raw)
+		if len(fields) == 0 {
+			continue
+		}
+
+		// -layout pads columns with runs of spaces; collapse them.
+		line := Line{Text: strings.Join(fields, " "), Confidence: 100}
+		for _, f := range fields {
+			line.Words = append(line.Words, Word{Text: f, Confidence: 100})
+		}
+		page.Lines = append(page.Lines, line)
+	}
+	return page
+}
+
+// rasterisePDFPage renders one page (1-based) to PNG for OCR.
+func rasterisePDFPage(ctx context.Context, pdfPath string, page int, prefix string) (string, error) {
+	n := strconv.Itoa(page)
+	cmd := exec.CommandContext(ctx, "pdftoppm", "-png", "-f", n, "-l", n, "-singlefile", pdfPath, prefix)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return "", fmt.Errorf("pdftoppm failed: %s", string(out))
+	}
+	return prefix + ".png", nil
+}
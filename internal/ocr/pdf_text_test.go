@@ -0,0 +1,64 @@
+package ocr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestSplitPDFPages(t *testing.T) {
+	pages := splitPDFPages("Starters\n  Paneer Tikka      280\n\fMains\n\f\f")
+	if len(pages) != 3 {
+		t.Fatalf("expected 3 pages, got %d: %q", len(pages), pages)
+	}
+	if pages[2] != "" {
+		t.Fatalf("expected empty third page, got %q", pages[2])
+	}
+}
+
+func TestHasTextLayer(t *testing.T) {
+	menu := "STARTERS\n  Paneer Tikka        280\n  Hara Bhara Kebab    240\n  Veg Manchurian      220\n"
+	if !hasTextLayer(menu, 40) {
+		t.Error("expected a digital menu page to have a text layer")
+	}
+	if hasTextLayer("  \n 3 \n", 40) {
+		t.Error("expected a scanned page with only a page number to need OCR")
+	}
+	garbled := "(cid:12)(cid:40)(cid:33)(cid:7) (cid:19)(cid:4) Paneer Tikka Paneer Tikka Paneer Tikka 280"
+	if hasTextLayer(garbled, 40) {
+		t.Error("expected unmapped font glyphs to need OCR")
+	}
+}
+
+func TestTextLayerPage(t *testing.T) {
+	page := textLayerPage(2, "STARTERS\n\n  Paneer Tikka        280\n")
+	if page.Number != 2 || page.Method != PageMethodText {
+		t.Fatalf("unexpected page %+v", page)
+	}
+	if page.Text() != "STARTERS\nPaneer Tikka 280" {
+		t.Fatalf("unexpected text %q", page.Text())
+	}
+	if page.Lines[1].Confidence != 100 || len(page.Lines[1].Words) != 3 {
+		t.Fatalf("unexpected line %+v", page.Lines[1])
+	}
+}
+
+func TestAddFailedPage(t *testing.T) {
+	doc := &Document{Pages: []Page{textLayerPage(1, "Paneer Tikka 280")}}
+	err := errors.Join(
+		doc.addFailedPage(2, errors.New("pdftoppm failed")),
+		doc.addFailedPage(3, fmt.Errorf("tesseract: %w", context.DeadlineExceeded)),
+	)
+
+	methods := doc.PageMethods()
+	if len(methods) != 3 || methods[1].Method != PageMethodFailed || methods[1].Error != "pdftoppm failed" {
+		t.Fatalf("unexpected page methods %+v", methods)
+	}
+	if err.Error() != "page 2: pdftoppm failed\npage 3: tesseract: context deadline exceeded" {
+		t.Fatalf("unexpected error %q", err)
+	}
+	if !isTransient(err) {
+		t.Fatal("expected a page that timed out to make the job retryable")
+	}
+}
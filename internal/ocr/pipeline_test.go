@@ -0,0 +1,66 @@
+package ocr
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"bhojanalya/internal/llm"
+)
+
+// stubEngine "recognises" fixed text, one line per menu line.
+type stubEngine struct {
+	text string
+}
+
+func (e stubEngine) Recognize(ctx context.Context, imagePath string, languages string) (*Document, error) {
+	page := Page{Number: 1}
+	for _, l := range strings.Split(e.text, "\n") {
+		page.Lines = append(page.Lines, Line{Text: l, Confidence: 90})
+	}
+	return &Document{Pages: []Page{page}}, nil
+}
+
+func TestPipelineOCRToCostOffline(t *testing.T) {
+	fake := llm.NewFakeServer(nil)
+	defer fake.Close()
+
+	s := &Service{
+		engine:          stubEngine{text: "Paneer Tikka 220\nDal Makhani 280\nMasala Chai 40\nGulab Jamun 90\nGST 5%"},
+		ocrSlots:        newProcessSlots(1),
+		llmClient:       llm.NewOpenAIClient(fake.URL, "", "fake"),
+		llmRate:         newRateLimiter(0),
+		pdfPreprocessor: NewPDFTextPreprocessor(),
+		chunking:        ChunkConfig{MaxChars: 6000, Overlap: 3, Concurrency: 2},
+	}
+
+	ctx := context.Background()
+	doc, err := s.recognize(ctx, "menu.png", "eng")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	results, err := s.parseChunks(ctx, doc.Text(), "eng", llm.DefaultPromptVersion, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	merged, ok := mergeChunks(results)
+	if !ok {
+		t.Fatal("expected a parsed chunk")
+	}
+	parsed, cost, err := buildMenu(merged)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(parsed.Items) != 4 || parsed.Items[0].SearchName != "paneer tikka" {
+		t.Fatalf("unexpected items: %+v", parsed.Items)
+	}
+	// One starter, one main, two drinks (only one on the menu), one dessert.
+	if cost.Calculation.Subtotal != 630 || cost.Calculation.Total != 661.5 {
+		t.Fatalf("unexpected cost: %+v", cost.Calculation)
+	}
+	if cost.Availability["drink"] {
+		t.Fatal("expected drinks unavailable with only one on the menu")
+	}
+}
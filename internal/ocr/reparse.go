@@ -0,0 +1,165 @@
+package ocr
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"bhojanalya/internal/llm"
+	"bhojanalya/internal/menu"
+)
+
+// ReparseResult compares a fresh parse of an upload, under another prompt
+// version, with the parse it has now.
+type ReparseResult struct {
+	MenuID        int    `json:"menu_id"`
+	PromptVersion string `json:"prompt_version"`
+
+	// Nil for menus parsed before provenance was kept.
+	CurrentProvenance *menu.Provenance `json:"current_provenance"`
+	Provenance        *llm.Provenance  `json:"provenance,omitempty"`
+
+	ChunksFailed int       `json:"chunks_failed"`
+	Diff         *MenuDiff `json:"diff,omitempty"`
+	Error        string    `json:"error,omitempty"`
+}
+
+// MenuDiff is how a new parse differs from the current one. Items are
+// matched by name; a name listed more than once is matched in order.
+type MenuDiff struct {
+	Added     []menu.Item  `json:"added"`
+	Removed   []menu.Item  `json:"removed"`
+	Changed   []ItemChange `json:"changed"`
+	Unchanged int          `json:"unchanged"`
+
+	TaxPercent Change `json:"tax_percent"`
+	CostForTwo Change `json:"cost_for_two"`
+}
+
+// ItemChange is an item whose category or price differs.
+type ItemChange struct {
+	From menu.Item `json:"from"`
+	To   menu.Item `json:"to"`
+}
+
+type Change struct {
+	From float64 `json:"from"`
+	To   float64 `json:"to"`
+}
+
+// currentParse is the part of menu_uploads.parsed_data a reparse is
+// compared with.
+type currentParse struct {
+	Items      []menu.Item      `json:"items"`
+	TaxPercent float64          `json:"tax_percent"`
+	CostForTwo *menu.CostForTwo `json:"cost_for_two"`
+	Provenance *menu.Provenance `json:"provenance"`
+}
+
+// Reparse parses uploads again from their OCR text with prompt version
+// and diffs each result against the current parse. Nothing is saved, so
+// a prompt can be tried on real menus before it becomes the default.
+func (s *Service) Reparse(ctx context.Context, ids []int, version string) ([]ReparseResult, error) {
+	uploads, err := s.repo.ParsedUploads(ids)
+	if err != nil {
+		return nil, err
+	}
+	found := map[int]ParsedUpload{}
+	for _, u := range uploads {
+		found[u.ID] = u
+	}
+
+	results := make([]ReparseResult, 0, len(ids))
+	for _, id := range ids {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		result := ReparseResult{MenuID: id, PromptVersion: version}
+
+		u, ok := found[id]
+		if !ok {
+			result.Error = "no parsed menu with OCR text"
+			results = append(results, result)
+			continue
+		}
+		s.reparse(ctx, u, &result)
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func (s *Service) reparse(ctx context.Context, u ParsedUpload, result *ReparseResult) {
+	var current currentParse
+	if err := json.Unmarshal(u.ParsedData, &current); err != nil {
+		result.Error = "current parse unreadable: " + err.Error()
+		return
+	}
+	result.CurrentProvenance = current.Provenance
+
+	chunks, err := s.parseChunks(ctx, u.RawText, u.Languages, result.PromptVersion, nil)
+	for _, c := range chunks {
+		if !c.OK {
+			result.ChunksFailed++
+		}
+	}
+	merged, ok := mergeChunks(chunks)
+	if !ok {
+		result.Error = err.Error()
+		return
+	}
+	result.Provenance = merged.Provenance
+
+	parsed, cost, err := buildMenu(merged)
+	if err != nil {
+		result.Error = err.Error()
+		return
+	}
+
+	result.Diff = diffMenus(current.Items, parsed.Items)
+	result.Diff.TaxPercent = Change{From: current.TaxPercent, To: parsed.TaxPercent}
+	result.Diff.CostForTwo.To = cost.Calculation.Total
+	if current.CostForTwo != nil {
+		result.Diff.CostForTwo.From = current.CostForTwo.Calculation.Total
+	}
+}
+
+// diffMenus matches items by name, in order of first appearance.
+func diffMenus(current, next []menu.Item) *MenuDiff {
+	diff := &MenuDiff{Added: []menu.Item{}, Removed: []menu.Item{}, Changed: []ItemChange{}}
+
+	var keys []string
+	before, after := map[string][]menu.Item{}, map[string][]menu.Item{}
+	for _, it := range current {
+		k := itemKey(it)
+		if before[k] == nil && after[k] == nil {
+			keys = append(keys, k)
+		}
+		before[k] = append(before[k], it)
+	}
+	for _, it := range next {
+		k := itemKey(it)
+		if before[k] == nil && after[k] == nil {
+			keys = append(keys, k)
+		}
+		after[k] = append(after[k], it)
+	}
+
+	for _, k := range keys {
+		b, a := before[k], after[k]
+		n := min(len(b), len(a))
+		for i := 0; i < n; i++ {
+			if b[i].Price == a[i].Price && b[i].Category == a[i].Category {
+				diff.Unchanged++
+			} else {
+				diff.Changed = append(diff.Changed, ItemChange{From: b[i], To: a[i]})
+			}
+		}
+		diff.Removed = append(diff.Removed, b[n:]...)
+		diff.Added = append(diff.Added, a[n:]...)
+	}
+	return diff
+}
+
+func itemKey(it menu.Item) string {
+	return strings.Join(strings.Fields(strings.ToLower(it.Name)), " ")
+}
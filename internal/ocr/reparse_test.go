@@ -0,0 +1,37 @@
+package ocr
+
+import (
+	"testing"
+
+	"bhojanalya/internal/menu"
+)
+
+func TestDiffMenusMatchesItemsByName(t *testing.T) {
+	current := []menu.Item{
+		{Name: "Paneer Tikka", Category: "starter", Price: 220},
+		{Name: "Dal Makhani", Category: "main_course", Price: 280},
+		{Name: "Masala Chai", Category: "drink", Price: 40},
+		{Name: "Papad", Category: "starter", Price: 30},
+	}
+	next := []menu.Item{
+		{Name: "paneer  tikka", Category: "starter", Price: 220},
+		{Name: "Dal Makhani", Category: "main_course", Price: 260},
+		{Name: "Dal Makhani", Category: "main_course", Price: 320},
+		{Name: "Masala Chai", Category: "drink", Price: 40},
+		{Name: "Gulab Jamun", Category: "dessert", Price: 90},
+	}
+
+	diff := diffMenus(current, next)
+	if diff.Unchanged != 2 {
+		t.Fatalf("expected 2 unchanged items, got %d", diff.Unchanged)
+	}
+	if len(diff.Changed) != 1 || diff.Changed[0].From.Price != 280 || diff.Changed[0].To.Price != 260 {
+		t.Fatalf("unexpected changes: %+v", diff.Changed)
+	}
+	if len(diff.Added) != 2 || diff.Added[0].Price != 320 || diff.Added[1].Name != "Gulab Jamun" {
+		t.Fatalf("unexpected additions: %+v", diff.Added)
+	}
+	if len(diff.Removed) != 1 || diff.Removed[0].Name != "Papad" {
+		t.Fatalf("unexpected removals: %+v", diff.Removed)
+	}
+}
@@ -2,9 +2,15 @@ package ocr
 
 import (
 	"context"
-	"database/sql"
+	"encoding/json"
+	"fmt"
 	"log"
+	"time"
 
+	"bhojanalya/internal/menu"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -22,50 +28,43 @@ func NewRepository(db *pgxpool.Pool) *Repository {
 // ─────────────────────────────────────────────────────────────
 //
 
-func (r *Repository) FetchPending() (int, string, error) {
-	ctx := context.Background()
-
-	tx, err := r.db.Begin(ctx)
-	if err != nil {
-		return 0, "", err
-	}
-	defer tx.Rollback(ctx)
-
-	var id int
-	var imageURL string
-
-	err = tx.QueryRow(ctx, `
-		SELECT id, image_url
-		FROM menu_uploads
-		WHERE status = 'MENU_UPLOADED'
-		ORDER BY created_at
-		LIMIT 1
-		FOR UPDATE SKIP LOCKED
-	`).Scan(&id, &imageURL)
-
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return 0, "", sql.ErrNoRows
-		}
-		return 0, "", err
-	}
-
-	// Mark as processing immediately (atomic claim)
-	_, err = tx.Exec(ctx, `
-		UPDATE menu_uploads
+// FetchPending claims up to limit of the oldest uploaded menus for
+// workerID, leased for lease. Rows locked by another worker are skipped.
+func (r *Repository) FetchPending(workerID string, lease time.Duration, limit int) ([]OCRJob, error) {
+	rows, err := r.db.Query(context.Background(), `
+		WITH claimed AS (
+			SELECT mu.id
+			FROM menu_uploads mu
+			WHERE mu.status = 'MENU_UPLOADED'
+			  AND (mu.next_attempt_at IS NULL OR mu.next_attempt_at <= now())
+			ORDER BY mu.created_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE menu_uploads mu
 		SET status = 'OCR_PROCESSING',
+		    claimed_by = $2,
+		    lease_expires_at = now() + make_interval(secs => $3),
 		    updated_at = now()
-		WHERE id = $1
-	`, id)
+		FROM claimed, restaurants r
+		WHERE mu.id = claimed.id
+		  AND r.id = mu.restaurant_id
+		RETURNING mu.id, mu.image_url, COALESCE(mu.language_hint, r.menu_language, ''), mu.retries
+	`, limit, workerID, lease.Seconds())
 	if err != nil {
-		return 0, "", err
+		return nil, err
 	}
+	defer rows.Close()
 
-	if err := tx.Commit(ctx); err != nil {
-		return 0, "", err
+	var jobs []OCRJob
+	for rows.Next() {
+		var j OCRJob
+		if err := rows.Scan(&j.ID, &j.ObjectKey, &j.LanguageHint, &j.Retries); err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, j)
 	}
-
-	return id, imageURL, nil
+	return jobs, rows.Err()
 }
 
 //
@@ -74,18 +73,80 @@ func (r *Repository) FetchPending() (int, string, error) {
 // ─────────────────────────────────────────────────────────────
 //
 
-func (r *Repository) SaveOCRText(id int, text string) error {
-	_, err := r.db.Exec(
+// SaveOCRResult stores the text together with its layout, the mean word
+// confidence, the lines flagged for review, the languages used and how
+// each page was read, and moves the upload to OCR_DONE. It returns
+// ErrLeaseLost unless workerID still holds the upload in OCR_PROCESSING.
+func (r *Repository) SaveOCRResult(id int, workerID string, text string, doc *Document, lowConfidence []menu.OCRRegion, languages string) error {
+	layout, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	flagged, err := json.Marshal(lowConfidence)
+	if err != nil {
+		return err
+	}
+	methods, err := json.Marshal(doc.PageMethods())
+	if err != nil {
+		return err
+	}
+
+	cmd, err := r.db.Exec(
 		context.Background(),
 		`
 		UPDATE menu_uploads
 		SET raw_text = $1,
+		    ocr_layout = $2,
+		    ocr_confidence = $3,
+		    ocr_low_confidence = $4,
+		    ocr_language = $5,
+		    ocr_page_methods = $6,
 		    status = 'OCR_DONE',
+		    claimed_by = NULL,
+		    lease_expires_at = NULL,
 		    error_message = NULL,
 		    updated_at = now()
-		WHERE id = $2
+		WHERE id = $7
+		  AND claimed_by = $8
+		  AND status = 'OCR_PROCESSING'
 		`,
 		text,
+		layout,
+		doc.MeanConfidence(),
+		flagged,
+		languages,
+		methods,
+		id,
+		workerID,
+	)
+	return leaseHeld(cmd, err)
+}
+
+// SavePageMethods records how each page was read, including the pages
+// that failed, for a PDF whose OCR did not complete. Nothing is written
+// once workerID no longer holds the upload.
+func (r *Repository) SavePageMethods(id int, workerID string, doc *Document) error {
+	methods, err := json.Marshal(doc.PageMethods())
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(
+		context.Background(),
+		`UPDATE menu_uploads SET ocr_page_methods = $1, updated_at = now() WHERE id = $2 AND claimed_by = $3`,
+		methods,
+		id,
+		workerID,
+	)
+	return err
+}
+
+// SaveProcessedImage records where the preprocessed image was stored.
+func (r *Repository) SaveProcessedImage(id int, objectKey string) error {
+	_, err := r.db.Exec(
+		context.Background(),
+		`UPDATE menu_uploads SET processed_image_key = $1 WHERE id = $2`,
+		objectKey,
 		id,
 	)
 	return err
@@ -97,52 +158,340 @@ func (r *Repository) SaveOCRText(id int, text string) error {
 // ─────────────────────────────────────────────────────────────
 //
 
-func (r *Repository) FetchForLLMParsing() (int, string, error) {
-	ctx := context.Background()
-
-	tx, err := r.db.Begin(ctx)
+// FetchForLLMParsing claims up to limit of the oldest OCRed menus for
+// workerID, leased for lease.
+func (r *Repository) FetchForLLMParsing(workerID string, lease time.Duration, limit int) ([]LLMJob, error) {
+	rows, err := r.db.Query(context.Background(), `
+		WITH claimed AS (
+			SELECT id
+			FROM menu_uploads
+			WHERE status = 'OCR_DONE'
+			  AND raw_text IS NOT NULL
+			  AND parsed_data IS NULL
+			  AND (next_attempt_at IS NULL OR next_attempt_at <= now())
+			ORDER BY updated_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE menu_uploads mu
+		SET status = 'PARSING_LLM',
+		    claimed_by = $2,
+		    lease_expires_at = now() + make_interval(secs => $3),
+		    updated_at = now()
+		FROM claimed
+		WHERE mu.id = claimed.id
+		RETURNING mu.id, mu.raw_text, COALESCE(mu.ocr_language, ''), mu.retries, mu.llm_chunks
+	`, limit, workerID, lease.Seconds())
 	if err != nil {
-		return 0, "", err
+		return nil, err
+	}
+	defer rows.Close()
+
+	var jobs []LLMJob
+	for rows.Next() {
+		var j LLMJob
+		var chunks []byte
+		if err := rows.Scan(&j.ID, &j.RawText, &j.Languages, &j.Retries, &chunks); err != nil {
+			return nil, err
+		}
+		if chunks != nil {
+			if err := json.Unmarshal(chunks, &j.Chunks); err != nil {
+				return nil, fmt.Errorf("menu %d: llm_chunks: %w", j.ID, err)
+			}
+		}
+		jobs = append(jobs, j)
 	}
-	defer tx.Rollback(ctx)
+	return jobs, rows.Err()
+}
 
-	var id int
-	var rawText string
+// SaveChunkResults records how each chunk of an upload's OCR text parsed.
+// Nothing is written once workerID no longer holds the upload.
+func (r *Repository) SaveChunkResults(id int, workerID string, results []ChunkResult) error {
+	data, err := json.Marshal(results)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.Exec(context.Background(), `
+		UPDATE menu_uploads
+		SET llm_chunks = $2
+		WHERE id = $1
+		  AND claimed_by = $3
+	`, id, data, workerID)
+	return err
+}
 
-	err = tx.QueryRow(ctx, `
-		SELECT id, raw_text
+// ParsedUploads loads the OCR text and current parse of those of ids
+// that have both.
+func (r *Repository) ParsedUploads(ids []int) ([]ParsedUpload, error) {
+	rows, err := r.db.Query(context.Background(), `
+		SELECT id, raw_text, COALESCE(ocr_language, ''), parsed_data
 		FROM menu_uploads
-		WHERE status = 'OCR_DONE'
+		WHERE id = ANY($1)
 		  AND raw_text IS NOT NULL
-		  AND parsed_data IS NULL
-		ORDER BY updated_at
-		LIMIT 1
-		FOR UPDATE SKIP LOCKED
-	`).Scan(&id, &rawText)
+		  AND parsed_data IS NOT NULL
+		ORDER BY id
+	`, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var uploads []ParsedUpload
+	for rows.Next() {
+		var u ParsedUpload
+		if err := rows.Scan(&u.ID, &u.RawText, &u.Languages, &u.ParsedData); err != nil {
+			return nil, err
+		}
+		uploads = append(uploads, u)
+	}
+	return uploads, rows.Err()
+}
 
+// QueueDepth counts uploads waiting for or inside each pipeline stage.
+func (r *Repository) QueueDepth() (map[string]int, error) {
+	rows, err := r.db.Query(context.Background(), `
+		SELECT status, count(*)
+		FROM menu_uploads
+		WHERE status IN ('MENU_UPLOADED', 'OCR_PROCESSING', 'OCR_DONE', 'PARSING_LLM')
+		GROUP BY status
+	`)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return 0, "", sql.ErrNoRows
+		return nil, err
+	}
+	defer rows.Close()
+
+	depth := map[string]int{}
+	for rows.Next() {
+		var status string
+		var n int
+		if err := rows.Scan(&status, &n); err != nil {
+			return nil, err
 		}
-		return 0, "", err
+		depth[status] = n
 	}
+	return depth, rows.Err()
+}
 
-	// Mark as parsing
-	_, err = tx.Exec(ctx, `
+//
+// ─────────────────────────────────────────────────────────────
+//  LEASES
+// ─────────────────────────────────────────────────────────────
+//
+
+// ErrLeaseLost is returned by the writes that end a stage when the
+// worker no longer holds the job: its lease expired and the reaper
+// requeued or failed it, and another worker may have claimed it since.
+// The worker must drop the job without writing anything else.
+var ErrLeaseLost = menu.ErrLeaseLost
+
+// leaseHeld turns a stage-ending write that matched no row into
+// ErrLeaseLost.
+func leaseHeld(cmd pgconn.CommandTag, err error) error {
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return ErrLeaseLost
+	}
+	return nil
+}
+
+// RenewLease extends workerID's lease on a job still in progress. It
+// returns false when the lease was lost, e.g. reaped after a stall.
+func (r *Repository) RenewLease(id int, workerID string, lease time.Duration) (bool, error) {
+	cmd, err := r.db.Exec(context.Background(), `
 		UPDATE menu_uploads
-		SET status = 'PARSING_LLM',
+		SET lease_expires_at = now() + make_interval(secs => $3)
+		WHERE id = $1
+		  AND claimed_by = $2
+		  AND status IN ('OCR_PROCESSING', 'PARSING_LLM')
+	`, id, workerID, lease.Seconds())
+	if err != nil {
+		return false, err
+	}
+	return cmd.RowsAffected() == 1, nil
+}
+
+// ReapExpiredLeases hands jobs whose worker stopped renewing back to the
+// stage they were claimed from, counting the attempt. Jobs that reach
+// maxAttempts are failed for good. Rows claimed before leases existed
+// count as expired once untouched for a lease.
+func (r *Repository) ReapExpiredLeases(lease time.Duration, maxAttempts int) ([]Transition, error) {
+	rows, err := r.db.Query(context.Background(), `
+		WITH expired AS (
+			SELECT id, status, COALESCE(claimed_by, '') AS claimed_by
+			FROM menu_uploads
+			WHERE status IN ('OCR_PROCESSING', 'PARSING_LLM')
+			  AND (lease_expires_at < now()
+			       OR (lease_expires_at IS NULL AND updated_at < now() - make_interval(secs => $2)))
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE menu_uploads mu
+		SET status = CASE
+		        WHEN mu.attempts + 1 >= $1 THEN 'FAILED'
+		        WHEN mu.status = 'OCR_PROCESSING' THEN 'MENU_UPLOADED'
+		        ELSE 'OCR_DONE'
+		    END,
+		    error_message = CASE
+		        WHEN mu.attempts + 1 >= $1 THEN 'processing abandoned after ' || (mu.attempts + 1) || ' interrupted attempts'
+		        ELSE mu.error_message
+		    END,
+		    rejection_reason = CASE
+		        WHEN mu.attempts + 1 >= $1 THEN 'processing abandoned after ' || (mu.attempts + 1) || ' interrupted attempts'
+		        ELSE mu.rejection_reason
+		    END,
+		    attempts = mu.attempts + 1,
+		    claimed_by = NULL,
+		    lease_expires_at = NULL,
+		    updated_at = now()
+		FROM expired
+		WHERE mu.id = expired.id
+		RETURNING mu.id, expired.status, mu.status, expired.claimed_by
+	`, maxAttempts, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	return scanTransitions(rows)
+}
+
+//
+// ─────────────────────────────────────────────────────────────
+//  RETRIES & DEAD LETTERS
+// ─────────────────────────────────────────────────────────────
+//
+
+// deadStatuses are the statuses the pipeline never leaves on its own.
+var deadStatuses = []string{"OCR_FAILED", "PARSING_FAILED", "FAILED"}
+
+// ScheduleRetry puts an upload workerID holds in from back in queue
+// (MENU_UPLOADED or OCR_DONE) to be claimed again after delay. It returns
+// ErrLeaseLost if workerID no longer holds it.
+func (r *Repository) ScheduleRetry(id int, workerID string, from string, queue string, errMsg string, delay time.Duration) error {
+	cmd, err := r.db.Exec(context.Background(), `
+		UPDATE menu_uploads
+		SET status = $2,
+		    error_message = $3,
+		    retries = retries + 1,
+		    next_attempt_at = now() + make_interval(secs => $4),
+		    claimed_by = NULL,
+		    lease_expires_at = NULL,
 		    updated_at = now()
 		WHERE id = $1
+		  AND claimed_by = $5
+		  AND status = $6
+	`, id, queue, errMsg, delay.Seconds(), workerID, from)
+	return leaseHeld(cmd, err)
+}
+
+// RecordError appends a failed attempt to the upload's error history.
+func (r *Repository) RecordError(id int, stage string, errMsg string, transient bool, attempt int) error {
+	_, err := r.db.Exec(context.Background(), `
+		INSERT INTO menu_upload_errors (menu_upload_id, stage, error, transient, attempt)
+		VALUES ($1, $2, $3, $4, $5)
+	`, id, stage, errMsg, transient, attempt)
+	return err
+}
+
+// DeadLetters lists failed uploads, most recent first.
+func (r *Repository) DeadLetters(limit int) ([]DeadLetter, error) {
+	rows, err := r.db.Query(context.Background(), `
+		SELECT mu.id, mu.restaurant_id, r.name, mu.status,
+		       COALESCE(mu.error_message, mu.rejection_reason),
+		       mu.retries, mu.attempts, mu.updated_at
+		FROM menu_uploads mu
+		JOIN restaurants r ON r.id = mu.restaurant_id
+		WHERE mu.status = ANY($1)
+		ORDER BY mu.updated_at DESC
+		LIMIT $2
+	`, deadStatuses, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	letters := []DeadLetter{}
+	for rows.Next() {
+		var d DeadLetter
+		if err := rows.Scan(
+			&d.ID, &d.RestaurantID, &d.RestaurantName, &d.Status,
+			&d.Error, &d.Retries, &d.Attempts, &d.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		letters = append(letters, d)
+	}
+	return letters, rows.Err()
+}
+
+// JobErrors returns an upload's failed attempts, oldest first.
+func (r *Repository) JobErrors(id int) ([]JobError, error) {
+	rows, err := r.db.Query(context.Background(), `
+		SELECT stage, error, transient, attempt, created_at
+		FROM menu_upload_errors
+		WHERE menu_upload_id = $1
+		ORDER BY created_at, id
 	`, id)
 	if err != nil {
-		return 0, "", err
+		return nil, err
 	}
+	defer rows.Close()
+
+	history := []JobError{}
+	for rows.Next() {
+		var e JobError
+		if err := rows.Scan(&e.Stage, &e.Error, &e.Transient, &e.Attempt, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		history = append(history, e)
+	}
+	return history, rows.Err()
+}
 
-	if err := tx.Commit(ctx); err != nil {
-		return 0, "", err
+// Requeue sends failed uploads back through the pipeline with fresh retry
+// and attempt counts. Uploads whose OCR succeeded restart at the LLM
+// stage. Ids not in a failed status are ignored.
+func (r *Repository) Requeue(ids []int) ([]Transition, error) {
+	rows, err := r.db.Query(context.Background(), `
+		WITH dead AS (
+			SELECT id, status
+			FROM menu_uploads
+			WHERE id = ANY($1)
+			  AND status = ANY($2)
+			FOR UPDATE
+		)
+		UPDATE menu_uploads mu
+		SET status = CASE
+		        WHEN mu.status <> 'OCR_FAILED' AND mu.ocr_layout IS NOT NULL THEN 'OCR_DONE'
+		        ELSE 'MENU_UPLOADED'
+		    END,
+		    error_message = NULL,
+		    rejection_reason = NULL,
+		    retries = 0,
+		    attempts = 0,
+		    next_attempt_at = NULL,
+		    updated_at = now()
+		FROM dead
+		WHERE mu.id = dead.id
+		RETURNING mu.id, dead.status, mu.status, ''
+	`, ids, deadStatuses)
+	if err != nil {
+		return nil, err
 	}
+	return scanTransitions(rows)
+}
 
-	return id, rawText, nil
+func scanTransitions(rows pgx.Rows) ([]Transition, error) {
+	defer rows.Close()
+
+	moved := []Transition{}
+	for rows.Next() {
+		var t Transition
+		if err := rows.Scan(&t.ID, &t.From, &t.To, &t.ClaimedBy); err != nil {
+			return nil, err
+		}
+		moved = append(moved, t)
+	}
+	return moved, rows.Err()
 }
 
 //
@@ -151,21 +500,30 @@ func (r *Repository) FetchForLLMParsing() (int, string, error) {
 // ─────────────────────────────────────────────────────────────
 //
 
-func (r *Repository) UpdateStatus(id int, status string, errMsg *string) error {
-	_, err := r.db.Exec(
+// UpdateStatus moves a job workerID holds in from to status and releases
+// the lease: every caller ends a worker's hold on the job. It returns
+// ErrLeaseLost if workerID no longer holds the job in from.
+func (r *Repository) UpdateStatus(id int, workerID string, from string, status string, errMsg *string) error {
+	cmd, err := r.db.Exec(
 		context.Background(),
 		`
 		UPDATE menu_uploads
 		SET status = $1,
 		    error_message = $2,
+		    claimed_by = NULL,
+		    lease_expires_at = NULL,
 		    updated_at = now()
 		WHERE id = $3
+		  AND claimed_by = $4
+		  AND status = $5
 		`,
 		status,
 		errMsg,
 		id,
+		workerID,
+		from,
 	)
-	return err
+	return leaseHeld(cmd, err)
 }
 
 //
@@ -182,6 +540,8 @@ func (r *Repository) SaveParsedData(id int, parsed any) error {
 		SET parsed_data = $1,
 		    status = 'PARSED',
 		    error_message = NULL,
+		    claimed_by = NULL,
+		    lease_expires_at = NULL,
 		    updated_at = now()
 		WHERE id = $2
 		`,
@@ -204,6 +564,8 @@ func (r *Repository) MarkFailed(id int, reason string) error {
 		UPDATE menu_uploads
 		SET status = 'FAILED',
 		    error_message = $1,
+		    claimed_by = NULL,
+		    lease_expires_at = NULL,
 		    updated_at = now()
 		WHERE id = $2
 		`,
@@ -241,3 +603,39 @@ func (r *Repository) GetRestaurantID(menuID int) (int, error) {
 
 	return restaurantID, err
 }
+
+//
+// ─────────────────────────────────────────────────────────────
+//  JOB NOTIFICATIONS (LISTEN menu_jobs)
+// ─────────────────────────────────────────────────────────────
+//
+
+// jobsChannel is notified by the menu_uploads trigger from migration 0017.
+const jobsChannel = "menu_jobs"
+
+// Listen holds a connection on LISTEN menu_jobs and calls onNotify with
+// each payload (the status an upload moved to). onListen runs once the
+// LISTEN is active, so callers can catch up on anything sent before. It
+// returns when ctx is done or the connection fails.
+func (r *Repository) Listen(ctx context.Context, onListen func(), onNotify func(status string)) error {
+	pooled, err := r.db.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	// A connection left in LISTEN must not go back to the pool.
+	conn := pooled.Hijack()
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+jobsChannel); err != nil {
+		return err
+	}
+	onListen()
+
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		onNotify(n.Payload)
+	}
+}
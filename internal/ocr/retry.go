@@ -0,0 +1,69 @@
+package ocr
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"time"
+
+	"bhojanalya/internal/llm"
+)
+
+// Pipeline stages, as recorded in menu_upload_errors.stage.
+const (
+	StageOCR = "ocr"
+	StageLLM = "llm"
+)
+
+// stageStatus is the status an upload holds while a stage works on it.
+var stageStatus = map[string]string{
+	StageOCR: "OCR_PROCESSING",
+	StageLLM: "PARSING_LLM",
+}
+
+// isTransient reports whether err is worth retrying unchanged: provider
+// rate limits and 5xx responses, R2 throttling and server errors, network
+// failures and timeouts. Anything else, such as an unreadable file or a
+// malformed LLM reply, fails the same way every time.
+func isTransient(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var apiErr *llm.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Temporary()
+	}
+
+	// R2 (S3) responses carry their status code.
+	var respErr interface{ HTTPStatusCode() int }
+	if errors.As(err, &respErr) {
+		code := respErr.HTTPStatusCode()
+		return code == http.StatusTooManyRequests || code >= 500
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+
+	return errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// retryDelay is the wait before retry number retry (0-based): base
+// doubled per retry, capped at maxDelay, with the upper half jittered so
+// uploads that failed together do not retry together.
+func retryDelay(retry int, base, maxDelay time.Duration) time.Duration {
+	d := base
+	for i := 0; i < retry && d < maxDelay; i++ {
+		d *= 2
+	}
+	d = min(d, maxDelay)
+
+	half := d / 2
+	return half + rand.N(half+1)
+}
@@ -0,0 +1,54 @@
+package ocr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"bhojanalya/internal/llm"
+)
+
+type statusErr int
+
+func (e statusErr) Error() string       { return fmt.Sprintf("status %d", int(e)) }
+func (e statusErr) HTTPStatusCode() int { return int(e) }
+
+func TestIsTransient(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"gemini rate limit", &llm.APIError{Provider: "gemini", StatusCode: 429}, true},
+		{"gemini server error", fmt.Errorf("parse: %w", &llm.APIError{StatusCode: 503}), true},
+		{"gemini bad request", &llm.APIError{StatusCode: 400}, false},
+		{"r2 throttled", statusErr(503), true},
+		{"r2 missing object", statusErr(404), false},
+		{"network", &net.OpError{Op: "dial", Err: errors.New("connection refused")}, true},
+		{"timeout", fmt.Errorf("ocr: %w", context.DeadlineExceeded), true},
+		{"bad output", errors.New("gemini returned non-json output"), false},
+		{"nil", nil, false},
+	}
+
+	for _, tc := range cases {
+		if got := isTransient(tc.err); got != tc.want {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.want, got)
+		}
+	}
+}
+
+func TestRetryDelayBacksOffWithJitter(t *testing.T) {
+	base, maxDelay := 10*time.Second, time.Minute
+
+	for retry, ceiling := range []time.Duration{10 * time.Second, 20 * time.Second, 40 * time.Second, time.Minute, time.Minute} {
+		for i := 0; i < 100; i++ {
+			d := retryDelay(retry, base, maxDelay)
+			if d < ceiling/2 || d > ceiling {
+				t.Fatalf("retry %d: delay %s outside [%s, %s]", retry, d, ceiling/2, ceiling)
+			}
+		}
+	}
+}
@@ -2,16 +2,20 @@ package ocr
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"bhojanalya/internal/competition"
+	"bhojanalya/internal/lang"
 	"bhojanalya/internal/llm"
 	"bhojanalya/internal/menu"
 	"bhojanalya/internal/storage"
@@ -20,61 +24,249 @@ import (
 type Service struct {
 	repo            *Repository
 	r2              *storage.R2Client
-	llmClient       *llm.GeminiClient
+	llmClient       llm.Client
+	promptVersion   string
 	menuService     *menu.Service
 	competitionSvc  *competition.Service
 	pdfPreprocessor *PDFTextPreprocessor
+	imgPreprocessor *ImagePreprocessor
+	engine          OCREngine
+
+	// Pages of a PDF with fewer letters and digits than this in their
+	// text layer are OCRed instead.
+	minPDFTextChars int
+
+	// Lines below this confidence (0–100) are flagged for the reviewer.
+	lowConfidence float64
+
+	// chunking sizes the pieces long menus are parsed in.
+	chunking ChunkConfig
+
+	pool     PoolConfig
+	ocrStage *stage[OCRJob]
+	llmStage *stage[LLMJob]
+	ocrSlots *processSlots
+	llmRate  *rateLimiter
+
+	// workerID identifies this process in menu_uploads.claimed_by.
+	workerID string
+
+	// jobCtx is what jobs run with. It outlives the context the workers
+	// are run with so in-flight jobs can finish; Shutdown cancels it once
+	// its deadline passes.
+	jobCtx     context.Context
+	cancelJobs context.CancelFunc
+	running    sync.WaitGroup
+
+	listenOnce sync.Once
 }
 
 func NewService(
 	repo *Repository,
 	r2 *storage.R2Client,
-	llmClient *llm.GeminiClient,
+	llmClient llm.Client,
+	promptVersion string,
 	menuService *menu.Service,
 	competitionSvc *competition.Service,
+	engine OCREngine,
+	imgPreprocessor *ImagePreprocessor,
 ) *Service {
-	return &Service{
+	pool := PoolConfigFromEnv()
+
+	s := &Service{
 		repo:            repo,
 		r2:              r2,
 		llmClient:       llmClient,
+		promptVersion:   promptVersion,
 		menuService:     menuService,
 		competitionSvc:  competitionSvc,
 		pdfPreprocessor: NewPDFTextPreprocessor(),
+		imgPreprocessor: imgPreprocessor,
+		engine:          engine,
+		minPDFTextChars: minPDFTextCharsFromEnv(),
+		lowConfidence:   lowConfidenceThresholdFromEnv(),
+		chunking:        ChunkConfigFromEnv(),
+		pool:            pool,
+		ocrSlots:        newProcessSlots(pool.MaxOCRProcesses),
+		llmRate:         newRateLimiter(pool.LLMPerMinute),
+		workerID:        workerIDFromHost(),
+	}
+	s.jobCtx, s.cancelJobs = context.WithCancel(context.Background())
+
+	s.ocrStage = newStage(pool.OCRWorkers, pool.BatchSize, pool.IdlePoll,
+		func(limit int) ([]OCRJob, error) {
+			jobs, err := s.repo.FetchPending(s.workerID, pool.Lease, limit)
+			for _, job := range jobs {
+				s.recordEvent(job.ID, "MENU_UPLOADED", "OCR_PROCESSING", nil)
+			}
+			return jobs, err
+		},
+		func(ctx context.Context, job OCRJob) {
+			ctx, stop := s.holdLease(ctx, job.ID)
+			defer stop()
+			if err := s.processOCR(ctx, job); err != nil {
+				log.Println("[OCR WORKER] Error:", err)
+			}
+		},
+	)
+	s.llmStage = newStage(pool.LLMWorkers, pool.BatchSize, pool.IdlePoll,
+		func(limit int) ([]LLMJob, error) {
+			jobs, err := s.repo.FetchForLLMParsing(s.workerID, pool.Lease, limit)
+			for _, job := range jobs {
+				s.recordEvent(job.ID, "OCR_DONE", "PARSING_LLM", nil)
+			}
+			return jobs, err
+		},
+		func(ctx context.Context, job LLMJob) {
+			ctx, stop := s.holdLease(ctx, job.ID)
+			defer stop()
+			if err := s.processLLM(ctx, job); err != nil {
+				log.Println("[LLM WORKER] Error:", err)
+			}
+		},
+	)
+
+	return s
+}
+
+// workerIDFromHost names this process as hostname-pid.
+func workerIDFromHost() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "worker"
+	}
+	return fmt.Sprintf("%s-%d", host, os.Getpid())
+}
+
+// lowConfidenceThresholdFromEnv reads OCR_LOW_CONFIDENCE, default 60.
+func lowConfidenceThresholdFromEnv() float64 {
+	if v, err := strconv.ParseFloat(os.Getenv("OCR_LOW_CONFIDENCE"), 64); err == nil && v > 0 {
+		return v
 	}
+	return 60
 }
 
 // ─────────────────────────────────────────────
-// OCR WORKER
+// LEASES
 // ─────────────────────────────────────────────
 
-func (s *Service) RunOCRWorker() {
-	log.Println("[OCR WORKER] Started")
+// holdLease keeps this worker's claim on a job alive while it runs. The
+// returned context is cancelled with ErrLeaseLost if the claim is lost,
+// so the job stops instead of racing whoever holds it now. Call the
+// returned func when the job is finished.
+func (s *Service) holdLease(ctx context.Context, id int) (context.Context, func()) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	stop := heartbeat(s.pool.Lease/3,
+		func() (bool, error) {
+			return s.repo.RenewLease(id, s.workerID, s.pool.Lease)
+		},
+		func() {
+			log.Printf("[LEASE][%d] Lost lease; stopping the job", id)
+			cancel(ErrLeaseLost)
+		},
+		func(err error) {
+			log.Printf("[LEASE][%d] Renew failed: %v", id, err)
+		},
+	)
+	return ctx, func() {
+		stop()
+		cancel(nil)
+	}
+}
+
+// leaseLost reports whether err or ctx says the job is no longer this
+// worker's, in which case there is nothing left for it to write.
+func (s *Service) leaseLost(ctx context.Context, id int, err error) bool {
+	if !errors.Is(err, ErrLeaseLost) && !errors.Is(context.Cause(ctx), ErrLeaseLost) {
+		return false
+	}
+	log.Printf("[LEASE][%d] Lease lost; dropping the job", id)
+	return true
+}
+
+// endStage moves a job this worker holds in stage to status, releasing
+// the lease. It reports whether the job moved.
+func (s *Service) endStage(ctx context.Context, id int, stage string, status string, errMsg *string) bool {
+	err := s.repo.UpdateStatus(id, s.workerID, stageStatus[stage], status, errMsg)
+	if err != nil && !s.leaseLost(ctx, id, err) {
+		log.Printf("[PIPELINE][%d] Could not move to %s: %v", id, status, err)
+	}
+	return err == nil
+}
 
-	ticker := time.NewTicker(1 * time.Second)
+// RunReaper returns jobs whose worker stopped renewing its lease to their
+// queue, or fails them after PoolConfig.MaxAttempts. It returns when ctx
+// is cancelled.
+func (s *Service) RunReaper(ctx context.Context) {
+	log.Printf("[REAPER] Started (lease %s, max %d attempts)", s.pool.Lease, s.pool.MaxAttempts)
+
+	ticker := time.NewTicker(s.pool.Lease / 2)
 	defer ticker.Stop()
 
-	for range ticker.C {
-		if err := s.processOCR(); err != nil {
-			log.Println("[OCR WORKER] Error:", err)
+	for {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+
+		reaped, err := s.repo.ReapExpiredLeases(s.pool.Lease, s.pool.MaxAttempts)
+		if err != nil {
+			log.Println("[REAPER] Error:", err)
+			continue
+		}
+
+		var requeued, failed int
+		for _, t := range reaped {
+			if t.To == "FAILED" {
+				failed++
+			} else {
+				requeued++
+			}
+			s.recordEvent(t.ID, t.From, t.To, fmt.Errorf("lease held by %q expired", t.ClaimedBy))
+		}
+		if requeued > 0 || failed > 0 {
+			log.Printf("[REAPER] Requeued %d and failed %d abandoned jobs", requeued, failed)
+		}
+		if requeued > 0 {
+			s.ocrStage.wake()
+			s.llmStage.wake()
 		}
 	}
 }
 
-func (s *Service) processOCR() error {
-	id, objectKey, err := s.repo.FetchPending()
-	if err != nil || id == 0 {
-		return nil
-	}
+// ─────────────────────────────────────────────
+// OCR WORKER
+// ─────────────────────────────────────────────
+
+// RunOCRWorker runs the OCR worker pool until ctx is cancelled, then
+// stops claiming and returns once the jobs in flight are done. See
+// Shutdown.
+func (s *Service) RunOCRWorker(ctx context.Context) {
+	s.running.Add(1)
+	defer s.running.Done()
+
+	log.Printf("[OCR WORKER] Started %d workers (max %d OCR processes)",
+		s.pool.OCRWorkers, s.pool.MaxOCRProcesses)
+	s.listenOnce.Do(func() { go s.listenForJobs(ctx) })
+
+	s.ocrStage.run(ctx, s.jobCtx, func(err error) {
+		log.Println("[OCR WORKER] Claim failed:", err)
+	})
+	log.Println("[OCR WORKER] Stopped")
+}
+
+func (s *Service) processOCR(ctx context.Context, job OCRJob) error {
+	id, objectKey, hint := job.ID, job.ObjectKey, job.LanguageHint
+
 	restaurantID, err := s.repo.GetRestaurantID(id)
 	if err != nil {
-		msg := err.Error()
-		_ = s.repo.UpdateStatus(id, "OCR_FAILED", &msg)
+		s.failOCR(ctx, job, err)
 		return nil
 	}
 
 
-	log.Printf("[OCR][%d] Processing restaurant %d", id,restaurantID)
-	_ = s.repo.UpdateStatus(id, "OCR_PROCESSING", nil)
+	log.Printf("[OCR][%d] Processing restaurant %d", id, restaurantID)
 
 	ext := strings.ToLower(filepath.Ext(objectKey))
 	if ext == "" {
@@ -84,113 +276,295 @@ func (s *Service) processOCR() error {
 	localPath := filepath.Join(os.TempDir(), fmt.Sprintf("menu_%d%s", id, ext))
 
 	if err := storage.DownloadFromR2(
-		context.Background(),
+		ctx,
 		s.r2.GetClient(),
 		s.r2.GetBucket(),
 		objectKey,
 		localPath,
 	); err != nil {
-		msg := err.Error()
-		_ = s.repo.UpdateStatus(id, "OCR_FAILED", &msg)
+		s.failOCR(ctx, job, err)
 		return nil
 	}
 	defer os.Remove(localPath)
 
-	var text string
+	var text, languages string
+	var doc *Document
 	if ext == ".pdf" {
-		text, err = s.processPDFtoOCR(id, localPath)
+		text, doc, languages, err = s.processPDFtoOCR(ctx, id, localPath, hint)
 	} else {
-		text, err = runTesseract(localPath)
+		imagePath := s.preprocessImage(ctx, id, objectKey, localPath)
+		if imagePath != localPath {
+			defer os.Remove(imagePath)
+		}
+
+		languages = s.languagesFor(ctx, id, imagePath, hint)
+		doc, err = s.recognize(ctx, imagePath, languages)
+		if err == nil {
+			doc.setMethod(PageMethodOCR)
+			text = doc.Text()
+		}
 	}
 
 	if err != nil {
-		msg := err.Error()
-		_ = s.repo.UpdateStatus(id, "OCR_FAILED", &msg)
+		// A PDF with unreadable pages comes back with them marked
+		// failed; keep which ones for the reviewer before failing.
+		if doc != nil {
+			if err := s.repo.SavePageMethods(id, s.workerID, doc); err != nil {
+				log.Printf("[OCR][%d] Could not save page methods: %v", id, err)
+			}
+		}
+		s.failOCR(ctx, job, err)
 		return nil
 	}
 
-	if err := s.repo.SaveOCRText(id, text); err != nil {
+	flagged := doc.LowConfidenceRegions(s.lowConfidence)
+	if err := s.repo.SaveOCRResult(id, s.workerID, text, doc, flagged, languages); err != nil {
+		if s.leaseLost(ctx, id, err) {
+			return nil
+		}
 		return err
 	}
-
-	_ = s.repo.UpdateStatus(id, "OCR_DONE", nil)
-	log.Printf("[OCR][%d] OCR completed", id)
+	s.recordEvent(id, "OCR_PROCESSING", "OCR_DONE", nil)
+	log.Printf("[OCR][%d] OCR completed (%s, confidence %.0f, %d low-confidence lines)",
+		id, languages, doc.MeanConfidence(), len(flagged))
 
 	return nil
 }
 
-// ─────────────────────────────────────────────
-// LLM WORKER (ATOMIC PARSING)
-// ─────────────────────────────────────────────
-
-func (s *Service) RunLLMWorker() {
-	log.Println("[LLM WORKER] Started")
+// preprocessImage cleans up a photographed menu for OCR and returns the
+// path to OCR. Preprocessing is best effort: on any failure the original
+// image is used.
+func (s *Service) preprocessImage(ctx context.Context, id int, objectKey string, imagePath string) string {
+	if s.imgPreprocessor == nil || !s.imgPreprocessor.Enabled() {
+		return imagePath
+	}
 
-	ticker := time.NewTicker(5 * time.Second)
-	defer ticker.Stop()
+	processedPath := filepath.Join(os.TempDir(), fmt.Sprintf("menu_%d_processed.png", id))
+	steps, err := s.imgPreprocessor.Process(imagePath, processedPath)
+	if err != nil {
+		log.Printf("[OCR][%d] Preprocessing failed, using original image: %v", id, err)
+		_ = os.Remove(processedPath)
+		return imagePath
+	}
+	if len(steps) == 0 {
+		return imagePath
+	}
+	log.Printf("[OCR][%d] Preprocessed (%s)", id, strings.Join(steps, ", "))
 
-	for range ticker.C {
-		if err := s.processLLM(); err != nil {
-			log.Println("[LLM WORKER] Error:", err)
+	if s.imgPreprocessor.SaveProcessed {
+		key := strings.TrimSuffix(objectKey, filepath.Ext(objectKey)) + "_processed.png"
+		if err := s.saveProcessedImage(ctx, id, key, processedPath); err != nil {
+			log.Printf("[OCR][%d] Could not save processed image: %v", id, err)
 		}
 	}
+
+	return processedPath
 }
 
-func (s *Service) processLLM() error {
-	ctx := context.Background()
+func (s *Service) saveProcessedImage(ctx context.Context, id int, key string, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
 
-	id, rawText, err := s.repo.FetchForLLMParsing()
-	if err != nil || id == 0 {
+	if _, err := s.r2.Upload(ctx, key, f); err != nil {
+		return err
+	}
+	return s.repo.SaveProcessedImage(id, key)
+}
+
+// listenForJobs wakes the stages on NOTIFY menu_jobs, reconnecting after
+// failures, until ctx is cancelled. While it is down the stages fall back
+// to IdlePoll.
+func (s *Service) listenForJobs(ctx context.Context) {
+	for {
+		err := s.repo.Listen(ctx,
+			func() {
+				// Catch up on anything announced while not listening.
+				s.ocrStage.wake()
+				s.llmStage.wake()
+			},
+			func(status string) {
+				switch status {
+				case "MENU_UPLOADED":
+					s.ocrStage.wake()
+				case "OCR_DONE":
+					s.llmStage.wake()
+				}
+			},
+		)
+		if ctx.Err() != nil {
+			return
+		}
+
+		log.Println("[PIPELINE] LISTEN menu_jobs lost, retrying in 5s:", err)
+		select {
+		case <-time.After(5 * time.Second):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Shutdown waits for RunOCRWorker and RunLLMWorker, whose context has been
+// cancelled, to finish their jobs in flight. If ctx ends first the jobs
+// are cancelled, killing OCR processes and LLM calls, and put back in
+// queue for the next start.
+func (s *Service) Shutdown(ctx context.Context) error {
+	drained := make(chan struct{})
+	go func() {
+		s.running.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
 		return nil
+	case <-ctx.Done():
+		log.Println("[PIPELINE] Shutdown deadline passed, cancelling jobs in flight")
+		s.cancelJobs()
+		<-drained
+		return ctx.Err()
 	}
-	restaurantID, err := s.repo.GetRestaurantID(id)
+}
+
+// recognize runs the engine once a process slot is free, so the workers
+// together never run more than MaxOCRProcesses tesseracts.
+func (s *Service) recognize(ctx context.Context, imagePath string, languages string) (*Document, error) {
+	s.ocrSlots.acquire()
+	defer s.ocrSlots.release()
+	return s.engine.Recognize(ctx, imagePath, languages)
+}
+
+// Stats reports queue depth and what the worker pools are doing.
+func (s *Service) Stats() (*PipelineStats, error) {
+	depth, err := s.repo.QueueDepth()
 	if err != nil {
-		s.failParsing(id, 0, err)
-		return nil
+		return nil, err
 	}
 
+	return &PipelineStats{
+		QueueDepth:      depth,
+		OCRWorkers:      s.pool.OCRWorkers,
+		OCRInFlight:     s.ocrStage.inFlight.Load(),
+		OCRProcessed:    s.ocrStage.processed.Load(),
+		OCRProcesses:    s.ocrSlots.inUse(),
+		MaxOCRProcesses: s.pool.MaxOCRProcesses,
+		LLMWorkers:      s.pool.LLMWorkers,
+		LLMInFlight:     s.llmStage.inFlight.Load(),
+		LLMProcessed:    s.llmStage.processed.Load(),
+		LLMPerMinute:    s.pool.LLMPerMinute,
+	}, nil
+}
 
-	log.Printf("[LLM][%d] Parsing restaurant %d", id, restaurantID)
-	_ = s.repo.UpdateStatus(id, "PARSING_LLM", nil)
+// languagesFor returns the hint if there is one, otherwise the languages
+// for the script the engine detects. Detection failures fall back to
+// English so a missing osd model never blocks OCR.
+func (s *Service) languagesFor(ctx context.Context, id int, imagePath string, hint string) string {
+	if hint != "" {
+		return hint
+	}
 
-	textToParse := rawText
-	if s.pdfPreprocessor.IsLikelyPDFText(rawText) {
-		textToParse = s.pdfPreprocessor.CleanPDFText(rawText)
+	detector, ok := s.engine.(ScriptDetector)
+	if !ok {
+		return lang.English
 	}
 
-	rawJSON, err := s.llmClient.ParseOCR(ctx, textToParse)
+	s.ocrSlots.acquire()
+	script, err := detector.DetectScript(ctx, imagePath)
+	s.ocrSlots.release()
 	if err != nil {
-		s.failParsing(id, restaurantID, err)
-		return nil
+		log.Printf("[OCR][%d] Script detection failed, using English: %v", id, err)
+		return lang.English
 	}
+	return lang.ForScript(script)
+}
+
+// ─────────────────────────────────────────────
+// LLM WORKER (ATOMIC PARSING)
+// ─────────────────────────────────────────────
 
-	parsedOCR, err := llm.ParseLLMResponse(rawJSON)
+// RunLLMWorker runs the LLM worker pool until ctx is cancelled, then
+// stops claiming and returns once the jobs in flight are done. See
+// Shutdown.
+func (s *Service) RunLLMWorker(ctx context.Context) {
+	s.running.Add(1)
+	defer s.running.Done()
+
+	log.Printf("[LLM WORKER] Started %d workers (%d calls/min)",
+		s.pool.LLMWorkers, s.pool.LLMPerMinute)
+	s.listenOnce.Do(func() { go s.listenForJobs(ctx) })
+
+	s.llmStage.run(ctx, s.jobCtx, func(err error) {
+		log.Println("[LLM WORKER] Claim failed:", err)
+	})
+	log.Println("[LLM WORKER] Stopped")
+}
+
+func (s *Service) processLLM(ctx context.Context, job LLMJob) error {
+	id, rawText, languages := job.ID, job.RawText, job.Languages
+
+	restaurantID, err := s.repo.GetRestaurantID(id)
 	if err != nil {
-		s.failParsing(id, restaurantID, err)
+		s.failParsing(ctx, job, 0, err)
 		return nil
 	}
 
-	parsedMenu := toParsedMenu(parsedOCR)
 
-	cost, err := menu.BuildCostForTwo(parsedMenu)
+	log.Printf("[LLM][%d] Parsing restaurant %d", id, restaurantID)
+
+	results, err := s.parseChunks(ctx, rawText, languages, s.promptVersion, job.Chunks)
+	if len(results) > 0 {
+		if saveErr := s.repo.SaveChunkResults(id, s.workerID, results); saveErr != nil {
+			log.Printf("[LLM][%d] Could not save chunk results: %v", id, saveErr)
+		}
+	}
+
+	parsed, ok := mergeChunks(results)
+	if !ok {
+		s.failParsing(ctx, job, restaurantID, err)
+		return nil
+	}
+	if err != nil {
+		// Chunks that failed for a passing reason are worth another
+		// attempt while retries last; the ones that parsed are kept and
+		// not sent again. Otherwise the menu goes to review with what
+		// parsed rather than being thrown away.
+		if ctx.Err() != nil || (isTransient(err) && job.Retries < s.pool.MaxRetries) {
+			s.failParsing(ctx, job, restaurantID, err)
+			return nil
+		}
+		log.Printf("[LLM][%d] Keeping partial parse: %v", id, err)
+	}
+
+	parsedMenu, cost, err := buildMenu(parsed)
 	if err != nil {
-		s.failParsing(id, restaurantID, err)
+		s.failParsing(ctx, job, restaurantID, err)
 		return nil
 	}
 
+	// Parsed by the rules alone when every model was down; nothing to
+	// check against.
+	if parsed.Provenance == nil || parsed.Provenance.Provider != "rules" {
+		parsedMenu.CrossCheck = crossCheck(parsed, llm.ParseRules(strings.Join(s.cleanPages(rawText), "\n\n")))
+		if parsedMenu.CrossCheck.Flagged {
+			log.Printf("[LLM][%d] Cross-check flagged: %s", id, strings.Join(parsedMenu.CrossCheck.Reasons, "; "))
+		}
+	}
+
 	// 🔒 ATOMIC WRITE — THIS IS THE FIX
 	if err := s.menuService.SaveParsedResult(
 		ctx,
 		restaurantID,
+		s.workerID,
 		parsedMenu,
 		cost,
 	); err != nil {
-		s.failParsing(id, restaurantID, err)
+		s.failParsing(ctx, job, restaurantID, err)
 		return nil
 	}
 
-	_ = s.repo.UpdateStatus(id, "PARSED", nil)
-
 	city, cuisine, err := s.menuService.GetMenuContext(ctx, restaurantID)
 	if err == nil {
 		_ = s.competitionSvc.RecomputeSnapshot(ctx, city, cuisine)
@@ -200,13 +574,150 @@ func (s *Service) processLLM() error {
 	return nil
 }
 
+// chunkText splits OCR text into the chunks it is parsed in, breaking
+// between pages where it can.
+func (s *Service) chunkText(rawText string) []string {
+	return splitChunks(s.cleanPages(rawText), s.chunking.MaxChars, s.chunking.Overlap)
+}
+
+// cleanPages splits OCR text into pages, cleaning PDF text page by page.
+func (s *Service) cleanPages(rawText string) []string {
+	pages := strings.Split(rawText, pageBreak)
+	if s.pdfPreprocessor.IsLikelyPDFText(rawText) {
+		for i, page := range pages {
+			pages[i] = s.pdfPreprocessor.CleanPDFText(page)
+		}
+	}
+	return pages
+}
+
+// parseChunks sends each chunk of the OCR text to the LLM with prompt
+// version, a few at a time. A chunk that parsed on an earlier attempt is
+// reused from previous if its text and prompt are unchanged. It returns
+// every chunk's result, and the failed chunks' errors joined.
+func (s *Service) parseChunks(ctx context.Context, rawText string, languages string, version string, previous []ChunkResult) ([]ChunkResult, error) {
+	chunks := s.chunkText(rawText)
+	if len(chunks) == 0 {
+		return nil, errors.New("empty OCR text")
+	}
+
+	var sourceLanguages []string
+	if !lang.IsEnglishOnly(languages) {
+		sourceLanguages = lang.Names(languages)
+	}
+
+	done := map[int]ChunkResult{}
+	for _, r := range previous {
+		if r.OK && r.Provenance != nil && r.Provenance.PromptVersion == version {
+			done[r.Index] = r
+		}
+	}
+
+	results := make([]ChunkResult, len(chunks))
+	errs := make([]error, len(chunks))
+	slots := make(chan struct{}, max(s.chunking.Concurrency, 1))
+	var wg sync.WaitGroup
+
+	for i, text := range chunks {
+		hash := chunkHash(text)
+		if r, ok := done[i]; ok && r.Hash == hash {
+			results[i] = r
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			r := ChunkResult{Index: i, Hash: hash, Chars: len(text)}
+			parsed, err := s.parseChunk(ctx, text, version, sourceLanguages)
+			if err != nil {
+				r.Error = err.Error()
+				errs[i] = fmt.Errorf("chunk %d of %d: %w", i+1, len(chunks), err)
+			} else {
+				r.OK, r.Items, r.Quarantined, r.TaxPercent = true, parsed.Items, parsed.Quarantined, parsed.TaxPercent
+				r.Provenance = parsed.Provenance
+			}
+			results[i] = r
+		}()
+	}
+	wg.Wait()
+
+	return results, errors.Join(errs...)
+}
+
+// parseChunk parses one chunk, splitting it when the reply does not fit
+// the output limit.
+func (s *Service) parseChunk(ctx context.Context, text string, version string, languages []string) (*llm.ParsedOCRResult, error) {
+	if err := s.llmRate.wait(ctx); err != nil {
+		return nil, err
+	}
+	parsed, err := llm.ParseMenu(ctx, s.llmClient, version, text, languages)
+	var truncated *llm.TruncatedError
+	if !errors.As(err, &truncated) {
+		return parsed, err
+	}
+
+	// The reply ran out of output tokens partway through the items;
+	// accepting it would silently lose the rest.
+	first, second, ok := halveChunk(text)
+	if !ok {
+		return nil, err
+	}
+	log.Printf("[LLM] Reply cut off for %d chars of text, parsing it in halves", len(text))
+
+	results := make([]ChunkResult, 2)
+	for i, half := range []string{first, second} {
+		p, err := s.parseChunk(ctx, half, version, languages)
+		if err != nil {
+			return nil, err
+		}
+		results[i] = ChunkResult{OK: true, Items: p.Items, Quarantined: p.Quarantined, TaxPercent: p.TaxPercent, Provenance: p.Provenance}
+	}
+	merged, _ := mergeChunks(results)
+	// The cut-off call used tokens too.
+	if merged.Provenance == nil {
+		merged.Provenance = &llm.Provenance{}
+	}
+	merged.Provenance.Add(*truncated.Provenance)
+	return merged, nil
+}
+
+// buildMenu turns merged LLM output into a menu and its cost for two.
+func buildMenu(parsed *llm.ParsedOCRResult) (*menu.ParsedMenu, *menu.CostForTwo, error) {
+	parsedMenu := toParsedMenu(parsed)
+
+	cost, err := menu.BuildCostForTwo(parsedMenu)
+	if err != nil {
+		return nil, nil, err
+	}
+	return parsedMenu, cost, nil
+}
+
 func (s *Service) failParsing(
-	menuID int,
+	ctx context.Context,
+	job LLMJob,
 	restaurantID int,
 	err error,
 ) {
+	if s.leaseLost(ctx, job.ID, err) {
+		return
+	}
+	if ctx.Err() != nil {
+		s.release(ctx, job.ID, StageLLM, "OCR_DONE", err)
+		return
+	}
+	if s.retryLater(ctx, job.ID, StageLLM, "OCR_DONE", job.Retries, err) {
+		return
+	}
+
 	msg := err.Error()
-	_ = s.repo.UpdateStatus(menuID, "PARSING_FAILED", &msg)
+	if !s.endStage(ctx, job.ID, StageLLM, "PARSING_FAILED", &msg) {
+		return
+	}
+	s.recordEvent(job.ID, "PARSING_LLM", "PARSING_FAILED", err)
 	_ = s.menuService.MarkParsingFailed(
 		context.Background(),
 		restaurantID,
@@ -214,6 +725,112 @@ func (s *Service) failParsing(
 	)
 }
 
+// ─────────────────────────────────────────────
+// RETRIES
+// ─────────────────────────────────────────────
+
+func (s *Service) failOCR(ctx context.Context, job OCRJob, err error) {
+	if s.leaseLost(ctx, job.ID, err) {
+		return
+	}
+	if ctx.Err() != nil {
+		s.release(ctx, job.ID, StageOCR, "MENU_UPLOADED", err)
+		return
+	}
+	if s.retryLater(ctx, job.ID, StageOCR, "MENU_UPLOADED", job.Retries, err) {
+		return
+	}
+
+	msg := err.Error()
+	if !s.endStage(ctx, job.ID, StageOCR, "OCR_FAILED", &msg) {
+		return
+	}
+	s.recordEvent(job.ID, "OCR_PROCESSING", "OCR_FAILED", err)
+}
+
+// retryLater records the failed attempt and, if err is transient and the
+// upload has retries left, puts it back in queue after a backoff. It
+// reports whether a retry was scheduled, or the lease turned out to be
+// lost so there is nothing to do; if not the caller fails the job.
+func (s *Service) retryLater(ctx context.Context, id int, stage string, queue string, retries int, err error) bool {
+	msg := err.Error()
+	transient := isTransient(err)
+
+	if recErr := s.repo.RecordError(id, stage, msg, transient, retries+1); recErr != nil {
+		log.Printf("[RETRY][%d] Could not record error: %v", id, recErr)
+	}
+
+	if !transient || retries >= s.pool.MaxRetries {
+		return false
+	}
+
+	delay := retryDelay(retries, s.pool.RetryBase, s.pool.RetryMax)
+	if err := s.repo.ScheduleRetry(id, s.workerID, stageStatus[stage], queue, msg, delay); err != nil {
+		if s.leaseLost(ctx, id, err) {
+			return true
+		}
+		log.Printf("[RETRY][%d] Could not schedule retry: %v", id, err)
+		return false
+	}
+	s.recordEvent(id, stageStatus[stage], queue, err)
+
+	log.Printf("[RETRY][%d] %s failed (%v); retry %d/%d in %s",
+		id, stage, err, retries+1, s.pool.MaxRetries, delay.Round(time.Second))
+	return true
+}
+
+// release puts a job cancelled by Shutdown straight back in queue. It
+// was interrupted, not failed, so it costs no retry.
+func (s *Service) release(ctx context.Context, id int, stage string, queue string, cause error) {
+	if !s.endStage(ctx, id, stage, queue, nil) {
+		return
+	}
+	s.recordEvent(id, stageStatus[stage], queue, fmt.Errorf("released on shutdown: %w", cause))
+	log.Printf("[PIPELINE][%d] Released to %s on shutdown", id, queue)
+}
+
+// DeadLetters lists uploads that failed for good.
+func (s *Service) DeadLetters(limit int) ([]DeadLetter, error) {
+	return s.repo.DeadLetters(limit)
+}
+
+// JobErrors returns an upload's failed attempts.
+func (s *Service) JobErrors(id int) ([]JobError, error) {
+	return s.repo.JobErrors(id)
+}
+
+// Requeue sends failed uploads back through the pipeline and returns the
+// ids it requeued.
+func (s *Service) Requeue(ids []int) ([]int, error) {
+	moved, err := s.repo.Requeue(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	requeued := make([]int, 0, len(moved))
+	for _, t := range moved {
+		s.recordEvent(t.ID, t.From, t.To, nil)
+		requeued = append(requeued, t.ID)
+	}
+	if len(requeued) > 0 {
+		s.ocrStage.wake()
+		s.llmStage.wake()
+	}
+	return requeued, nil
+}
+
+// recordEvent appends a status change to the upload's history. History
+// is best effort; it never fails the job.
+func (s *Service) recordEvent(id int, from, to string, cause error) {
+	ev := menu.UploadEvent{MenuID: id, FromStatus: from, ToStatus: to, WorkerID: s.workerID}
+	if cause != nil {
+		ev.Error = cause.Error()
+	}
+	if err := s.menuService.RecordEvent(context.Background(), ev); err != nil {
+		log.Printf("[PIPELINE][%d] Could not record %s event: %v", id, to, err)
+	}
+}
+
 // ─────────────────────────────────────────────
 // HELPERS
 // ─────────────────────────────────────────────
@@ -223,54 +840,164 @@ func toParsedMenu(ocr *llm.ParsedOCRResult) *menu.ParsedMenu {
 
 	for _, it := range ocr.Items {
 		items = append(items, menu.Item{
-			Name:     it.Name,
-			Category: it.Category,
-			Price:    it.Price,
+			Name:       it.Name,
+			SearchName: lang.Transliterate(it.Name),
+			Category:   it.Category,
+			Price:      it.Price,
 		})
 	}
 
-	return &menu.ParsedMenu{
-		Items:      items,
-		TaxPercent: ocr.TaxPercent,
+	var quarantined []menu.QuarantinedItem
+	for _, q := range ocr.Quarantined {
+		quarantined = append(quarantined, menu.QuarantinedItem{Raw: q.Raw, Problems: q.Problems})
+	}
+
+	parsed := &menu.ParsedMenu{
+		Items:       items,
+		TaxPercent:  ocr.TaxPercent,
+		Quarantined: quarantined,
+	}
+	if p := ocr.Provenance; p != nil {
+		parsed.Provenance = &menu.Provenance{
+			PromptVersion: p.PromptVersion,
+			Provider:      p.Provider,
+			Model:         p.Model,
+			Calls:         p.Calls,
+			InputTokens:   p.InputTokens,
+			OutputTokens:  p.OutputTokens,
+			LatencyMS:     p.LatencyMS,
+		}
 	}
+	return parsed
 }
 
-func runTesseract(path string) (string, error) {
-	cmd := exec.Command("tesseract", path, "stdout", "-l", "eng")
-	out, err := cmd.CombinedOutput()
-	if err != nil {
-		return "", fmt.Errorf("tesseract failed: %s", string(out))
+// processPDFtoOCR reads each page from the PDF's text layer when it has
+// a usable one and rasterises and OCRs only the rest. The text keeps the
+// page break markers the PDF cleaner looks for. Without a hint the script
+// is detected on the first OCRed page, or from the text layer when no page
+// needs OCR.
+func (s *Service) processPDFtoOCR(ctx context.Context, id int, pdfPath string, hint string) (string, *Document, string, error) {
+	s.ocrSlots.acquire()
+	pageTexts, err := extractPDFText(ctx, pdfPath)
+	s.ocrSlots.release()
+	if ctx.Err() != nil {
+		return "", nil, "", ctx.Err()
+	}
+	if err != nil || len(pageTexts) == 0 {
+		log.Printf("[OCR][%d] No PDF text layer, OCRing every page: %v", id, err)
+		return s.ocrPDF(ctx, id, pdfPath, hint)
 	}
-	return string(out), nil
+
+	doc := &Document{}
+	var b strings.Builder
+	languages := hint
+	var textPages, ocrPages int
+	var failed []error
+
+	for i, pageText := range pageTexts {
+		if ctx.Err() != nil {
+			return "", nil, "", ctx.Err()
+		}
+		number := i + 1
+
+		var page Page
+		if hasTextLayer(pageText, s.minPDFTextChars) {
+			page = textLayerPage(number, pageText)
+			textPages++
+		} else {
+			prefix := filepath.Join(os.TempDir(), fmt.Sprintf("menu_%d_page_%d", id, number))
+			s.ocrSlots.acquire()
+			img, err := rasterisePDFPage(ctx, pdfPath, number, prefix)
+			s.ocrSlots.release()
+			if err != nil {
+				failed = append(failed, doc.addFailedPage(number, err))
+				continue
+			}
+			if languages == "" {
+				languages = s.languagesFor(ctx, id, img, hint)
+			}
+			recognised, err := s.recognize(ctx, img, languages)
+			_ = os.Remove(img)
+			if err != nil {
+				failed = append(failed, doc.addFailedPage(number, err))
+				continue
+			}
+			page = ocrPage(number, recognised)
+			ocrPages++
+		}
+
+		b.WriteString(page.Text())
+		b.WriteString("\n" + pageBreak + "\n")
+		doc.Pages = append(doc.Pages, page)
+	}
+
+	if ctx.Err() != nil {
+		return "", nil, "", ctx.Err()
+	}
+	if len(failed) > 0 {
+		return "", doc, languages, errors.Join(failed...)
+	}
+	if strings.TrimSpace(strings.ReplaceAll(b.String(), pageBreak, "")) == "" {
+		return "", nil, "", fmt.Errorf("no text extracted from PDF")
+	}
+	if languages == "" {
+		languages = lang.ForText(b.String())
+	}
+
+	log.Printf("[OCR][%d] PDF pages: %d from text layer, %d OCRed", id, textPages, ocrPages)
+	return b.String(), doc, languages, nil
 }
 
-func (s *Service) processPDFtoOCR(id int, pdfPath string) (string, error) {
+// ocrPDF rasterises every page and OCRs it, for PDFs whose text layer
+// cannot be read at all.
+func (s *Service) ocrPDF(ctx context.Context, id int, pdfPath string, hint string) (string, *Document, string, error) {
 	prefix := filepath.Join(os.TempDir(), fmt.Sprintf("menu_%d_page", id))
 
-	cmd := exec.Command("pdftoppm", "-png", pdfPath, prefix)
-	if out, err := cmd.CombinedOutput(); err != nil {
-		return "", fmt.Errorf("pdftoppm failed: %s", string(out))
+	s.ocrSlots.acquire()
+	out, err := exec.CommandContext(ctx, "pdftoppm", "-png", pdfPath, prefix).CombinedOutput()
+	s.ocrSlots.release()
+	if err != nil {
+		return "", nil, "", fmt.Errorf("pdftoppm failed: %s", string(out))
 	}
 
 	images, err := filepath.Glob(prefix + "*.png")
 	if err != nil || len(images) == 0 {
-		return "", fmt.Errorf("no images generated from PDF")
+		return "", nil, "", fmt.Errorf("no images generated from PDF")
 	}
 	sort.Strings(images)
 
+	languages := s.languagesFor(ctx, id, images[0], hint)
+
+	doc := &Document{}
 	var b strings.Builder
-	for _, img := range images {
-		txt, err := runTesseract(img)
+	var failed []error
+	for i, img := range images {
+		if ctx.Err() != nil {
+			_ = os.Remove(img)
+			continue
+		}
+		number := i + 1
+		recognised, err := s.recognize(ctx, img, languages)
 		if err == nil {
-			b.WriteString(txt)
-			b.WriteString("\n---PAGE BREAK---\n")
+			page := ocrPage(number, recognised)
+			b.WriteString(page.Text())
+			b.WriteString("\n" + pageBreak + "\n")
+			doc.Pages = append(doc.Pages, page)
+		} else {
+			failed = append(failed, doc.addFailedPage(number, err))
 		}
 		_ = os.Remove(img)
 	}
 
+	if ctx.Err() != nil {
+		return "", nil, "", ctx.Err()
+	}
+	if len(failed) > 0 {
+		return "", doc, languages, errors.Join(failed...)
+	}
 	if b.Len() == 0 {
-		return "", fmt.Errorf("no text extracted from PDF")
+		return "", nil, "", fmt.Errorf("no text extracted from PDF")
 	}
 
-	return b.String(), nil
+	return b.String(), doc, languages, nil
 }
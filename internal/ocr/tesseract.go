@@ -1,15 +1,283 @@
 package ocr
 
 import (
+	"bufio"
+	"bytes"
+	"context"
 	"fmt"
+	"html"
+	"io"
+	"maps"
+	"os"
 	"os/exec"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
 )
 
+const (
+	FormatTSV  = "tsv"
+	FormatHOCR = "hocr"
+)
+
+// TesseractEngine shells out to the tesseract CLI and parses its TSV or
+// hOCR output into a Document.
+type TesseractEngine struct {
+	Binary   string
+	Language string
+	Format   string
+}
+
+func NewTesseractEngine() *TesseractEngine {
+	return &TesseractEngine{
+		Binary:   "tesseract",
+		Language: "eng",
+		Format:   FormatTSV,
+	}
+}
+
+// NewTesseractEngineFromEnv reads OCR_TESSERACT_FORMAT ("tsv" or "hocr").
+func NewTesseractEngineFromEnv() (*TesseractEngine, error) {
+	e := NewTesseractEngine()
+	if f := os.Getenv("OCR_TESSERACT_FORMAT"); f != "" {
+		if f != FormatTSV && f != FormatHOCR {
+			return nil, fmt.Errorf("OCR_TESSERACT_FORMAT must be %q or %q", FormatTSV, FormatHOCR)
+		}
+		e.Format = f
+	}
+	return e, nil
+}
+
+func (e *TesseractEngine) Recognize(ctx context.Context, imagePath string, languages string) (*Document, error) {
+	if languages == "" {
+		languages = e.Language
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, e.Binary, imagePath, "stdout", "-l", languages, e.Format)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("tesseract failed: %w - output: %s", err, stderr.String())
+	}
+
+	if e.Format == FormatHOCR {
+		return parseHOCR(stdout.String())
+	}
+	return parseTSV(&stdout)
+}
+
+// ExtractText returns only the recognised text of an image.
 func ExtractText(filePath string) (string, error) {
-	cmd := exec.Command("tesseract", filePath, "stdout", "-l", "eng")
-	out, err := cmd.CombinedOutput()
+	doc, err := NewTesseractEngine().Recognize(context.Background(), filePath, "")
 	if err != nil {
-		return "", fmt.Errorf("tesseract failed: %w - output: %s", err, string(out))
+		return "", err
+	}
+	return doc.Text(), nil
+}
+
+// DetectScript runs Tesseract's orientation and script detection (needs
+// the osd traineddata) and returns the script name, e.g. "Devanagari".
+func (e *TesseractEngine) DetectScript(ctx context.Context, imagePath string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, e.Binary, imagePath, "stdout", "--psm", "0")
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("tesseract osd failed: %w - output: %s", err, stderr.String())
+	}
+	return parseOSDScript(stdout.String())
+}
+
+// parseOSDScript reads the "Script: Devanagari" line of --psm 0 output.
+func parseOSDScript(out string) (string, error) {
+	for _, line := range strings.Split(out, "\n") {
+		if script, ok := strings.CutPrefix(strings.TrimSpace(line), "Script:"); ok {
+			if script = strings.TrimSpace(script); script != "" {
+				return script, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("tesseract osd reported no script")
+}
+
+// --------------------------------------------------
+// TSV
+// --------------------------------------------------
+
+// Tesseract TSV columns: level page_num block_num par_num line_num
+// word_num left top width height conf text. Only level 5 rows are words.
+const tsvWordLevel = 5
+
+type lineKey struct{ page, block, par, line int }
+
+func parseTSV(r io.Reader) (*Document, error) {
+	doc := &Document{}
+	pages := map[int]*Page{}
+	lines := map[lineKey]*Line{}
+	var order []lineKey
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	header := true
+	for scanner.Scan() {
+		if header {
+			header = false
+			continue
+		}
+
+		cols := strings.SplitN(scanner.Text(), "\t", 12)
+		if len(cols) < 12 {
+			continue
+		}
+
+		nums := make([]int, 10)
+		for i := 0; i < 10; i++ {
+			n, err := strconv.Atoi(cols[i])
+			if err != nil {
+				return nil, fmt.Errorf("invalid tesseract tsv row %q", scanner.Text())
+			}
+			nums[i] = n
+		}
+
+		text := strings.TrimSpace(cols[11])
+		conf, _ := strconv.ParseFloat(cols[10], 64)
+		if nums[0] != tsvWordLevel || text == "" || conf < 0 {
+			continue
+		}
+
+		key := lineKey{page: nums[1], block: nums[2], par: nums[3], line: nums[4]}
+		l, ok := lines[key]
+		if !ok {
+			l = &Line{}
+			lines[key] = l
+			order = append(order, key)
+		}
+
+		box := BoundingBox{Left: nums[6], Top: nums[7], Width: nums[8], Height: nums[9]}
+		l.Words = append(l.Words, Word{Text: text, Box: box, Confidence: conf})
+		l.Box = l.Box.union(box)
+
+		if _, ok := pages[key.page]; !ok {
+			pages[key.page] = &Page{Number: key.page}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, key := range order {
+		l := lines[key]
+		finishLine(l)
+		pages[key.page].Lines = append(pages[key.page].Lines, *l)
+	}
+	// Pages with no words have no rows, so numbers can skip.
+	for _, n := range slices.Sorted(maps.Keys(pages)) {
+		doc.Pages = append(doc.Pages, *pages[n])
+	}
+
+	return doc, nil
+}
+
+// finishLine fills the line text and mean confidence from its words.
+func finishLine(l *Line) {
+	texts := make([]string, len(l.Words))
+	var sum float64
+	for i, w := range l.Words {
+		texts[i] = w.Text
+		sum += w.Confidence
+	}
+	l.Text = strings.Join(texts, " ")
+	if len(l.Words) > 0 {
+		l.Confidence = sum / float64(len(l.Words))
+	}
+}
+
+// --------------------------------------------------
+// hOCR
+// --------------------------------------------------
+
+var (
+	hocrElement = regexp.MustCompile(`<(?:div|span)[^>]*class=['"](ocr_page|ocr_line|ocr_caption|ocr_header|ocr_textfloat|ocrx_word)['"][^>]*title=['"]([^'"]*)['"][^>]*>`)
+	hocrBBox    = regexp.MustCompile(`bbox (\d+) (\d+) (\d+) (\d+)`)
+	hocrConf    = regexp.MustCompile(`x_wconf (\d+(?:\.\d+)?)`)
+	hocrTag     = regexp.MustCompile(`<[^>]+>`)
+)
+
+func parseHOCR(s string) (*Document, error) {
+	doc := &Document{}
+	var page *Page
+	var line *Line
+
+	flushLine := func() {
+		if line != nil && page != nil && len(line.Words) > 0 {
+			finishLine(line)
+			page.Lines = append(page.Lines, *line)
+		}
+		line = nil
+	}
+	flushPage := func() {
+		flushLine()
+		if page != nil {
+			doc.Pages = append(doc.Pages, *page)
+		}
+		page = nil
+	}
+
+	matches := hocrElement.FindAllStringSubmatchIndex(s, -1)
+	for i, m := range matches {
+		class := s[m[2]:m[3]]
+		title := s[m[4]:m[5]]
+
+		switch class {
+		case "ocr_page":
+			flushPage()
+			page = &Page{Number: len(doc.Pages) + 1}
+		case "ocrx_word":
+			if line == nil {
+				line = &Line{}
+			}
+			// The word text runs up to the next recognised element.
+			end := len(s)
+			if i+1 < len(matches) {
+				end = matches[i+1][0]
+			}
+			text := strings.TrimSpace(html.UnescapeString(hocrTag.ReplaceAllString(s[m[1]:end], "")))
+			if text == "" {
+				continue
+			}
+			conf := 0.0
+			if c := hocrConf.FindStringSubmatch(title); c != nil {
+				conf, _ = strconv.ParseFloat(c[1], 64)
+			}
+			box := parseHOCRBox(title)
+			line.Words = append(line.Words, Word{Text: text, Box: box, Confidence: conf})
+			line.Box = line.Box.union(box)
+		default:
+			flushLine()
+			line = &Line{}
+		}
+	}
+	flushPage()
+
+	if len(matches) > 0 && len(doc.Pages) == 0 {
+		return nil, fmt.Errorf("hocr output has no ocr_page")
+	}
+	return doc, nil
+}
+
+func parseHOCRBox(title string) BoundingBox {
+	m := hocrBBox.FindStringSubmatch(title)
+	if m == nil {
+		return BoundingBox{}
 	}
-	return string(out), nil
+	x0, _ := strconv.Atoi(m[1])
+	y0, _ := strconv.Atoi(m[2])
+	x1, _ := strconv.Atoi(m[3])
+	y1, _ := strconv.Atoi(m[4])
+	return BoundingBox{Left: x0, Top: y0, Width: x1 - x0, Height: y1 - y0}
 }
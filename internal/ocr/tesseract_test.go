@@ -0,0 +1,142 @@
+package ocr
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+const sampleTSV = "level\tpage_num\tblock_num\tpar_num\tline_num\tword_num\tleft\ttop\twidth\theight\tconf\ttext\n" +
+	"1\t1\t0\t0\t0\t0\t0\t0\t800\t600\t-1\t\n" +
+	"4\t1\t1\t1\t1\t0\t10\t10\t300\t20\t-1\t\n" +
+	"5\t1\t1\t1\t1\t1\t10\t10\t120\t20\t96.5\tPaneer\n" +
+	"5\t1\t1\t1\t1\t2\t140\t12\t80\t18\t93.5\tTikka\n" +
+	"5\t1\t1\t1\t1\t3\t240\t10\t70\t20\t91\t₹280\n" +
+	"4\t1\t1\t1\t2\t0\t10\t40\t300\t20\t-1\t\n" +
+	"5\t1\t1\t1\t2\t1\t10\t40\t90\t20\t31\tDa1\n" +
+	"5\t1\t1\t1\t2\t2\t110\t40\t60\t22\t29\tMakh@ni\n" +
+	"5\t1\t1\t1\t2\t3\t180\t40\t10\t20\t95\t \n"
+
+func TestParseTSV(t *testing.T) {
+	doc, err := parseTSV(strings.NewReader(sampleTSV))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(doc.Pages) != 1 || len(doc.Pages[0].Lines) != 2 {
+		t.Fatalf("expected 1 page with 2 lines, got %+v", doc.Pages)
+	}
+
+	first := doc.Pages[0].Lines[0]
+	if first.Text != "Paneer Tikka ₹280" {
+		t.Fatalf("unexpected line text %q", first.Text)
+	}
+	if first.Box != (BoundingBox{Left: 10, Top: 10, Width: 300, Height: 20}) {
+		t.Fatalf("unexpected line box %+v", first.Box)
+	}
+	if first.Confidence < 93.6 || first.Confidence > 93.7 {
+		t.Fatalf("unexpected line confidence %v", first.Confidence)
+	}
+
+	if doc.Text() != "Paneer Tikka ₹280\nDa1 Makh@ni" {
+		t.Fatalf("unexpected text %q", doc.Text())
+	}
+
+	flagged := doc.LowConfidenceRegions(60)
+	if len(flagged) != 1 || flagged[0].Text != "Da1 Makh@ni" || flagged[0].Page != 1 {
+		t.Fatalf("expected the second line to be flagged, got %+v", flagged)
+	}
+	if flagged[0].Width != 160 || flagged[0].Height != 22 {
+		t.Fatalf("unexpected flagged box %+v", flagged[0])
+	}
+}
+
+func TestParseTSVSkippedPages(t *testing.T) {
+	tsv := "level\tpage_num\tblock_num\tpar_num\tline_num\tword_num\tleft\ttop\twidth\theight\tconf\ttext\n" +
+		"5\t3\t1\t1\t1\t1\t10\t10\t120\t20\t95\tLassi\n" +
+		"5\t1\t1\t1\t1\t1\t10\t10\t120\t20\t95\tChai\n"
+
+	doc, err := parseTSV(strings.NewReader(tsv))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(doc.Pages) != 2 || doc.Pages[0].Number != 1 || doc.Pages[1].Number != 3 {
+		t.Fatalf("expected pages 1 and 3 in order, got %+v", doc.Pages)
+	}
+}
+
+const sampleHOCR = `<?xml version="1.0" encoding="UTF-8"?>
+<html><body>
+  <div class='ocr_page' id='page_1' title='image "menu.png"; bbox 0 0 800 600; ppageno 0'>
+   <div class='ocr_carea' id='block_1_1' title="bbox 10 10 310 62">
+    <p class='ocr_par' id='par_1_1' lang='eng' title="bbox 10 10 310 62">
+     <span class='ocr_line' id='line_1_1' title="bbox 10 10 310 30; baseline 0 -4; x_size 20">
+      <span class='ocrx_word' id='word_1_1' title='bbox 10 10 130 30; x_wconf 96'>Masala</span>
+      <span class='ocrx_word' id='word_1_2' title='bbox 140 10 220 30; x_wconf 94'>Dosa</span>
+      <span class='ocrx_word' id='word_1_3' title='bbox 240 10 310 30; x_wconf 90'>&#8377;120</span>
+     </span>
+     <span class='ocr_line' id='line_1_2' title="bbox 10 40 200 62; baseline 0 -4; x_size 20">
+      <span class='ocrx_word' id='word_1_4' title='bbox 10 40 100 62; x_wconf 22'><strong>V4da</strong></span>
+     </span>
+    </p>
+   </div>
+  </div>
+</body></html>`
+
+func TestParseHOCR(t *testing.T) {
+	doc, err := parseHOCR(sampleHOCR)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(doc.Pages) != 1 || len(doc.Pages[0].Lines) != 2 {
+		t.Fatalf("expected 1 page with 2 lines, got %+v", doc.Pages)
+	}
+	if doc.Text() != "Masala Dosa ₹120\nV4da" {
+		t.Fatalf("unexpected text %q", doc.Text())
+	}
+
+	word := doc.Pages[0].Lines[0].Words[1]
+	if word.Box != (BoundingBox{Left: 140, Top: 10, Width: 80, Height: 20}) || word.Confidence != 94 {
+		t.Fatalf("unexpected word %+v", word)
+	}
+
+	flagged := doc.LowConfidenceRegions(60)
+	if len(flagged) != 1 || flagged[0].Text != "V4da" {
+		t.Fatalf("expected the second line to be flagged, got %+v", flagged)
+	}
+}
+
+func TestOCRPageKeepsPDFPageNumber(t *testing.T) {
+	recognised, _ := parseTSV(strings.NewReader(sampleTSV))
+	doc := &Document{}
+	if err := doc.addFailedPage(1, errors.New("tesseract crashed")); err == nil {
+		t.Fatal("expected the failure to be returned")
+	}
+	doc.Pages = append(doc.Pages, ocrPage(3, recognised))
+
+	page := doc.Pages[1]
+	if page.Number != 3 || page.Method != PageMethodOCR || page.Text() != recognised.Text() {
+		t.Fatalf("expected PDF page 3 read by OCR, got %+v", page)
+	}
+	if got := doc.LowConfidenceRegions(60); len(got) != 1 || got[0].Page != 3 {
+		t.Fatalf("expected the flagged line on page 3, got %+v", got)
+	}
+}
+
+func TestParseOSDScript(t *testing.T) {
+	out := "Page number: 0\nOrientation in degrees: 0\nRotate: 0\n" +
+		"Orientation confidence: 11.24\nScript: Devanagari\nScript confidence: 3.07\n"
+
+	script, err := parseOSDScript(out)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if script != "Devanagari" {
+		t.Fatalf("unexpected script %q", script)
+	}
+
+	if _, err := parseOSDScript("Too few characters. Skipping this page\n"); err == nil {
+		t.Fatal("expected error when no script is reported")
+	}
+}
@@ -0,0 +1,315 @@
+package ocr
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// PoolConfig sizes the OCR and LLM worker pools.
+type PoolConfig struct {
+	OCRWorkers int
+	LLMWorkers int
+
+	// BatchSize caps how many uploads one claim takes.
+	BatchSize int
+
+	// MaxOCRProcesses caps concurrent tesseract and pdftoppm processes
+	// across all OCR workers.
+	MaxOCRProcesses int
+
+	// LLMPerMinute caps LLM calls across all LLM workers; 0 is unlimited.
+	LLMPerMinute int
+
+	// IdlePoll is how long a stage waits before claiming again after
+	// finding nothing to do. Workers are normally woken by NOTIFY, so this
+	// is only a fallback.
+	IdlePoll time.Duration
+
+	// Lease is how long a claimed job stays with its worker without a
+	// heartbeat. Workers renew at a third of it while they run, so only
+	// a crashed or wedged worker lets it lapse.
+	Lease time.Duration
+
+	// MaxAttempts is how many lapsed leases a job survives before the
+	// reaper fails it instead of requeueing it.
+	MaxAttempts int
+
+	// MaxRetries is how many transient failures an upload is retried
+	// after, waiting RetryBase doubled per retry up to RetryMax.
+	MaxRetries int
+	RetryBase  time.Duration
+	RetryMax   time.Duration
+}
+
+// PoolConfigFromEnv reads OCR_WORKERS (default 2), LLM_WORKERS (2),
+// PIPELINE_BATCH_SIZE (10), OCR_MAX_PROCESSES (number of OCR workers),
+// LLM_RATE_PER_MINUTE (60), PIPELINE_IDLE_POLL (30s), PIPELINE_LEASE (2m),
+// PIPELINE_MAX_ATTEMPTS (3), PIPELINE_MAX_RETRIES (5), PIPELINE_RETRY_BASE
+// (30s) and PIPELINE_RETRY_MAX (30m).
+func PoolConfigFromEnv() PoolConfig {
+	cfg := PoolConfig{
+		OCRWorkers:   envInt("OCR_WORKERS", 2),
+		LLMWorkers:   envInt("LLM_WORKERS", 2),
+		BatchSize:    envInt("PIPELINE_BATCH_SIZE", 10),
+		LLMPerMinute: envInt("LLM_RATE_PER_MINUTE", 60),
+		IdlePoll:     envDuration("PIPELINE_IDLE_POLL", 30*time.Second),
+		Lease:        envDuration("PIPELINE_LEASE", 2*time.Minute),
+		MaxAttempts:  max(envInt("PIPELINE_MAX_ATTEMPTS", 3), 1),
+		MaxRetries:   envInt("PIPELINE_MAX_RETRIES", 5),
+		RetryBase:    envDuration("PIPELINE_RETRY_BASE", 30*time.Second),
+		RetryMax:     envDuration("PIPELINE_RETRY_MAX", 30*time.Minute),
+	}
+	cfg.MaxOCRProcesses = envInt("OCR_MAX_PROCESSES", cfg.OCRWorkers)
+	return cfg
+}
+
+func envInt(key string, def int) int {
+	if v, err := strconv.Atoi(os.Getenv(key)); err == nil && v >= 0 {
+		return v
+	}
+	return def
+}
+
+func envDuration(key string, def time.Duration) time.Duration {
+	if d, err := time.ParseDuration(os.Getenv(key)); err == nil && d > 0 {
+		return d
+	}
+	return def
+}
+
+// PipelineStats is a snapshot of the pipeline for the admin dashboard.
+type PipelineStats struct {
+	// QueueDepth counts uploads per status, e.g. MENU_UPLOADED.
+	QueueDepth map[string]int `json:"queue_depth"`
+
+	OCRWorkers      int   `json:"ocr_workers"`
+	OCRInFlight     int64 `json:"ocr_in_flight"`
+	OCRProcessed    int64 `json:"ocr_processed"`
+	OCRProcesses    int   `json:"ocr_processes"`
+	MaxOCRProcesses int   `json:"max_ocr_processes"`
+
+	LLMWorkers   int   `json:"llm_workers"`
+	LLMInFlight  int64 `json:"llm_in_flight"`
+	LLMProcessed int64 `json:"llm_processed"`
+	LLMPerMinute int   `json:"llm_rate_per_minute"`
+}
+
+// ─────────────────────────────────────────────
+// STAGE POOL
+// ─────────────────────────────────────────────
+
+// stage runs one pipeline step with a fixed number of workers. A single
+// dispatcher claims work only for idle workers, so claimed rows never sit
+// in memory behind a busy worker.
+type stage[J any] struct {
+	workers  int
+	batch    int
+	idlePoll time.Duration
+
+	claim   func(limit int) ([]J, error)
+	process func(ctx context.Context, job J)
+
+	// wakeup cuts an idle wait short when new work is announced.
+	wakeup chan struct{}
+
+	inFlight  atomic.Int64
+	processed atomic.Int64
+}
+
+func newStage[J any](workers, batch int, idlePoll time.Duration, claim func(int) ([]J, error), process func(context.Context, J)) *stage[J] {
+	return &stage[J]{
+		workers:  workers,
+		batch:    batch,
+		idlePoll: idlePoll,
+		claim:    claim,
+		process:  process,
+		wakeup:   make(chan struct{}, 1),
+	}
+}
+
+// wake makes an idle stage claim now. It never blocks.
+func (st *stage[J]) wake() {
+	select {
+	case st.wakeup <- struct{}{}:
+	default:
+	}
+}
+
+// run claims and processes jobs until ctx is cancelled, then stops
+// claiming and returns once the jobs in flight have finished. Jobs run
+// with jobCtx, so cancelling ctx alone lets them complete.
+func (st *stage[J]) run(ctx, jobCtx context.Context, onError func(error)) {
+	if st.workers <= 0 {
+		return
+	}
+
+	jobs := make(chan J)
+	idle := make(chan struct{}, st.workers)
+	var running sync.WaitGroup
+
+	for i := 0; i < st.workers; i++ {
+		idle <- struct{}{}
+		running.Add(1)
+		go func() {
+			defer running.Done()
+			for job := range jobs {
+				st.inFlight.Add(1)
+				st.process(jobCtx, job)
+				st.inFlight.Add(-1)
+				st.processed.Add(1)
+				idle <- struct{}{}
+			}
+		}()
+	}
+
+	defer func() {
+		close(jobs)
+		running.Wait()
+	}()
+
+	for {
+		// Wait for at least one idle worker, then take any others.
+		select {
+		case <-idle:
+		case <-ctx.Done():
+			return
+		}
+		free := 1
+	drain:
+		for free < st.workers {
+			select {
+			case <-idle:
+				free++
+			default:
+				break drain
+			}
+		}
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		claimed, err := st.claim(min(free, st.batch))
+		if err != nil {
+			onError(err)
+		}
+
+		for _, job := range claimed {
+			jobs <- job
+			free--
+		}
+		for ; free > 0; free-- {
+			idle <- struct{}{}
+		}
+
+		if len(claimed) == 0 {
+			select {
+			case <-st.wakeup:
+			case <-time.After(st.idlePoll):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// ─────────────────────────────────────────────
+// LIMITS
+// ─────────────────────────────────────────────
+
+// processSlots caps concurrent external processes.
+type processSlots struct {
+	slots chan struct{}
+}
+
+func newProcessSlots(n int) *processSlots {
+	return &processSlots{slots: make(chan struct{}, max(n, 1))}
+}
+
+func (p *processSlots) acquire() { p.slots <- struct{}{} }
+func (p *processSlots) release() { <-p.slots }
+func (p *processSlots) inUse() int {
+	return len(p.slots)
+}
+
+// rateLimiter spaces calls evenly to at most perMinute.
+type rateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+func newRateLimiter(perMinute int) *rateLimiter {
+	if perMinute <= 0 {
+		return &rateLimiter{}
+	}
+	return &rateLimiter{interval: time.Minute / time.Duration(perMinute)}
+}
+
+// wait blocks until the caller may make its call, or ctx is done.
+func (r *rateLimiter) wait(ctx context.Context) error {
+	if r.interval == 0 {
+		return nil
+	}
+
+	r.mu.Lock()
+	now := time.Now()
+	if r.next.Before(now) {
+		r.next = now
+	}
+	delay := r.next.Sub(now)
+	r.next = r.next.Add(r.interval)
+	r.mu.Unlock()
+
+	select {
+	case <-time.After(delay):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// ─────────────────────────────────────────────
+// LEASES
+// ─────────────────────────────────────────────
+
+// heartbeat calls renew every interval until the returned stop is called.
+// It gives up after renew reports the lease lost and calls onLost: the
+// reaper has requeued or failed the job, so the current run must stop
+// before it writes over whoever holds it now.
+func heartbeat(interval time.Duration, renew func() (bool, error), onLost func(), onError func(error)) (stop func()) {
+	done := make(chan struct{})
+	finished := make(chan struct{})
+
+	go func() {
+		defer close(finished)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				held, err := renew()
+				if err != nil {
+					onError(err)
+					continue
+				}
+				if !held {
+					onLost()
+					return
+				}
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-finished
+	}
+}
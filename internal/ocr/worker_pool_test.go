@@ -0,0 +1,223 @@
+package ocr
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestStageProcessesEveryJobWithinWorkerLimit(t *testing.T) {
+	var mu sync.Mutex
+	queue := make([]int, 50)
+	for i := range queue {
+		queue[i] = i
+	}
+
+	var running, peak atomic.Int64
+	var done sync.WaitGroup
+	done.Add(len(queue))
+	seen := make([]atomic.Bool, len(queue))
+
+	st := newStage(4, 3, time.Millisecond,
+		func(limit int) ([]int, error) {
+			if limit > 3 {
+				t.Errorf("claimed %d, more than the batch size", limit)
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			n := min(limit, len(queue))
+			claimed := queue[:n]
+			queue = queue[n:]
+			return claimed, nil
+		},
+		func(_ context.Context, job int) {
+			n := running.Add(1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			running.Add(-1)
+			if seen[job].Swap(true) {
+				t.Errorf("job %d processed twice", job)
+			}
+			done.Done()
+		},
+	)
+	go st.run(context.Background(), context.Background(), func(err error) { t.Error(err) })
+
+	finished := make(chan struct{})
+	go func() { done.Wait(); close(finished) }()
+	select {
+	case <-finished:
+	case <-time.After(5 * time.Second):
+		t.Fatal("jobs not drained")
+	}
+
+	if p := peak.Load(); p > 4 {
+		t.Fatalf("expected at most 4 concurrent jobs, saw %d", p)
+	}
+}
+
+func TestRateLimiterSpacesCalls(t *testing.T) {
+	r := newRateLimiter(6000) // one call per 10ms
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		_ = r.wait(context.Background())
+	}
+	if elapsed := time.Since(start); elapsed < 30*time.Millisecond {
+		t.Fatalf("expected calls spaced 10ms apart, took %v", elapsed)
+	}
+
+	unlimited := newRateLimiter(0)
+	start = time.Now()
+	for i := 0; i < 100; i++ {
+		_ = unlimited.wait(context.Background())
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Millisecond {
+		t.Fatalf("expected no limit, took %v", elapsed)
+	}
+}
+
+func TestStageWakesOnNotify(t *testing.T) {
+	var pending atomic.Int64
+	processed := make(chan int, 1)
+
+	st := newStage(1, 1, time.Hour,
+		func(limit int) ([]int, error) {
+			if pending.Swap(0) == 1 {
+				return []int{1}, nil
+			}
+			return nil, nil
+		},
+		func(_ context.Context, job int) { processed <- job },
+	)
+	go st.run(context.Background(), context.Background(), func(err error) { t.Error(err) })
+
+	// Let the stage go idle on its hour-long poll, then announce work.
+	time.Sleep(20 * time.Millisecond)
+	pending.Store(1)
+	st.wake()
+
+	select {
+	case <-processed:
+	case <-time.After(time.Second):
+		t.Fatal("stage did not wake up")
+	}
+}
+
+func TestStageDrainsJobsInFlightWhenCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var claims atomic.Int64
+	var finished atomic.Bool
+
+	st := newStage(1, 1, time.Millisecond,
+		func(limit int) ([]int, error) {
+			claims.Add(1)
+			return []int{1}, nil
+		},
+		func(jobCtx context.Context, job int) {
+			close(started)
+			<-release
+			if jobCtx.Err() != nil {
+				t.Error("job context cancelled with the stage")
+			}
+			finished.Store(true)
+		},
+	)
+
+	stopped := make(chan struct{})
+	go func() {
+		st.run(ctx, context.Background(), func(err error) { t.Error(err) })
+		close(stopped)
+	}()
+
+	<-started
+	cancel()
+
+	select {
+	case <-stopped:
+		t.Fatal("run returned with a job in flight")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(release)
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("run did not return after draining")
+	}
+
+	if !finished.Load() {
+		t.Fatal("job in flight did not finish")
+	}
+	if n := claims.Load(); n != 1 {
+		t.Fatalf("expected no claims after cancel, got %d", n)
+	}
+}
+
+func TestRateLimiterWaitStopsOnCancel(t *testing.T) {
+	r := newRateLimiter(1)
+	_ = r.wait(context.Background()) // the first call goes straight through
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := r.wait(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestHeartbeatRenewsUntilStopped(t *testing.T) {
+	var renewals atomic.Int32
+	stop := heartbeat(5*time.Millisecond,
+		func() (bool, error) {
+			renewals.Add(1)
+			return true, nil
+		},
+		func() { t.Error("lease reported lost") },
+		func(err error) { t.Errorf("unexpected error: %v", err) },
+	)
+
+	time.Sleep(40 * time.Millisecond)
+	stop()
+	got := renewals.Load()
+	if got < 2 {
+		t.Fatalf("expected repeated renewals, got %d", got)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	if renewals.Load() != got {
+		t.Fatal("renewed after stop")
+	}
+}
+
+func TestHeartbeatStopsWhenLeaseLost(t *testing.T) {
+	var renewals atomic.Int32
+	lost := make(chan struct{})
+	stop := heartbeat(5*time.Millisecond,
+		func() (bool, error) {
+			renewals.Add(1)
+			return false, nil
+		},
+		func() { close(lost) },
+		func(err error) { t.Errorf("unexpected error: %v", err) },
+	)
+	defer stop()
+
+	select {
+	case <-lost:
+	case <-time.After(time.Second):
+		t.Fatal("lost lease not reported")
+	}
+	time.Sleep(40 * time.Millisecond)
+	if got := renewals.Load(); got != 1 {
+		t.Fatalf("expected a single renewal attempt, got %d", got)
+	}
+}
@@ -1,8 +1,12 @@
 package restaurant
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
+
+	"bhojanalya/internal/core"
 
 	"github.com/gin-gonic/gin"
 )
@@ -33,6 +37,12 @@ func (h *Handler) CreateRestaurant(c *gin.Context) {
 		return
 	}
 
+	// A key bound to one restaurant cannot create others.
+	if _, bound := core.BoundRestaurant(c.Request.Context()); bound {
+		c.JSON(http.StatusForbidden, gin.H{"error": "unauthorized"})
+		return
+	}
+
 	userIDVal, exists := c.Get("userID")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
@@ -82,6 +92,16 @@ func (h *Handler) ListMyRestaurants(c *gin.Context) {
 		return
 	}
 
+	if bound, ok := core.BoundRestaurant(c.Request.Context()); ok {
+		scoped := []*Restaurant{}
+		for _, r := range restaurants {
+			if r.ID == strconv.Itoa(bound) {
+				scoped = append(scoped, r)
+			}
+		}
+		restaurants = scoped
+	}
+
 	c.JSON(http.StatusOK, restaurants)
 }
 
@@ -196,6 +216,45 @@ func (h *Handler) UploadImages(c *gin.Context) {
 	})
 }
 
+// --------------------------------------------------
+// PUT /restaurants/:id/menu-language
+// --------------------------------------------------
+func (h *Handler) SetMenuLanguage(c *gin.Context) {
+	var restaurantID int
+	if _, err := fmt.Sscanf(c.Param("id"), "%d", &restaurantID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid restaurant id"})
+		return
+	}
+
+	var req struct {
+		Language string `json:"language"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	languages, err := h.service.SetMenuLanguage(
+		c.Request.Context(),
+		restaurantID,
+		c.GetString("userID"),
+		req.Language,
+	)
+	switch {
+	case errors.Is(err, ErrUnauthorized):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"restaurant_id": restaurantID,
+		"menu_language": languages,
+	})
+}
+
 // --------------------------------------------------
 // GET /restaurants/:id/preview
 // --------------------------------------------------
@@ -230,6 +289,25 @@ func (h *Handler) Preview(c *gin.Context) {
 	c.JSON(http.StatusOK, data)
 }
 
+// --------------------------------------------------
+// GET /admin/restaurants/:id/preview
+// --------------------------------------------------
+func (h *Handler) AdminPreview(c *gin.Context) {
+	var restaurantID int
+	if _, err := fmt.Sscanf(c.Param("id"), "%d", &restaurantID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid restaurant id"})
+		return
+	}
+
+	data, err := h.service.GetPreviewForReview(c.Request.Context(), restaurantID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "restaurant not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, data)
+}
+
 
 // to get the restaurant approved by the admin 
 func (h *Handler) ApproveRestaurant(c *gin.Context) {
@@ -0,0 +1,252 @@
+package restaurant
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type MemberHandler struct {
+	service *MemberService
+}
+
+func NewMemberHandler(service *MemberService) *MemberHandler {
+	return &MemberHandler{service: service}
+}
+
+// --------------------------------------------------
+// GET /restaurants/:id/members
+// --------------------------------------------------
+func (h *MemberHandler) ListMembers(c *gin.Context) {
+	restaurantID, ok := restaurantIDParam(c)
+	if !ok {
+		return
+	}
+
+	members, err := h.service.ListMembers(c.Request.Context(), restaurantID, c.GetString("userID"))
+	if err != nil {
+		writeMemberError(c, err)
+		return
+	}
+
+	if members == nil {
+		members = []Member{}
+	}
+	c.JSON(http.StatusOK, members)
+}
+
+// --------------------------------------------------
+// PATCH /restaurants/:id/members/:userId
+// --------------------------------------------------
+func (h *MemberHandler) ChangeRole(c *gin.Context) {
+	restaurantID, ok := restaurantIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Role string `json:"role"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	if err := h.service.ChangeRole(
+		c.Request.Context(),
+		restaurantID,
+		c.GetString("userID"),
+		c.Param("userId"),
+		req.Role,
+	); err != nil {
+		writeMemberError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "role updated"})
+}
+
+// --------------------------------------------------
+// DELETE /restaurants/:id/members/:userId
+// --------------------------------------------------
+func (h *MemberHandler) RemoveMember(c *gin.Context) {
+	restaurantID, ok := restaurantIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.RemoveMember(
+		c.Request.Context(),
+		restaurantID,
+		c.GetString("userID"),
+		c.Param("userId"),
+	); err != nil {
+		writeMemberError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "member removed"})
+}
+
+// --------------------------------------------------
+// POST /restaurants/:id/invitations
+// --------------------------------------------------
+func (h *MemberHandler) Invite(c *gin.Context) {
+	restaurantID, ok := restaurantIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Email string `json:"email"`
+		Role  string `json:"role"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	inv, err := h.service.Invite(
+		c.Request.Context(),
+		restaurantID,
+		c.GetString("userID"),
+		req.Email,
+		req.Role,
+	)
+	if err != nil {
+		writeMemberError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, inv)
+}
+
+// --------------------------------------------------
+// GET /restaurants/:id/invitations
+// --------------------------------------------------
+func (h *MemberHandler) ListInvitations(c *gin.Context) {
+	restaurantID, ok := restaurantIDParam(c)
+	if !ok {
+		return
+	}
+
+	invitations, err := h.service.ListInvitations(c.Request.Context(), restaurantID, c.GetString("userID"))
+	if err != nil {
+		writeMemberError(c, err)
+		return
+	}
+
+	if invitations == nil {
+		invitations = []Invitation{}
+	}
+	c.JSON(http.StatusOK, invitations)
+}
+
+// --------------------------------------------------
+// DELETE /restaurants/:id/invitations/:invitationId
+// --------------------------------------------------
+func (h *MemberHandler) RevokeInvitation(c *gin.Context) {
+	restaurantID, ok := restaurantIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.RevokeInvitation(
+		c.Request.Context(),
+		restaurantID,
+		c.GetString("userID"),
+		c.Param("invitationId"),
+	); err != nil {
+		writeMemberError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "invitation revoked"})
+}
+
+// --------------------------------------------------
+// GET /invitations
+// --------------------------------------------------
+func (h *MemberHandler) MyInvitations(c *gin.Context) {
+	invitations, err := h.service.MyInvitations(c.Request.Context(), c.GetString("userEmail"))
+	if err != nil {
+		writeMemberError(c, err)
+		return
+	}
+
+	if invitations == nil {
+		invitations = []Invitation{}
+	}
+	c.JSON(http.StatusOK, invitations)
+}
+
+// --------------------------------------------------
+// POST /invitations/:id/accept
+// --------------------------------------------------
+func (h *MemberHandler) AcceptInvitation(c *gin.Context) {
+	inv, err := h.service.AcceptInvitation(
+		c.Request.Context(),
+		c.Param("id"),
+		c.GetString("userID"),
+		c.GetString("userEmail"),
+	)
+	if err != nil {
+		writeMemberError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":       "invitation accepted",
+		"restaurant_id": inv.RestaurantID,
+		"role":          inv.Role,
+	})
+}
+
+// --------------------------------------------------
+// POST /invitations/:id/decline
+// --------------------------------------------------
+func (h *MemberHandler) DeclineInvitation(c *gin.Context) {
+	if err := h.service.DeclineInvitation(
+		c.Request.Context(),
+		c.Param("id"),
+		c.GetString("userEmail"),
+	); err != nil {
+		writeMemberError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "invitation declined"})
+}
+
+// --------------------------------------------------
+
+func restaurantIDParam(c *gin.Context) (int, bool) {
+	var restaurantID int
+	if _, err := fmt.Sscanf(c.Param("id"), "%d", &restaurantID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid restaurant id"})
+		return 0, false
+	}
+	return restaurantID, true
+}
+
+func writeMemberError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, ErrUnauthorized),
+		errors.Is(err, ErrInvitationNotForYou):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrMemberNotFound),
+		errors.Is(err, ErrInvitationNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrInvitationExists),
+		errors.Is(err, ErrAlreadyMember),
+		errors.Is(err, ErrLastOwner):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrInvalidMemberRole),
+		errors.Is(err, ErrInvalidEmail):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to process request"})
+	}
+}
@@ -0,0 +1,27 @@
+package restaurant
+
+import "context"
+
+type MemberRepository interface {
+	// GetRole returns "" when the user is not a member.
+	GetRole(ctx context.Context, restaurantID int, userID string) (MemberRole, error)
+	ListMembers(ctx context.Context, restaurantID int) ([]Member, error)
+
+	// UpdateRole and RemoveMember return ErrLastOwner instead of leaving
+	// the restaurant without an OWNER.
+	UpdateRole(ctx context.Context, restaurantID int, userID string, role MemberRole) error
+	RemoveMember(ctx context.Context, restaurantID int, userID string) error
+
+	// CreateInvitation returns ErrInvitationExists if one is already
+	// pending. Pending invitations to the same address that have expired
+	// are marked EXPIRED first, so they do not block a new one.
+	CreateInvitation(ctx context.Context, inv *Invitation) error
+	GetInvitation(ctx context.Context, id string) (*Invitation, error)
+	ListInvitations(ctx context.Context, restaurantID int) ([]Invitation, error)
+	ListInvitationsForEmail(ctx context.Context, email string) ([]Invitation, error)
+
+	// AcceptInvitation marks the invitation accepted and adds the member
+	// in one transaction.
+	AcceptInvitation(ctx context.Context, id string, userID string) error
+	SetInvitationStatus(ctx context.Context, id string, status string) error
+}
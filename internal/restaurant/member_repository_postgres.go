@@ -0,0 +1,356 @@
+package restaurant
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PostgresMemberRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewPostgresMemberRepository(db *pgxpool.Pool) *PostgresMemberRepository {
+	return &PostgresMemberRepository{db: db}
+}
+
+// --------------------------------------------------
+// Members
+// --------------------------------------------------
+func (r *PostgresMemberRepository) GetRole(
+	ctx context.Context,
+	restaurantID int,
+	userID string,
+) (MemberRole, error) {
+
+	var role MemberRole
+	err := r.db.QueryRow(ctx, `
+		SELECT role
+		FROM restaurant_members
+		WHERE restaurant_id = $1
+		  AND user_id = $2
+	`, restaurantID, userID).Scan(&role)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", nil
+	}
+	return role, err
+}
+
+func (r *PostgresMemberRepository) ListMembers(
+	ctx context.Context,
+	restaurantID int,
+) ([]Member, error) {
+
+	rows, err := r.db.Query(ctx, `
+		SELECT m.restaurant_id, m.user_id, u.name, u.email, m.role, m.created_at
+		FROM restaurant_members m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.restaurant_id = $1
+		ORDER BY m.created_at
+	`, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var members []Member
+	for rows.Next() {
+		var m Member
+		if err := rows.Scan(
+			&m.RestaurantID,
+			&m.UserID,
+			&m.Name,
+			&m.Email,
+			&m.Role,
+			&m.JoinedAt,
+		); err != nil {
+			return nil, err
+		}
+		members = append(members, m)
+	}
+
+	return members, rows.Err()
+}
+
+func (r *PostgresMemberRepository) UpdateRole(
+	ctx context.Context,
+	restaurantID int,
+	userID string,
+	role MemberRole,
+) error {
+	return r.changeMember(ctx, restaurantID, userID, role != MemberOwner, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, `
+			UPDATE restaurant_members
+			SET role = $3, updated_at = now()
+			WHERE restaurant_id = $1
+			  AND user_id = $2
+		`, restaurantID, userID, role)
+		return err
+	})
+}
+
+func (r *PostgresMemberRepository) RemoveMember(
+	ctx context.Context,
+	restaurantID int,
+	userID string,
+) error {
+	return r.changeMember(ctx, restaurantID, userID, true, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, `
+			DELETE FROM restaurant_members
+			WHERE restaurant_id = $1
+			  AND user_id = $2
+		`, restaurantID, userID)
+		return err
+	})
+}
+
+// changeMember locks the restaurant's member rows so two concurrent
+// changes cannot both remove the last owner.
+func (r *PostgresMemberRepository) changeMember(
+	ctx context.Context,
+	restaurantID int,
+	userID string,
+	losesOwner bool,
+	apply func(tx pgx.Tx) error,
+) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, `
+		SELECT user_id, role
+		FROM restaurant_members
+		WHERE restaurant_id = $1
+		FOR UPDATE
+	`, restaurantID)
+	if err != nil {
+		return err
+	}
+
+	owners := 0
+	var current MemberRole
+	for rows.Next() {
+		var id string
+		var role MemberRole
+		if err := rows.Scan(&id, &role); err != nil {
+			rows.Close()
+			return err
+		}
+		if role == MemberOwner {
+			owners++
+		}
+		if id == userID {
+			current = role
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if current == "" {
+		return ErrMemberNotFound
+	}
+	if losesOwner && current == MemberOwner && owners == 1 {
+		return ErrLastOwner
+	}
+
+	if err := apply(tx); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// --------------------------------------------------
+// Invitations
+// --------------------------------------------------
+func (r *PostgresMemberRepository) CreateInvitation(ctx context.Context, inv *Invitation) error {
+	if inv.ID == "" {
+		inv.ID = uuid.New().String()
+	}
+	inv.Status = InvitationPending
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	// Nothing else moves an expired invitation out of PENDING, and the
+	// unique index would otherwise block the address for good.
+	if _, err := tx.Exec(ctx, `
+		UPDATE restaurant_invitations
+		SET status = 'EXPIRED'
+		WHERE restaurant_id = $1
+		  AND lower(email) = lower($2)
+		  AND status = 'PENDING'
+		  AND expires_at <= now()
+	`, inv.RestaurantID, inv.Email); err != nil {
+		return err
+	}
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO restaurant_invitations (id, restaurant_id, email, role, invited_by, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING created_at, (SELECT name FROM restaurants WHERE id = $2)
+	`,
+		inv.ID,
+		inv.RestaurantID,
+		inv.Email,
+		inv.Role,
+		inv.InvitedBy,
+		inv.ExpiresAt,
+	).Scan(&inv.CreatedAt, &inv.RestaurantName)
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return ErrInvitationExists
+	}
+	if err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+const invitationColumns = `
+	i.id, i.restaurant_id, r.name, i.email, i.role,
+	COALESCE(i.invited_by::text, ''), i.status, i.expires_at, i.created_at
+`
+
+func scanInvitation(row pgx.Row) (*Invitation, error) {
+	var inv Invitation
+	err := row.Scan(
+		&inv.ID,
+		&inv.RestaurantID,
+		&inv.RestaurantName,
+		&inv.Email,
+		&inv.Role,
+		&inv.InvitedBy,
+		&inv.Status,
+		&inv.ExpiresAt,
+		&inv.CreatedAt,
+	)
+	return &inv, err
+}
+
+func (r *PostgresMemberRepository) GetInvitation(ctx context.Context, id string) (*Invitation, error) {
+	inv, err := scanInvitation(r.db.QueryRow(ctx, `
+		SELECT `+invitationColumns+`
+		FROM restaurant_invitations i
+		JOIN restaurants r ON r.id = i.restaurant_id
+		WHERE i.id = $1
+	`, id))
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrInvitationNotFound
+	}
+	return inv, err
+}
+
+func (r *PostgresMemberRepository) ListInvitations(ctx context.Context, restaurantID int) ([]Invitation, error) {
+	return r.queryInvitations(ctx, `
+		SELECT `+invitationColumns+`
+		FROM restaurant_invitations i
+		JOIN restaurants r ON r.id = i.restaurant_id
+		WHERE i.restaurant_id = $1
+		  AND i.status = 'PENDING'
+		  AND i.expires_at > now()
+		ORDER BY i.created_at DESC
+	`, restaurantID)
+}
+
+func (r *PostgresMemberRepository) ListInvitationsForEmail(ctx context.Context, email string) ([]Invitation, error) {
+	return r.queryInvitations(ctx, `
+		SELECT `+invitationColumns+`
+		FROM restaurant_invitations i
+		JOIN restaurants r ON r.id = i.restaurant_id
+		WHERE lower(i.email) = lower($1)
+		  AND i.status = 'PENDING'
+		  AND i.expires_at > now()
+		ORDER BY i.created_at DESC
+	`, email)
+}
+
+func (r *PostgresMemberRepository) queryInvitations(ctx context.Context, query string, arg interface{}) ([]Invitation, error) {
+	rows, err := r.db.Query(ctx, query, arg)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []Invitation
+	for rows.Next() {
+		inv, err := scanInvitation(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *inv)
+	}
+
+	return out, rows.Err()
+}
+
+func (r *PostgresMemberRepository) AcceptInvitation(ctx context.Context, id string, userID string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var restaurantID int
+	var role MemberRole
+	var invitedBy *string
+
+	err = tx.QueryRow(ctx, `
+		UPDATE restaurant_invitations
+		SET status = 'ACCEPTED', responded_at = now()
+		WHERE id = $1
+		  AND status = 'PENDING'
+		  AND expires_at > now()
+		RETURNING restaurant_id, role, invited_by
+	`, id).Scan(&restaurantID, &role, &invitedBy)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrInvitationNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	tag, err := tx.Exec(ctx, `
+		INSERT INTO restaurant_members (restaurant_id, user_id, role, invited_by)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (restaurant_id, user_id) DO NOTHING
+	`, restaurantID, userID, role, invitedBy)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrAlreadyMember
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *PostgresMemberRepository) SetInvitationStatus(ctx context.Context, id string, status string) error {
+	tag, err := r.db.Exec(ctx, `
+		UPDATE restaurant_invitations
+		SET status = $2, responded_at = now()
+		WHERE id = $1
+		  AND status = 'PENDING'
+	`, id, status)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrInvitationNotFound
+	}
+	return nil
+}
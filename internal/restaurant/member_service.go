@@ -0,0 +1,272 @@
+package restaurant
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"bhojanalya/internal/core"
+	"bhojanalya/internal/mail"
+
+	"github.com/google/uuid"
+)
+
+const invitationTTL = 7 * 24 * time.Hour
+
+// MemberService manages restaurant staff and answers permission checks
+// for the rest of the app (see core.RestaurantAccess).
+type MemberService struct {
+	repo    MemberRepository
+	mailer  mail.Mailer
+	baseURL string
+}
+
+func NewMemberService(repo MemberRepository, mailer mail.Mailer) *MemberService {
+	baseURL := os.Getenv("APP_BASE_URL")
+	if baseURL == "" {
+		baseURL = "http://localhost:3000"
+	}
+
+	return &MemberService{
+		repo:    repo,
+		mailer:  mailer,
+		baseURL: baseURL,
+	}
+}
+
+// CanAccess implements core.RestaurantAccess.
+func (s *MemberService) CanAccess(
+	ctx context.Context,
+	restaurantID int,
+	userID string,
+	action core.RestaurantAction,
+) (bool, error) {
+	if !core.RestaurantInScope(ctx, restaurantID) {
+		return false, nil
+	}
+	role, err := s.repo.GetRole(ctx, restaurantID, userID)
+	if err != nil {
+		return false, err
+	}
+	return role.Allows(action), nil
+}
+
+func (s *MemberService) require(
+	ctx context.Context,
+	restaurantID int,
+	userID string,
+	action core.RestaurantAction,
+) error {
+	ok, err := s.CanAccess(ctx, restaurantID, userID, action)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrUnauthorized
+	}
+	return nil
+}
+
+// --------------------------------------------------
+// Members
+// --------------------------------------------------
+func (s *MemberService) ListMembers(
+	ctx context.Context,
+	restaurantID int,
+	actorID string,
+) ([]Member, error) {
+	if err := s.require(ctx, restaurantID, actorID, core.ActionViewRestaurant); err != nil {
+		return nil, err
+	}
+	return s.repo.ListMembers(ctx, restaurantID)
+}
+
+func (s *MemberService) ChangeRole(
+	ctx context.Context,
+	restaurantID int,
+	actorID string,
+	userID string,
+	role string,
+) error {
+	parsed, err := ParseMemberRole(role)
+	if err != nil {
+		return err
+	}
+	if err := s.require(ctx, restaurantID, actorID, core.ActionManageMembers); err != nil {
+		return err
+	}
+	return s.repo.UpdateRole(ctx, restaurantID, userID, parsed)
+}
+
+// RemoveMember is allowed for member managers, and for anyone removing
+// themselves (leaving the restaurant).
+func (s *MemberService) RemoveMember(
+	ctx context.Context,
+	restaurantID int,
+	actorID string,
+	userID string,
+) error {
+	if actorID != userID {
+		if err := s.require(ctx, restaurantID, actorID, core.ActionManageMembers); err != nil {
+			return err
+		}
+	}
+	return s.repo.RemoveMember(ctx, restaurantID, userID)
+}
+
+// --------------------------------------------------
+// Invitations
+// --------------------------------------------------
+func (s *MemberService) Invite(
+	ctx context.Context,
+	restaurantID int,
+	actorID string,
+	email string,
+	role string,
+) (*Invitation, error) {
+	email = strings.TrimSpace(email)
+	if email == "" || !strings.Contains(email, "@") {
+		return nil, ErrInvalidEmail
+	}
+
+	parsed, err := ParseMemberRole(role)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.require(ctx, restaurantID, actorID, core.ActionManageMembers); err != nil {
+		return nil, err
+	}
+
+	inv := &Invitation{
+		RestaurantID: restaurantID,
+		Email:        email,
+		Role:         parsed,
+		InvitedBy:    actorID,
+		ExpiresAt:    time.Now().Add(invitationTTL),
+	}
+	if err := s.repo.CreateInvitation(ctx, inv); err != nil {
+		return nil, err
+	}
+
+	if err := s.mailer.Send(ctx, mail.Message{
+		To:      inv.Email,
+		Subject: fmt.Sprintf("You've been invited to %s on Bhojanalya", inv.RestaurantName),
+		Body: fmt.Sprintf(
+			"Hi,\n\nYou have been invited to join %s as %s.\n\nSign in with this email address to accept or decline:\n\n%s\n\nThe invitation expires on %s.\n",
+			inv.RestaurantName,
+			inv.Role,
+			s.baseURL+"/invitations",
+			inv.ExpiresAt.Format("2 Jan 2006"),
+		),
+	}); err != nil {
+		log.Printf("Failed to send invitation email to %s: %v", inv.Email, err)
+	}
+
+	return inv, nil
+}
+
+func (s *MemberService) ListInvitations(
+	ctx context.Context,
+	restaurantID int,
+	actorID string,
+) ([]Invitation, error) {
+	if err := s.require(ctx, restaurantID, actorID, core.ActionManageMembers); err != nil {
+		return nil, err
+	}
+	return s.repo.ListInvitations(ctx, restaurantID)
+}
+
+func (s *MemberService) RevokeInvitation(
+	ctx context.Context,
+	restaurantID int,
+	actorID string,
+	invitationID string,
+) error {
+	inv, err := s.getInvitation(ctx, invitationID)
+	if err != nil {
+		return err
+	}
+	if inv.RestaurantID != restaurantID {
+		return ErrInvitationNotFound
+	}
+	if err := s.require(ctx, restaurantID, actorID, core.ActionManageMembers); err != nil {
+		return err
+	}
+	return s.repo.SetInvitationStatus(ctx, invitationID, InvitationRevoked)
+}
+
+// MyInvitations lists pending invitations addressed to the user's email.
+func (s *MemberService) MyInvitations(ctx context.Context, email string) ([]Invitation, error) {
+	all, err := s.repo.ListInvitationsForEmail(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []Invitation
+	for _, inv := range all {
+		if core.RestaurantInScope(ctx, inv.RestaurantID) {
+			out = append(out, inv)
+		}
+	}
+	return out, nil
+}
+
+func (s *MemberService) AcceptInvitation(
+	ctx context.Context,
+	invitationID string,
+	userID string,
+	email string,
+) (*Invitation, error) {
+	inv, err := s.invitationFor(ctx, invitationID, email)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.AcceptInvitation(ctx, invitationID, userID); err != nil {
+		return nil, err
+	}
+	return inv, nil
+}
+
+func (s *MemberService) DeclineInvitation(
+	ctx context.Context,
+	invitationID string,
+	email string,
+) error {
+	if _, err := s.invitationFor(ctx, invitationID, email); err != nil {
+		return err
+	}
+	return s.repo.SetInvitationStatus(ctx, invitationID, InvitationDeclined)
+}
+
+// --------------------------------------------------
+// Helpers
+// --------------------------------------------------
+
+func (s *MemberService) getInvitation(ctx context.Context, id string) (*Invitation, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, ErrInvitationNotFound
+	}
+	return s.repo.GetInvitation(ctx, id)
+}
+
+// invitationFor loads a pending invitation and checks it was addressed
+// to email.
+func (s *MemberService) invitationFor(ctx context.Context, id string, email string) (*Invitation, error) {
+	inv, err := s.getInvitation(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if inv.Status != InvitationPending || time.Now().After(inv.ExpiresAt) {
+		return nil, ErrInvitationNotFound
+	}
+	if !core.RestaurantInScope(ctx, inv.RestaurantID) {
+		return nil, ErrUnauthorized
+	}
+	if !strings.EqualFold(inv.Email, strings.TrimSpace(email)) {
+		return nil, ErrInvitationNotForYou
+	}
+	return inv, nil
+}
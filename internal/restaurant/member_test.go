@@ -0,0 +1,348 @@
+package restaurant
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"bhojanalya/internal/core"
+	"bhojanalya/internal/mail"
+
+	"github.com/google/uuid"
+)
+
+// --------------------------------------------------
+// Mock Member Repository
+// --------------------------------------------------
+
+type MockMemberRepository struct {
+	members     map[int]map[string]MemberRole
+	invitations map[string]*Invitation
+}
+
+func NewMockMemberRepository() *MockMemberRepository {
+	return &MockMemberRepository{
+		members:     make(map[int]map[string]MemberRole),
+		invitations: make(map[string]*Invitation),
+	}
+}
+
+func (m *MockMemberRepository) add(restaurantID int, userID string, role MemberRole) {
+	if m.members[restaurantID] == nil {
+		m.members[restaurantID] = make(map[string]MemberRole)
+	}
+	m.members[restaurantID][userID] = role
+}
+
+func (m *MockMemberRepository) owners(restaurantID int) int {
+	n := 0
+	for _, role := range m.members[restaurantID] {
+		if role == MemberOwner {
+			n++
+		}
+	}
+	return n
+}
+
+func (m *MockMemberRepository) GetRole(ctx context.Context, restaurantID int, userID string) (MemberRole, error) {
+	return m.members[restaurantID][userID], nil
+}
+
+func (m *MockMemberRepository) ListMembers(ctx context.Context, restaurantID int) ([]Member, error) {
+	var out []Member
+	for userID, role := range m.members[restaurantID] {
+		out = append(out, Member{RestaurantID: restaurantID, UserID: userID, Role: role})
+	}
+	return out, nil
+}
+
+func (m *MockMemberRepository) UpdateRole(ctx context.Context, restaurantID int, userID string, role MemberRole) error {
+	current, ok := m.members[restaurantID][userID]
+	if !ok {
+		return ErrMemberNotFound
+	}
+	if current == MemberOwner && role != MemberOwner && m.owners(restaurantID) == 1 {
+		return ErrLastOwner
+	}
+	m.members[restaurantID][userID] = role
+	return nil
+}
+
+func (m *MockMemberRepository) RemoveMember(ctx context.Context, restaurantID int, userID string) error {
+	current, ok := m.members[restaurantID][userID]
+	if !ok {
+		return ErrMemberNotFound
+	}
+	if current == MemberOwner && m.owners(restaurantID) == 1 {
+		return ErrLastOwner
+	}
+	delete(m.members[restaurantID], userID)
+	return nil
+}
+
+func (m *MockMemberRepository) CreateInvitation(ctx context.Context, inv *Invitation) error {
+	for _, existing := range m.invitations {
+		if existing.RestaurantID == inv.RestaurantID &&
+			strings.EqualFold(existing.Email, inv.Email) &&
+			existing.Status == InvitationPending {
+			if !existing.ExpiresAt.After(time.Now()) {
+				existing.Status = InvitationExpired
+				continue
+			}
+			return ErrInvitationExists
+		}
+	}
+	inv.ID = uuid.NewString()
+	inv.Status = InvitationPending
+	inv.RestaurantName = "Test Restaurant"
+	inv.CreatedAt = time.Now()
+	copied := *inv
+	m.invitations[inv.ID] = &copied
+	return nil
+}
+
+func (m *MockMemberRepository) GetInvitation(ctx context.Context, id string) (*Invitation, error) {
+	inv, ok := m.invitations[id]
+	if !ok {
+		return nil, ErrInvitationNotFound
+	}
+	copied := *inv
+	return &copied, nil
+}
+
+func (m *MockMemberRepository) ListInvitations(ctx context.Context, restaurantID int) ([]Invitation, error) {
+	var out []Invitation
+	for _, inv := range m.invitations {
+		if inv.RestaurantID == restaurantID && inv.Status == InvitationPending {
+			out = append(out, *inv)
+		}
+	}
+	return out, nil
+}
+
+func (m *MockMemberRepository) ListInvitationsForEmail(ctx context.Context, email string) ([]Invitation, error) {
+	var out []Invitation
+	for _, inv := range m.invitations {
+		if strings.EqualFold(inv.Email, email) && inv.Status == InvitationPending {
+			out = append(out, *inv)
+		}
+	}
+	return out, nil
+}
+
+func (m *MockMemberRepository) AcceptInvitation(ctx context.Context, id string, userID string) error {
+	inv, ok := m.invitations[id]
+	if !ok {
+		return ErrInvitationNotFound
+	}
+	if _, exists := m.members[inv.RestaurantID][userID]; exists {
+		return ErrAlreadyMember
+	}
+	inv.Status = InvitationAccepted
+	m.add(inv.RestaurantID, userID, inv.Role)
+	return nil
+}
+
+func (m *MockMemberRepository) SetInvitationStatus(ctx context.Context, id string, status string) error {
+	inv, ok := m.invitations[id]
+	if !ok {
+		return ErrInvitationNotFound
+	}
+	inv.Status = status
+	return nil
+}
+
+// --------------------------------------------------
+// Mock Mailer
+// --------------------------------------------------
+
+type recordingMailer struct {
+	sent []mail.Message
+}
+
+func (m *recordingMailer) Send(ctx context.Context, msg mail.Message) error {
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+// --------------------------------------------------
+// TESTS
+// --------------------------------------------------
+
+func TestMemberRolePermissions(t *testing.T) {
+	cases := []struct {
+		role    MemberRole
+		action  core.RestaurantAction
+		allowed bool
+	}{
+		{MemberOwner, core.ActionManageMembers, true},
+		{MemberOwner, core.ActionManageMenu, true},
+		{MemberManager, core.ActionManageMenu, true},
+		{MemberManager, core.ActionManageDeals, true},
+		{MemberManager, core.ActionManageMembers, false},
+		{MemberStaff, core.ActionViewRestaurant, true},
+		{MemberStaff, core.ActionManageMenu, false},
+		{MemberStaff, core.ActionManageDeals, false},
+		{"", core.ActionViewRestaurant, false},
+	}
+
+	for _, tc := range cases {
+		if got := tc.role.Allows(tc.action); got != tc.allowed {
+			t.Errorf("%q %s: expected %v, got %v", tc.role, tc.action, tc.allowed, got)
+		}
+	}
+
+	if _, err := ParseMemberRole("admin"); !errors.Is(err, ErrInvalidMemberRole) {
+		t.Fatalf("expected ErrInvalidMemberRole, got %v", err)
+	}
+	if role, _ := ParseMemberRole(" manager "); role != MemberManager {
+		t.Fatalf("expected MANAGER, got %q", role)
+	}
+}
+
+func TestInviteAndAcceptMember(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMockMemberRepository()
+	repo.add(1, "owner-1", MemberOwner)
+	mailer := &recordingMailer{}
+	service := NewMemberService(repo, mailer)
+
+	inv, err := service.Invite(ctx, 1, "owner-1", "Manager@Example.com", "manager")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(mailer.sent) != 1 || mailer.sent[0].To != "Manager@Example.com" {
+		t.Fatalf("expected one invitation email, got %+v", mailer.sent)
+	}
+
+	if _, err := service.Invite(ctx, 1, "owner-1", "manager@example.com", "STAFF"); !errors.Is(err, ErrInvitationExists) {
+		t.Fatalf("expected ErrInvitationExists, got %v", err)
+	}
+
+	if _, err := service.AcceptInvitation(ctx, inv.ID, "someone", "other@example.com"); !errors.Is(err, ErrInvitationNotForYou) {
+		t.Fatalf("expected ErrInvitationNotForYou, got %v", err)
+	}
+
+	pending, _ := service.MyInvitations(ctx, "manager@example.com")
+	if len(pending) != 1 {
+		t.Fatalf("expected 1 pending invitation, got %d", len(pending))
+	}
+
+	if _, err := service.AcceptInvitation(ctx, inv.ID, "manager-1", "manager@example.com"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ok, _ := service.CanAccess(ctx, 1, "manager-1", core.ActionManageMenu)
+	if !ok {
+		t.Fatalf("expected manager to manage the menu")
+	}
+	ok, _ = service.CanAccess(ctx, 1, "manager-1", core.ActionManageMembers)
+	if ok {
+		t.Fatalf("expected manager not to manage members")
+	}
+
+	if _, err := service.AcceptInvitation(ctx, inv.ID, "manager-1", "manager@example.com"); !errors.Is(err, ErrInvitationNotFound) {
+		t.Fatalf("expected accepted invitation to be unusable, got %v", err)
+	}
+}
+
+func TestExpiredInvitationCannotBeAccepted(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMockMemberRepository()
+	repo.add(1, "owner-1", MemberOwner)
+	service := NewMemberService(repo, &recordingMailer{})
+
+	inv, _ := service.Invite(ctx, 1, "owner-1", "staff@example.com", "STAFF")
+	repo.invitations[inv.ID].ExpiresAt = time.Now().Add(-time.Minute)
+
+	if _, err := service.AcceptInvitation(ctx, inv.ID, "staff-1", "staff@example.com"); !errors.Is(err, ErrInvitationNotFound) {
+		t.Fatalf("expected ErrInvitationNotFound, got %v", err)
+	}
+}
+
+func TestReinviteAfterInvitationExpired(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMockMemberRepository()
+	repo.add(1, "owner-1", MemberOwner)
+	service := NewMemberService(repo, &recordingMailer{})
+
+	stale, _ := service.Invite(ctx, 1, "owner-1", "staff@example.com", "STAFF")
+	repo.invitations[stale.ID].ExpiresAt = time.Now().Add(-time.Minute)
+
+	inv, err := service.Invite(ctx, 1, "owner-1", "Staff@Example.com", "STAFF")
+	if err != nil {
+		t.Fatalf("expected a new invitation once the old one expired, got %v", err)
+	}
+	if repo.invitations[stale.ID].Status != InvitationExpired {
+		t.Fatalf("expected the stale invitation marked expired, got %s", repo.invitations[stale.ID].Status)
+	}
+	if _, err := service.AcceptInvitation(ctx, inv.ID, "staff-1", "staff@example.com"); err != nil {
+		t.Fatalf("unexpected error accepting the new invitation: %v", err)
+	}
+}
+
+func TestStaffCannotManageMembers(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMockMemberRepository()
+	repo.add(1, "owner-1", MemberOwner)
+	repo.add(1, "staff-1", MemberStaff)
+	service := NewMemberService(repo, &recordingMailer{})
+
+	if _, err := service.Invite(ctx, 1, "staff-1", "friend@example.com", "OWNER"); !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("expected ErrUnauthorized, got %v", err)
+	}
+	if err := service.ChangeRole(ctx, 1, "staff-1", "staff-1", "OWNER"); !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("expected ErrUnauthorized, got %v", err)
+	}
+	if err := service.RemoveMember(ctx, 1, "staff-1", "owner-1"); !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("expected ErrUnauthorized, got %v", err)
+	}
+	if _, err := service.ListMembers(ctx, 1, "outsider"); !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("expected ErrUnauthorized for non-member, got %v", err)
+	}
+
+	// Anyone may leave on their own.
+	if err := service.RemoveMember(ctx, 1, "staff-1", "staff-1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if role, _ := repo.GetRole(ctx, 1, "staff-1"); role != "" {
+		t.Fatalf("expected staff-1 to be removed, got %q", role)
+	}
+}
+
+func TestLastOwnerIsKept(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMockMemberRepository()
+	repo.add(1, "owner-1", MemberOwner)
+	service := NewMemberService(repo, &recordingMailer{})
+
+	if err := service.ChangeRole(ctx, 1, "owner-1", "owner-1", "MANAGER"); !errors.Is(err, ErrLastOwner) {
+		t.Fatalf("expected ErrLastOwner, got %v", err)
+	}
+	if err := service.RemoveMember(ctx, 1, "owner-1", "owner-1"); !errors.Is(err, ErrLastOwner) {
+		t.Fatalf("expected ErrLastOwner, got %v", err)
+	}
+
+	repo.add(1, "owner-2", MemberOwner)
+	if err := service.ChangeRole(ctx, 1, "owner-1", "owner-1", "MANAGER"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestBoundRestaurantLimitsAccess(t *testing.T) {
+	repo := NewMockMemberRepository()
+	repo.add(1, "owner-1", MemberOwner)
+	repo.add(2, "owner-1", MemberOwner)
+	service := NewMemberService(repo, &recordingMailer{})
+
+	ctx := core.WithBoundRestaurant(context.Background(), 1)
+
+	if ok, _ := service.CanAccess(ctx, 1, "owner-1", core.ActionManageDeals); !ok {
+		t.Fatalf("expected access to the bound restaurant")
+	}
+	if ok, _ := service.CanAccess(ctx, 2, "owner-1", core.ActionViewRestaurant); ok {
+		t.Fatalf("expected no access outside the bound restaurant")
+	}
+}
@@ -0,0 +1,98 @@
+package restaurant
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"bhojanalya/internal/core"
+)
+
+var (
+	ErrUnauthorized        = errors.New("unauthorized")
+	ErrInvalidMemberRole   = errors.New("role must be OWNER, MANAGER or STAFF")
+	ErrInvalidEmail        = errors.New("a valid email is required")
+	ErrLastOwner           = errors.New("a restaurant must keep at least one owner")
+	ErrMemberNotFound      = errors.New("member not found")
+	ErrInvitationNotFound  = errors.New("invitation not found")
+	ErrInvitationExists    = errors.New("an invitation for this email is already pending")
+	ErrAlreadyMember       = errors.New("user is already a member of this restaurant")
+	ErrInvitationNotForYou = errors.New("invitation was sent to a different email")
+)
+
+// MemberRole is a user's role inside one restaurant. It is separate from
+// the platform role on the user account.
+type MemberRole string
+
+const (
+	MemberOwner   MemberRole = "OWNER"
+	MemberManager MemberRole = "MANAGER"
+	MemberStaff   MemberRole = "STAFF"
+)
+
+var memberPermissions = map[MemberRole][]core.RestaurantAction{
+	MemberOwner: {
+		core.ActionViewRestaurant,
+		core.ActionEditRestaurant,
+		core.ActionManageMenu,
+		core.ActionManageDeals,
+		core.ActionManageMembers,
+	},
+	MemberManager: {
+		core.ActionViewRestaurant,
+		core.ActionEditRestaurant,
+		core.ActionManageMenu,
+		core.ActionManageDeals,
+	},
+	MemberStaff: {
+		core.ActionViewRestaurant,
+	},
+}
+
+func ParseMemberRole(s string) (MemberRole, error) {
+	role := MemberRole(strings.ToUpper(strings.TrimSpace(s)))
+	if _, ok := memberPermissions[role]; !ok {
+		return "", ErrInvalidMemberRole
+	}
+	return role, nil
+}
+
+// Allows reports whether the role grants action.
+func (r MemberRole) Allows(action core.RestaurantAction) bool {
+	for _, a := range memberPermissions[r] {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
+
+type Member struct {
+	RestaurantID int        `json:"restaurant_id"`
+	UserID       string     `json:"user_id"`
+	Name         string     `json:"name"`
+	Email        string     `json:"email"`
+	Role         MemberRole `json:"role"`
+	JoinedAt     time.Time  `json:"joined_at"`
+}
+
+// Invitation statuses.
+const (
+	InvitationPending  = "PENDING"
+	InvitationAccepted = "ACCEPTED"
+	InvitationDeclined = "DECLINED"
+	InvitationRevoked  = "REVOKED"
+	InvitationExpired  = "EXPIRED"
+)
+
+type Invitation struct {
+	ID             string     `json:"id"`
+	RestaurantID   int        `json:"restaurant_id"`
+	RestaurantName string     `json:"restaurant_name"`
+	Email          string     `json:"email"`
+	Role           MemberRole `json:"role"`
+	InvitedBy      string     `json:"invited_by"`
+	Status         string     `json:"status"`
+	ExpiresAt      time.Time  `json:"expires_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
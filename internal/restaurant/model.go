@@ -13,6 +13,13 @@ type Restaurant struct {
 	OpensAt          string
 	ClosesAt         string
 	CreatedAt        time.Time
+
+	// MenuLanguage is the default OCR language hint ("hin+eng") for menu
+	// uploads; empty means detect the script.
+	MenuLanguage string
+
+	// MemberRole is the requesting user's role, set by ListForMember.
+	MemberRole MemberRole `json:",omitempty"`
 }
 
 
@@ -4,11 +4,11 @@ import "context"
 
 type Repository interface {
 	// core
+	// Create also makes the creator the restaurant's first OWNER member.
 	Create(restaurant *Restaurant) error
-	ListByOwner(ownerID string) ([]*Restaurant, error)
+	ListForMember(userID string) ([]*Restaurant, error)
 
-	// ownership & competitive insights
-	IsOwner(ctx context.Context, restaurantID int, userID string) (bool, error)
+	// competitive insights
 	GetLatestParsedCostForTwo(
 		ctx context.Context,
 		restaurantID int,
@@ -22,6 +22,9 @@ type Repository interface {
 	SaveRestaurantImages(ctx context.Context, restaurantID int, images []string) error
 	GetRestaurantImages(ctx context.Context, restaurantID int) ([]string, error)
 
+	// menu OCR language; "" clears it
+	SetMenuLanguage(ctx context.Context, restaurantID int, languages string) error
+
 	// admin views
 	ListApproved(ctx context.Context) ([]*Restaurant, error)
 	GetAdminDetails(
@@ -2,6 +2,7 @@ package restaurant
 
 import (
 	"context"
+	"errors"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 )
@@ -19,18 +20,24 @@ func NewPostgresRepository(db *pgxpool.Pool) *PostgresRepository {
 // --------------------------------------------------
 func (r *PostgresRepository) Create(restaurant *Restaurant) error {
 	query := `
-		INSERT INTO restaurants (
-			name,
-			city,
-			cuisine_type,
-			owner_id,
-			status,
-			short_description,
-			opens_at,
-			closes_at
+		WITH created AS (
+			INSERT INTO restaurants (
+				name,
+				city,
+				cuisine_type,
+				owner_id,
+				status,
+				short_description,
+				opens_at,
+				closes_at
+			)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			RETURNING id, owner_id, created_at
+		), owner AS (
+			INSERT INTO restaurant_members (restaurant_id, user_id, role)
+			SELECT id, owner_id, 'OWNER' FROM created
 		)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-		RETURNING id, created_at
+		SELECT id, created_at FROM created
 	`
 
 	return r.db.QueryRow(
@@ -48,27 +55,30 @@ func (r *PostgresRepository) Create(restaurant *Restaurant) error {
 }
 
 // --------------------------------------------------
-// List restaurants owned by a user
+// List restaurants a user is a member of
 // --------------------------------------------------
-func (r *PostgresRepository) ListByOwner(ownerID string) ([]*Restaurant, error) {
+func (r *PostgresRepository) ListForMember(userID string) ([]*Restaurant, error) {
 	query := `
 		SELECT
-			id,
-			name,
-			city,
-			cuisine_type,
-			owner_id,
-			status,
-			short_description,
-			opens_at,
-			closes_at,
-			created_at
-		FROM restaurants
-		WHERE owner_id = $1
-		ORDER BY created_at DESC
+			r.id,
+			r.name,
+			r.city,
+			r.cuisine_type,
+			r.owner_id,
+			r.status,
+			r.short_description,
+			r.opens_at,
+			r.closes_at,
+			r.created_at,
+			COALESCE(r.menu_language, ''),
+			m.role
+		FROM restaurants r
+		JOIN restaurant_members m ON m.restaurant_id = r.id
+		WHERE m.user_id = $1
+		ORDER BY r.created_at DESC
 	`
 
-	rows, err := r.db.Query(context.Background(), query, ownerID)
+	rows, err := r.db.Query(context.Background(), query, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -89,6 +99,8 @@ func (r *PostgresRepository) ListByOwner(ownerID string) ([]*Restaurant, error)
 			&res.OpensAt,
 			&res.ClosesAt,
 			&res.CreatedAt,
+			&res.MenuLanguage,
+			&res.MemberRole,
 		); err != nil {
 			return nil, err
 		}
@@ -215,28 +227,6 @@ func (r *PostgresRepository) GetLatestParsedCostForTwo(
 	return cost, city, cuisine, err
 }
 
-// --------------------------------------------------
-// Ownership check
-// --------------------------------------------------
-func (r *PostgresRepository) IsOwner(
-	ctx context.Context,
-	restaurantID int,
-	userID string,
-) (bool, error) {
-
-	var exists bool
-	err := r.db.QueryRow(ctx, `
-		SELECT EXISTS (
-			SELECT 1
-			FROM restaurants
-			WHERE id = $1
-			  AND owner_id = $2
-		)
-	`, restaurantID, userID).Scan(&exists)
-
-	return exists, err
-}
-
 // --------------------------------------------------
 // Preview support
 // --------------------------------------------------
@@ -411,3 +401,25 @@ func (r *PostgresRepository) GetRestaurantImages(
 
 	return images, nil
 }
+
+// --------------------------------------------------
+// Set the default menu OCR language
+// --------------------------------------------------
+func (r *PostgresRepository) SetMenuLanguage(
+	ctx context.Context,
+	restaurantID int,
+	languages string,
+) error {
+	cmd, err := r.db.Exec(ctx, `
+		UPDATE restaurants
+		SET menu_language = NULLIF($2, '')
+		WHERE id = $1
+	`, restaurantID, languages)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return errors.New("restaurant not found")
+	}
+	return nil
+}
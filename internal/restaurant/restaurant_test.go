@@ -41,22 +41,14 @@ func (m *MockRepository) Create(restaurant *Restaurant) error {
 	return nil
 }
 
-func (m *MockRepository) ListByOwner(ownerID string) ([]*Restaurant, error) {
-	return m.restaurants[ownerID], nil
+func (m *MockRepository) ListForMember(userID string) ([]*Restaurant, error) {
+	return m.restaurants[userID], nil
 }
 
 // --------------------------------------------------
-// OWNERSHIP & COMPETITION (NO-OP)
+// COMPETITION (NO-OP)
 // --------------------------------------------------
 
-func (m *MockRepository) IsOwner(
-	ctx context.Context,
-	restaurantID int,
-	userID string,
-) (bool, error) {
-	return true, nil
-}
-
 func (m *MockRepository) GetLatestParsedCostForTwo(
 	ctx context.Context,
 	restaurantID int,
@@ -110,6 +102,14 @@ func (m *MockRepository) GetRestaurantImages(
 	return []string{}, nil
 }
 
+func (m *MockRepository) SetMenuLanguage(
+	ctx context.Context,
+	restaurantID int,
+	languages string,
+) error {
+	return nil
+}
+
 // --------------------------------------------------
 // ADMIN METHODS (REQUIRED BY INTERFACE)
 // --------------------------------------------------
@@ -153,6 +153,7 @@ func TestCreateRestaurant_Success(t *testing.T) {
 		nil, // menuService not needed for this test
 		&competition.Repository{},
 		nil,
+		nil,
 	)
 
 	restaurant, err := service.CreateRestaurant(
@@ -186,6 +187,7 @@ func TestCreateRestaurant_MissingFields(t *testing.T) {
 		nil,
 		&competition.Repository{},
 		nil,
+		nil,
 	)
 
 	_, err := service.CreateRestaurant(
@@ -211,6 +213,7 @@ func TestListMyRestaurants_Success(t *testing.T) {
 		nil,
 		&competition.Repository{},
 		nil,
+		nil,
 	)
 
 	service.CreateRestaurant("Taj Palace", "NY", "Indian", "", "", "", "owner-123")
@@ -235,6 +238,7 @@ func TestListMyRestaurants_Empty(t *testing.T) {
 		nil,
 		&competition.Repository{},
 		nil,
+		nil,
 	)
 
 	restaurants, err := service.ListMyRestaurants("no-restaurants")
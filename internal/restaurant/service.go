@@ -10,6 +10,8 @@ import (
 	"time"
 	"bhojanalya/internal/menu"
 	"bhojanalya/internal/competition"
+	"bhojanalya/internal/core"
+	"bhojanalya/internal/lang"
 	"bhojanalya/internal/storage"
 )
 
@@ -18,6 +20,7 @@ type Service struct {
 	menuService     *menu.Service
 	competitionRepo *competition.Repository
 	r2              *storage.R2Client
+	access          core.RestaurantAccess
 }
 
 func NewService(
@@ -25,12 +28,14 @@ func NewService(
 	menuService *menu.Service,
 	competitionRepo *competition.Repository,
 	r2 *storage.R2Client,
+	access core.RestaurantAccess,
 ) *Service {
 	return &Service{
 		repo:            repo,
 		menuService:     menuService,
 		competitionRepo: competitionRepo,
 		r2:              r2,
+		access:          access,
 	}
 }
 
@@ -82,10 +87,10 @@ func (s *Service) CreateRestaurant(
 }
 
 // --------------------------------------------------
-// List restaurants owned by user
+// List restaurants the user is a member of
 // --------------------------------------------------
-func (s *Service) ListMyRestaurants(ownerID string) ([]*Restaurant, error) {
-	return s.repo.ListByOwner(ownerID)
+func (s *Service) ListMyRestaurants(userID string) ([]*Restaurant, error) {
+	return s.repo.ListForMember(userID)
 }
 
 // --------------------------------------------------
@@ -116,9 +121,9 @@ func (s *Service) GetCompetitiveInsight(
 	userID string,
 ) (*CompetitiveInsight, error) {
 
-	ok, err := s.repo.IsOwner(ctx, restaurantID, userID)
+	ok, err := s.access.CanAccess(ctx, restaurantID, userID, core.ActionViewRestaurant)
 	if err != nil || !ok {
-		return nil, errors.New("unauthorized")
+		return nil, ErrUnauthorized
 	}
 
 	cost, city, cuisine, err :=
@@ -156,9 +161,9 @@ func (s *Service) UploadImages(
 	files []*multipart.FileHeader,
 ) error {
 
-	ok, err := s.repo.IsOwner(ctx, restaurantID, userID)
+	ok, err := s.access.CanAccess(ctx, restaurantID, userID, core.ActionEditRestaurant)
 	if err != nil || !ok {
-		return errors.New("unauthorized")
+		return ErrUnauthorized
 	}
 
 	var imageKeys []string
@@ -202,18 +207,27 @@ func (s *Service) GetPreview(
 	userID string,
 ) (*PreviewData, error) {
 
-	IsOwner, err := s.repo.IsOwner(ctx, restaurantID, userID)
+	isMember, err := s.access.CanAccess(ctx, restaurantID, userID, core.ActionViewRestaurant)
 	if err != nil {
 		return nil, err
 	}
-
-	if !IsOwner {
-		role, _ := ctx.Value("userRole").(string)
-		if role != "admin"{
-			return nil, errors.New("unauthorized")
-		}
+	if !isMember {
+		return nil, ErrUnauthorized
 	}
 
+	return s.buildPreview(ctx, restaurantID)
+}
+
+// GetPreviewForReview skips the membership check. Callers must already
+// hold the restaurants:read permission.
+func (s *Service) GetPreviewForReview(
+	ctx context.Context,
+	restaurantID int,
+) (*PreviewData, error) {
+	return s.buildPreview(ctx, restaurantID)
+}
+
+func (s *Service) buildPreview(ctx context.Context, restaurantID int) (*PreviewData, error) {
 	preview, err := s.repo.GetPreviewData(ctx, restaurantID)
 	if err != nil {
 		return nil, err
@@ -234,6 +248,34 @@ func (s *Service) GetPreview(
 	return preview, nil
 }
 
+// --------------------------------------------------
+// Set default menu OCR language
+// --------------------------------------------------
+// SetMenuLanguage stores the OCR language hint used for uploads that don't
+// carry their own. An empty hint switches back to script detection.
+func (s *Service) SetMenuLanguage(
+	ctx context.Context,
+	restaurantID int,
+	userID string,
+	hint string,
+) (string, error) {
+
+	ok, err := s.access.CanAccess(ctx, restaurantID, userID, core.ActionEditRestaurant)
+	if err != nil || !ok {
+		return "", ErrUnauthorized
+	}
+
+	languages, err := lang.ParseHint(hint)
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.repo.SetMenuLanguage(ctx, restaurantID, languages); err != nil {
+		return "", err
+	}
+	return languages, nil
+}
+
 // --------------------------------------------------
 
 func determinePosition(cost, median float64) string {